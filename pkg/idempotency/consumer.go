@@ -0,0 +1,85 @@
+// Package idempotency descarta las entregas repetidas de eventos de RabbitMQ con un registro por
+// consumidor en la tabla processed_events. Lo usan purchase-order-service y supplier-service.
+package idempotency
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ProcessedEventStore registra qué eventos tomó o procesó cada consumidor
+type ProcessedEventStore interface {
+	Claim(consumidor, eventID, eventType string, reservadoHasta, expiraEn time.Time) (bool, error)
+	Complete(consumidor, eventID string, expiraEn time.Time) error
+	Release(consumidor, eventID string) error
+}
+
+// IdempotentConsumer descarta las entregas repetidas de un mismo evento (por event_id) antes de
+// llegar al handler, de modo que la entrega al menos una vez de RabbitMQ no repita efectos
+type IdempotentConsumer struct {
+	store     ProcessedEventStore
+	reserva   time.Duration
+	retencion time.Duration
+	log       *logrus.Logger
+}
+
+// NewIdempotentConsumer crea un IdempotentConsumer. reserva es cuánto puede tardar una instancia
+// en procesar un evento antes de que otra pueda tomarlo; retencion, cuánto se recuerda un evento
+// procesado.
+func NewIdempotentConsumer(store ProcessedEventStore, reserva, retencion time.Duration, log *logrus.Logger) *IdempotentConsumer {
+	return &IdempotentConsumer{
+		store:     store,
+		reserva:   reserva,
+		retencion: retencion,
+		log:       log,
+	}
+}
+
+// Wrap envuelve el handler de un consumidor (normalmente el nombre de su cola). Cada consumidor
+// lleva su propio registro porque un mismo evento llega a todas las colas enlazadas al exchange.
+func (c *IdempotentConsumer) Wrap(consumidor string, handler func([]byte) error) func([]byte) error {
+	return func(eventData []byte) error {
+		var baseEvent struct {
+			EventID   string `json:"event_id"`
+			EventType string `json:"event_type"`
+		}
+		if err := json.Unmarshal(eventData, &baseEvent); err != nil || baseEvent.EventID == "" {
+			// Sin event_id no hay cómo reconocer una entrega repetida; el handler decide
+			return handler(eventData)
+		}
+
+		logger := c.log.WithFields(logrus.Fields{
+			"consumer":   consumidor,
+			"event_id":   baseEvent.EventID,
+			"event_type": baseEvent.EventType,
+		})
+
+		ahora := time.Now()
+		reservado, err := c.store.Claim(consumidor, baseEvent.EventID, baseEvent.EventType, ahora.Add(c.reserva), ahora.Add(c.reserva+c.retencion))
+		if err != nil {
+			// Sin registro se procesa igual: es preferible un posible duplicado a perder el evento
+			logger.WithError(err).Warn("Processed event store unavailable, handling event without deduplication")
+			return handler(eventData)
+		}
+		if !reservado {
+			logger.Info("Skipping already processed event")
+			return nil
+		}
+
+		if err := handler(eventData); err != nil {
+			if relErr := c.store.Release(consumidor, baseEvent.EventID); relErr != nil {
+				logger.WithError(relErr).Error("Error releasing processed event claim")
+			}
+			return err
+		}
+
+		if err := c.store.Complete(consumidor, baseEvent.EventID, time.Now().Add(c.retencion)); err != nil {
+			// La reserva vence sola; hasta entonces las entregas repetidas se siguen descartando
+			logger.WithError(err).Error("Error marking event as processed")
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,24 @@
+package idempotency
+
+import "time"
+
+// EstadoEventoProcesado indica si un consumidor está procesando un evento o ya lo procesó
+type EstadoEventoProcesado string
+
+const (
+	EventoEnProceso EstadoEventoProcesado = "EN_PROCESO"
+	EventoProcesado EstadoEventoProcesado = "PROCESADO"
+)
+
+// RegistroEventoProcesado registra que un consumidor tomó o procesó un evento. Los tiempos en
+// segundos Unix permiten comparar la reserva en la condición de DynamoDB y usar expira_en como TTL.
+type RegistroEventoProcesado struct {
+	Consumidor     string                `json:"consumidor" dynamodbav:"consumidor"`
+	EventID        string                `json:"event_id" dynamodbav:"event_id"`
+	EventType      string                `json:"event_type,omitempty" dynamodbav:"event_type,omitempty"`
+	Estado         EstadoEventoProcesado `json:"estado" dynamodbav:"estado"`
+	ReservadoHasta int64                 `json:"reservado_hasta" dynamodbav:"reservado_hasta"`
+	ExpiraEn       int64                 `json:"expira_en" dynamodbav:"expira_en"`
+	FechaRecibido  time.Time             `json:"fecha_recibido" dynamodbav:"fecha_recibido"`
+	FechaProcesado *time.Time            `json:"fecha_procesado,omitempty" dynamodbav:"fecha_procesado,omitempty"`
+}
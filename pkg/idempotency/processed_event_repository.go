@@ -0,0 +1,197 @@
+package idempotency
+
+import (
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/sirupsen/logrus"
+)
+
+// processedEventRepository implementa ProcessedEventStore sobre la tabla processed_events
+type processedEventRepository struct {
+	client *dynamodb.DynamoDB
+	log    *logrus.Logger
+}
+
+// NewProcessedEventRepository crea un ProcessedEventStore sobre la tabla processed_events
+func NewProcessedEventRepository(client *dynamodb.DynamoDB, log *logrus.Logger) ProcessedEventStore {
+	return &processedEventRepository{
+		client: client,
+		log:    log,
+	}
+}
+
+// Claim reserva el evento para el consumidor hasta reservadoHasta. Retorna false si el evento ya
+// se procesó o si otra instancia lo tiene reservado; una reserva vencida puede volver a tomarse.
+func (r *processedEventRepository) Claim(consumidor, eventID, eventType string, reservadoHasta, expiraEn time.Time) (bool, error) {
+	ahora := time.Now()
+	registro := RegistroEventoProcesado{
+		Consumidor:     consumidor,
+		EventID:        eventID,
+		EventType:      eventType,
+		Estado:         EventoEnProceso,
+		ReservadoHasta: reservadoHasta.Unix(),
+		ExpiraEn:       expiraEn.Unix(),
+		FechaRecibido:  ahora,
+	}
+
+	item, err := dynamodbattribute.MarshalMap(registro)
+	if err != nil {
+		return false, err
+	}
+
+	condicion := expression.AttributeNotExists(expression.Name("event_id")).Or(
+		expression.Name("estado").Equal(expression.Value(EventoEnProceso)).
+			And(expression.Name("reservado_hasta").LessThan(expression.Value(ahora.Unix()))),
+	)
+	expr, err := expression.NewBuilder().WithCondition(condicion).Build()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = r.client.PutItem(&dynamodb.PutItemInput{
+		TableName:                 aws.String("processed_events"),
+		Item:                      item,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		var fallida *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &fallida) {
+			return false, nil
+		}
+		r.log.Errorf("Error claiming processed event: %v", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Complete marca el evento como procesado; el registro se elimina por TTL en expiraEn
+func (r *processedEventRepository) Complete(consumidor, eventID string, expiraEn time.Time) error {
+	update := expression.Set(expression.Name("estado"), expression.Value(EventoProcesado)).
+		Set(expression.Name("expira_en"), expression.Value(expiraEn.Unix())).
+		Set(expression.Name("fecha_procesado"), expression.Value(time.Now()))
+	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 aws.String("processed_events"),
+		Key:                       claveEventoProcesado(consumidor, eventID),
+		UpdateExpression:          expr.Update(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		r.log.Errorf("Error completing processed event: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// Release libera la reserva de un evento cuyo procesamiento falló para que una nueva entrega
+// pueda procesarlo. Un evento ya procesado no se libera.
+func (r *processedEventRepository) Release(consumidor, eventID string) error {
+	condicion := expression.Name("estado").Equal(expression.Value(EventoEnProceso))
+	expr, err := expression.NewBuilder().WithCondition(condicion).Build()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName:                 aws.String("processed_events"),
+		Key:                       claveEventoProcesado(consumidor, eventID),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		var fallida *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &fallida) {
+			return nil
+		}
+		r.log.Errorf("Error releasing processed event: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// CreateTable crea la tabla processed_events, compartida por los servicios, y activa su TTL
+// sobre expira_en
+func CreateTable(client *dynamodb.DynamoDB, log *logrus.Logger) error {
+	input := &dynamodb.CreateTableInput{
+		TableName: aws.String("processed_events"),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("consumidor"),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String("event_id"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("consumidor"),
+				KeyType:       aws.String("HASH"),
+			},
+			{
+				AttributeName: aws.String("event_id"),
+				KeyType:       aws.String("RANGE"),
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(5),
+			WriteCapacityUnits: aws.Int64(5),
+		},
+	}
+
+	_, err := client.CreateTable(input)
+	if err != nil {
+		// Si la tabla ya existe, no es un error
+		if _, ok := err.(*dynamodb.ResourceInUseException); !ok {
+			return err
+		}
+		return nil
+	}
+
+	if err := client.WaitUntilTableExists(&dynamodb.DescribeTableInput{TableName: aws.String("processed_events")}); err != nil {
+		return err
+	}
+
+	_, err = client.UpdateTimeToLive(&dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String("processed_events"),
+		TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+			AttributeName: aws.String("expira_en"),
+			Enabled:       aws.Bool(true),
+		},
+	})
+	if err != nil {
+		// Sin TTL los registros no expiran, pero la deduplicación sigue funcionando
+		log.Warnf("Error enabling TTL on processed_events: %v", err)
+	}
+
+	return nil
+}
+
+// claveEventoProcesado arma la clave del registro de un evento para un consumidor
+func claveEventoProcesado(consumidor, eventID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"consumidor": {
+			S: aws.String(consumidor),
+		},
+		"event_id": {
+			S: aws.String(eventID),
+		},
+	}
+}
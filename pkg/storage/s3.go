@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// S3Storage guarda los documentos en un bucket de S3
+type S3Storage struct {
+	client *s3.S3
+	bucket string
+}
+
+// NewS3Storage crea un almacenamiento sobre el bucket indicado.
+// endpoint es opcional (por ejemplo, para usar un S3 compatible en desarrollo local).
+func NewS3Storage(region, endpoint, bucket string) (*S3Storage, error) {
+	config := &aws.Config{
+		Region: aws.String(region),
+	}
+
+	if endpoint != "" {
+		config.Endpoint = aws.String(endpoint)
+		config.S3ForcePathStyle = aws.Bool(true)
+	}
+
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &S3Storage{
+		client: s3.New(sess),
+		bucket: bucket,
+	}, nil
+}
+
+// Put sube el contenido al bucket con cifrado del lado del servidor
+func (s *S3Storage) Put(key string, data []byte, contentType string) error {
+	_, err := s.client.PutObject(&s3.PutObjectInput{
+		Bucket:               aws.String(s.bucket),
+		Key:                  aws.String(key),
+		Body:                 bytes.NewReader(data),
+		ContentType:          aws.String(contentType),
+		ServerSideEncryption: aws.String(s3.ServerSideEncryptionAes256),
+	})
+	return err
+}
+
+// Get descarga el objeto del bucket
+func (s *S3Storage) Get(key string) (io.ReadCloser, error) {
+	output, err := s.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return output.Body, nil
+}
+
+// Delete elimina el objeto del bucket
+func (s *S3Storage) Delete(key string) error {
+	_, err := s.client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
@@ -0,0 +1,17 @@
+// Package storage guarda el contenido de los documentos en el sistema de archivos local o en S3.
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrNotFound se retorna cuando el objeto solicitado no existe en el almacenamiento
+var ErrNotFound = errors.New("object not found")
+
+// DocumentStorage define la interfaz para almacenar el contenido de los documentos
+type DocumentStorage interface {
+	Put(key string, data []byte, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
@@ -0,0 +1,70 @@
+package clients
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen se retorna sin llamar a supplier-service mientras el circuito está abierto
+var ErrCircuitOpen = errors.New("supplier-service circuit breaker is open")
+
+// circuitBreaker corta las llamadas tras varios fallos seguidos. Pasada la espera deja pasar
+// una sola llamada de prueba: si responde se cierra y, si falla, vuelve a abrirse.
+type circuitBreaker struct {
+	mu           sync.Mutex
+	umbral       int
+	espera       time.Duration
+	fallos       int
+	abiertoHasta time.Time
+	enPrueba     bool
+}
+
+// newCircuitBreaker crea un circuito que se abre tras umbral fallos seguidos (0 lo desactiva)
+func newCircuitBreaker(umbral int, espera time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		umbral: umbral,
+		espera: espera,
+	}
+}
+
+// permitir indica si se puede llamar ahora al servicio
+func (b *circuitBreaker) permitir() error {
+	if b.umbral <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.fallos < b.umbral {
+		return nil
+	}
+	if time.Now().Before(b.abiertoHasta) || b.enPrueba {
+		return ErrCircuitOpen
+	}
+
+	b.enPrueba = true
+	return nil
+}
+
+// registrarExito cierra el circuito
+func (b *circuitBreaker) registrarExito() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.fallos = 0
+	b.enPrueba = false
+}
+
+// registrarFallo cuenta un fallo y abre el circuito al alcanzar el umbral
+func (b *circuitBreaker) registrarFallo() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.fallos++
+	b.enPrueba = false
+	if b.umbral > 0 && b.fallos >= b.umbral {
+		b.abiertoHasta = time.Now().Add(b.espera)
+	}
+}
@@ -0,0 +1,163 @@
+package clients
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"mediplus/purchase-order-service/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrSupplierNotFound se retorna cuando supplier-service no conoce al proveedor
+var ErrSupplierNotFound = errors.New("supplier not found in supplier-service")
+
+// SupplierClient define la interfaz para consultar supplier-service
+type SupplierClient interface {
+	GetSupplier(proveedorID string) (*models.Proveedor, error)
+	ListActiveSuppliers() ([]*models.Proveedor, error)
+	ListSuppliers() ([]*models.Proveedor, error)
+}
+
+// SupplierClientConfig define los tiempos de espera, reintentos y el circuit breaker del cliente
+type SupplierClientConfig struct {
+	Timeout          time.Duration
+	MaxRetries       int
+	RetryBackoff     time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// supplierClient implementa SupplierClient sobre la API HTTP de supplier-service
+type supplierClient struct {
+	baseURL    string
+	httpClient *http.Client
+	config     SupplierClientConfig
+	breaker    *circuitBreaker
+	log        *logrus.Logger
+}
+
+// NewSupplierClient crea un cliente para la API de supplier-service
+func NewSupplierClient(baseURL string, config SupplierClientConfig, log *logrus.Logger) SupplierClient {
+	return &supplierClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: config.Timeout,
+		},
+		config:  config,
+		breaker: newCircuitBreaker(config.BreakerThreshold, config.BreakerCooldown),
+		log:     log,
+	}
+}
+
+// GetSupplier obtiene un proveedor de supplier-service
+func (c *supplierClient) GetSupplier(proveedorID string) (*models.Proveedor, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/suppliers/%s", c.baseURL, url.PathEscape(proveedorID))
+
+	var respuesta struct {
+		Data models.Proveedor `json:"data"`
+	}
+	if err := c.get(endpoint, &respuesta); err != nil {
+		return nil, err
+	}
+
+	return &respuesta.Data, nil
+}
+
+// ListActiveSuppliers lista los proveedores en estado ACTIVO
+func (c *supplierClient) ListActiveSuppliers() ([]*models.Proveedor, error) {
+	query := url.Values{}
+	query.Set("estado", models.EstadoProveedorActivo)
+
+	endpoint := fmt.Sprintf("%s/api/v1/suppliers?%s", c.baseURL, query.Encode())
+
+	var respuesta struct {
+		Data []*models.Proveedor `json:"data"`
+	}
+	if err := c.get(endpoint, &respuesta); err != nil {
+		return nil, err
+	}
+
+	return respuesta.Data, nil
+}
+
+// ListSuppliers lista todos los proveedores, en cualquier estado
+func (c *supplierClient) ListSuppliers() ([]*models.Proveedor, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/suppliers", c.baseURL)
+
+	var respuesta struct {
+		Data []*models.Proveedor `json:"data"`
+	}
+	if err := c.get(endpoint, &respuesta); err != nil {
+		return nil, err
+	}
+
+	return respuesta.Data, nil
+}
+
+// errorReintentable envuelve los fallos que justifican reintentar y cuentan para el circuito
+type errorReintentable struct {
+	err error
+}
+
+func (e *errorReintentable) Error() string { return e.err.Error() }
+func (e *errorReintentable) Unwrap() error { return e.err }
+
+// get ejecuta una petición GET con reintentos y decodifica la respuesta JSON. Solo se
+// reintentan los errores de red y las respuestas 5xx, con espera exponencial entre intentos.
+func (c *supplierClient) get(endpoint string, destino interface{}) error {
+	espera := c.config.RetryBackoff
+	for intento := 0; ; intento++ {
+		if err := c.breaker.permitir(); err != nil {
+			c.log.Warnf("Skipping supplier-service call: %v", err)
+			return err
+		}
+
+		err := c.intentarGet(endpoint, destino)
+
+		var reintentable *errorReintentable
+		if !errors.As(err, &reintentable) {
+			c.breaker.registrarExito()
+			return err
+		}
+
+		c.breaker.registrarFallo()
+		if intento >= c.config.MaxRetries {
+			c.log.Errorf("Error calling supplier-service after %d attempts: %v", intento+1, reintentable.err)
+			return reintentable.err
+		}
+
+		c.log.Warnf("Error calling supplier-service, retrying in %s: %v", espera, reintentable.err)
+		time.Sleep(espera)
+		espera *= 2
+	}
+}
+
+// intentarGet ejecuta un único intento de la petición GET
+func (c *supplierClient) intentarGet(endpoint string, destino interface{}) error {
+	resp, err := c.httpClient.Get(endpoint)
+	if err != nil {
+		return &errorReintentable{err: err}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrSupplierNotFound
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return &errorReintentable{err: fmt.Errorf("supplier-service returned status %d", resp.StatusCode)}
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("supplier-service returned status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(destino); err != nil {
+		return fmt.Errorf("error decoding supplier-service response: %w", err)
+	}
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package database
 
 import (
+	"mediplus/pkg/idempotency"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -54,6 +56,35 @@ func (d *DynamoDBClient) CreateTables() error {
 		return err
 	}
 
+	// Crear tabla del libro de movimientos de inventario
+	if err := d.createMovementsTable(); err != nil {
+		return err
+	}
+
+	if err := d.createLotsTable(); err != nil {
+		return err
+	}
+
+	if err := d.createExcursionsTable(); err != nil {
+		return err
+	}
+
+	if err := d.createSupplierProjectionTable(); err != nil {
+		return err
+	}
+
+	if err := idempotency.CreateTable(d.client, d.log); err != nil {
+		return err
+	}
+
+	if err := d.createBudgetsTable(); err != nil {
+		return err
+	}
+
+	if err := d.createOrderNumbersTable(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -78,6 +109,10 @@ func (d *DynamoDBClient) createOrdersTable() error {
 				AttributeName: aws.String("fecha_generacion"),
 				AttributeType: aws.String("S"),
 			},
+			{
+				AttributeName: aws.String("numero_orden"),
+				AttributeType: aws.String("S"),
+			},
 		},
 		KeySchema: []*dynamodb.KeySchemaElement{
 			{
@@ -122,6 +157,22 @@ func (d *DynamoDBClient) createOrdersTable() error {
 					WriteCapacityUnits: aws.Int64(5),
 				},
 			},
+			{
+				IndexName: aws.String("numero-orden-index"),
+				KeySchema: []*dynamodb.KeySchemaElement{
+					{
+						AttributeName: aws.String("numero_orden"),
+						KeyType:       aws.String("HASH"),
+					},
+				},
+				Projection: &dynamodb.Projection{
+					ProjectionType: aws.String("ALL"),
+				},
+				ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+					ReadCapacityUnits:  aws.Int64(5),
+					WriteCapacityUnits: aws.Int64(5),
+				},
+			},
 		},
 		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
 			ReadCapacityUnits:  aws.Int64(5),
@@ -135,8 +186,64 @@ func (d *DynamoDBClient) createOrdersTable() error {
 		if _, ok := err.(*dynamodb.ResourceInUseException); !ok {
 			return err
 		}
+		return d.ensureNumeroOrdenIndex()
+	}
+
+	return nil
+}
+
+// ensureNumeroOrdenIndex crea numero-orden-index en una tabla de órdenes creada antes de que
+// existiera el índice. DynamoDB lo rellena en segundo plano con las órdenes que ya tienen
+// numero_orden; hasta que queda ACTIVE las búsquedas por número fallan
+func (d *DynamoDBClient) ensureNumeroOrdenIndex() error {
+	tabla, err := d.client.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String("orders")})
+	if err != nil {
+		return err
+	}
+	for _, indice := range tabla.Table.GlobalSecondaryIndexes {
+		if aws.StringValue(indice.IndexName) == "numero-orden-index" {
+			return nil
+		}
+	}
+
+	_, err = d.client.UpdateTable(&dynamodb.UpdateTableInput{
+		TableName: aws.String("orders"),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("numero_orden"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		GlobalSecondaryIndexUpdates: []*dynamodb.GlobalSecondaryIndexUpdate{
+			{
+				Create: &dynamodb.CreateGlobalSecondaryIndexAction{
+					IndexName: aws.String("numero-orden-index"),
+					KeySchema: []*dynamodb.KeySchemaElement{
+						{
+							AttributeName: aws.String("numero_orden"),
+							KeyType:       aws.String("HASH"),
+						},
+					},
+					Projection: &dynamodb.Projection{
+						ProjectionType: aws.String("ALL"),
+					},
+					ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+						ReadCapacityUnits:  aws.Int64(5),
+						WriteCapacityUnits: aws.Int64(5),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		// Otra réplica pudo lanzar la misma creación
+		if _, ok := err.(*dynamodb.ResourceInUseException); !ok {
+			return err
+		}
+		return nil
 	}
 
+	d.log.Infof("Creating numero-orden-index on orders; existing orders are being backfilled")
 	return nil
 }
 
@@ -194,3 +301,236 @@ func (d *DynamoDBClient) createProductsTable() error {
 
 	return nil
 }
+
+// createMovementsTable crea la tabla del libro de movimientos de inventario
+// (clave compuesta producto_id + movimiento_id, que ordena los movimientos cronológicamente)
+func (d *DynamoDBClient) createMovementsTable() error {
+	input := &dynamodb.CreateTableInput{
+		TableName: aws.String("inventory_movements"),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("producto_id"),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String("movimiento_id"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("producto_id"),
+				KeyType:       aws.String("HASH"),
+			},
+			{
+				AttributeName: aws.String("movimiento_id"),
+				KeyType:       aws.String("RANGE"),
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(5),
+			WriteCapacityUnits: aws.Int64(5),
+		},
+	}
+
+	_, err := d.client.CreateTable(input)
+	if err != nil {
+		// Si la tabla ya existe, no es un error
+		if _, ok := err.(*dynamodb.ResourceInUseException); !ok {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createLotsTable crea la tabla de lotes de productos (clave compuesta producto_id + numero_lote)
+func (d *DynamoDBClient) createLotsTable() error {
+	input := &dynamodb.CreateTableInput{
+		TableName: aws.String("product_lots"),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("producto_id"),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String("numero_lote"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("producto_id"),
+				KeyType:       aws.String("HASH"),
+			},
+			{
+				AttributeName: aws.String("numero_lote"),
+				KeyType:       aws.String("RANGE"),
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(5),
+			WriteCapacityUnits: aws.Int64(5),
+		},
+	}
+
+	_, err := d.client.CreateTable(input)
+	if err != nil {
+		// Si la tabla ya existe, no es un error
+		if _, ok := err.(*dynamodb.ResourceInUseException); !ok {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createExcursionsTable crea la tabla de excursiones de temperatura
+// (clave compuesta producto_id + unidad_lote, una excursión por lote y unidad de almacenamiento)
+func (d *DynamoDBClient) createExcursionsTable() error {
+	input := &dynamodb.CreateTableInput{
+		TableName: aws.String("temperature_excursions"),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("producto_id"),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String("unidad_lote"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("producto_id"),
+				KeyType:       aws.String("HASH"),
+			},
+			{
+				AttributeName: aws.String("unidad_lote"),
+				KeyType:       aws.String("RANGE"),
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(5),
+			WriteCapacityUnits: aws.Int64(5),
+		},
+	}
+
+	_, err := d.client.CreateTable(input)
+	if err != nil {
+		// Si la tabla ya existe, no es un error
+		if _, ok := err.(*dynamodb.ResourceInUseException); !ok {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createSupplierProjectionTable crea la tabla con la proyección local de proveedores
+func (d *DynamoDBClient) createSupplierProjectionTable() error {
+	input := &dynamodb.CreateTableInput{
+		TableName: aws.String("supplier_projection"),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("proveedor_id"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("proveedor_id"),
+				KeyType:       aws.String("HASH"),
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(5),
+			WriteCapacityUnits: aws.Int64(5),
+		},
+	}
+
+	_, err := d.client.CreateTable(input)
+	if err != nil {
+		// Si la tabla ya existe, no es un error
+		if _, ok := err.(*dynamodb.ResourceInUseException); !ok {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createBudgetsTable crea la tabla de presupuestos (clave compuesta centro_costo_id + periodo)
+func (d *DynamoDBClient) createBudgetsTable() error {
+	input := &dynamodb.CreateTableInput{
+		TableName: aws.String("budgets"),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("centro_costo_id"),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String("periodo"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("centro_costo_id"),
+				KeyType:       aws.String("HASH"),
+			},
+			{
+				AttributeName: aws.String("periodo"),
+				KeyType:       aws.String("RANGE"),
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(5),
+			WriteCapacityUnits: aws.Int64(5),
+		},
+	}
+
+	_, err := d.client.CreateTable(input)
+	if err != nil {
+		// Si la tabla ya existe, no es un error
+		if _, ok := err.(*dynamodb.ResourceInUseException); !ok {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createOrderNumbersTable crea la tabla de numeración de órdenes: el contador de cada serie y un
+// registro por número asignado que garantiza su unicidad
+func (d *DynamoDBClient) createOrderNumbersTable() error {
+	input := &dynamodb.CreateTableInput{
+		TableName: aws.String("order_numbers"),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("clave"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("clave"),
+				KeyType:       aws.String("HASH"),
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(5),
+			WriteCapacityUnits: aws.Int64(5),
+		},
+	}
+
+	_, err := d.client.CreateTable(input)
+	if err != nil {
+		// Si la tabla ya existe, no es un error
+		if _, ok := err.(*dynamodb.ResourceInUseException); !ok {
+			return err
+		}
+	}
+
+	return nil
+}
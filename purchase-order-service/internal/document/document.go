@@ -0,0 +1,222 @@
+package document
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"math"
+	"mediplus/purchase-order-service/internal/models"
+	"os"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates/*
+var plantillas embed.FS
+
+// Comprador son los datos de la institución que emite la orden
+type Comprador struct {
+	Nombre               string
+	IdentificacionFiscal string
+	Direccion            string
+	Email                string
+	Telefono             string
+}
+
+// Proveedor son los datos del proveedor que figuran en el documento. Si supplier-service no
+// responde, solo se conocen el ID y el nombre de la proyección local.
+type Proveedor struct {
+	ProveedorID          string
+	Nombre               string
+	RazonSocial          string
+	IdentificacionFiscal string
+	Contacto             *models.ContactoProveedor
+}
+
+// Linea es un item de la orden con el nombre y las condiciones de transporte de su producto
+type Linea struct {
+	Numero      int
+	Item        models.ItemOrdenCompra
+	Producto    string
+	CadenaFrio  bool
+	TempMinima  float64
+	TempMaxima  float64
+	Condiciones []string
+}
+
+// Datos es lo que reciben las plantillas del documento
+type Datos struct {
+	Orden           *models.OrdenCompra
+	Comprador       Comprador
+	Proveedor       Proveedor
+	Lineas          []Linea
+	Terminos        []string
+	FechaEmision    time.Time
+	Hash            string
+	URLVerificacion string
+
+	// Borrador indica que el documento aún no se envió al proveedor y no está archivado
+	Borrador bool
+}
+
+// CadenaFrio indica si alguna línea debe transportarse con cadena de frío
+func (d *Datos) CadenaFrio() bool {
+	for _, linea := range d.Lineas {
+		if linea.CadenaFrio {
+			return true
+		}
+	}
+	return false
+}
+
+// Renderer genera el documento de una orden en HTML y PDF a partir de sus plantillas
+type Renderer struct {
+	html  *htmltemplate.Template
+	texto *texttemplate.Template
+}
+
+// NewRenderer carga las plantillas HTML y de texto del PDF. Una ruta vacía usa la plantilla
+// incluida en el servicio.
+func NewRenderer(rutaHTML, rutaPDF string) (*Renderer, error) {
+	fuenteHTML, err := leerPlantilla(rutaHTML, "templates/orden.html")
+	if err != nil {
+		return nil, err
+	}
+	fuentePDF, err := leerPlantilla(rutaPDF, "templates/orden.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	html, err := htmltemplate.New("orden.html").Funcs(funciones).Parse(fuenteHTML)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing HTML document template: %w", err)
+	}
+	texto, err := texttemplate.New("orden.txt").Funcs(funciones).Parse(fuentePDF)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing PDF document template: %w", err)
+	}
+
+	return &Renderer{html: html, texto: texto}, nil
+}
+
+// RenderHTML genera el documento en HTML
+func (r *Renderer) RenderHTML(datos *Datos) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := r.html.Execute(&buf, datos); err != nil {
+		return nil, fmt.Errorf("error rendering HTML document: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderPDF genera el documento en PDF con las líneas de la plantilla de texto. Las líneas que
+// empiezan con "# " se escriben en negrita.
+func (r *Renderer) RenderPDF(datos *Datos) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := r.texto.Execute(&buf, datos); err != nil {
+		return nil, fmt.Errorf("error rendering PDF document: %w", err)
+	}
+	titulo := "Orden de compra " + datos.Orden.NumeroOrden
+	return escribirPDF(titulo, strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")), nil
+}
+
+// CalcularHash retorna el hash SHA-256 con que se verifica el documento de la orden. Cubre lo
+// que se le pidió al proveedor (número, proveedor, items, precios y totales) y la fecha de
+// emisión, no el estado de la orden, que sigue cambiando después del envío.
+func CalcularHash(orden *models.OrdenCompra, fechaEmision time.Time) (string, error) {
+	type linea struct {
+		ProductoID          string       `json:"producto_id"`
+		Cantidad            int          `json:"cantidad"`
+		PrecioUnitario      models.Monto `json:"precio_unitario"`
+		DescuentoPorcentaje float64      `json:"descuento_porcentaje"`
+		Impuesto            models.Monto `json:"impuesto"`
+		Total               models.Monto `json:"total"`
+	}
+	contenido := struct {
+		OrdenID      string              `json:"orden_id"`
+		NumeroOrden  string              `json:"numero_orden"`
+		ProveedorID  string              `json:"proveedor_id"`
+		Moneda       string              `json:"moneda"`
+		Items        []linea             `json:"items"`
+		Totales      models.TotalesOrden `json:"totales"`
+		FechaEmision time.Time           `json:"fecha_emision"`
+	}{
+		OrdenID:      orden.OrdenID,
+		NumeroOrden:  orden.NumeroOrden,
+		ProveedorID:  orden.ProveedorID,
+		Moneda:       orden.Moneda,
+		Totales:      orden.Totales,
+		FechaEmision: fechaEmision.UTC(),
+	}
+	for _, item := range orden.Items {
+		contenido.Items = append(contenido.Items, linea{
+			ProductoID:          item.ProductoID,
+			Cantidad:            item.CantidadSolicitada,
+			PrecioUnitario:      item.PrecioUnitario,
+			DescuentoPorcentaje: item.DescuentoPorcentaje,
+			Impuesto:            item.Impuesto,
+			Total:               item.Total,
+		})
+	}
+
+	data, err := json.Marshal(contenido)
+	if err != nil {
+		return "", err
+	}
+	suma := sha256.Sum256(data)
+	return hex.EncodeToString(suma[:]), nil
+}
+
+// LoadTerms lee los términos y condiciones del documento, uno por línea. Sin archivo se usan los
+// términos incluidos en el servicio.
+func LoadTerms(ruta string) ([]string, error) {
+	fuente, err := leerPlantilla(ruta, "templates/terminos.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	terminos := []string{}
+	for _, linea := range strings.Split(fuente, "\n") {
+		if linea = strings.TrimSpace(linea); linea != "" {
+			terminos = append(terminos, linea)
+		}
+	}
+	return terminos, nil
+}
+
+// leerPlantilla lee la plantilla de la ruta indicada o, si está vacía, la incluida en el servicio
+func leerPlantilla(ruta, incluida string) (string, error) {
+	if ruta == "" {
+		data, err := plantillas.ReadFile(incluida)
+		return string(data), err
+	}
+	data, err := os.ReadFile(ruta)
+	if err != nil {
+		return "", fmt.Errorf("error reading %s: %w", ruta, err)
+	}
+	return string(data), nil
+}
+
+// funciones son las funciones disponibles en las plantillas
+var funciones = map[string]interface{}{
+	"fecha": func(t time.Time) string {
+		return t.Format("02/01/2006")
+	},
+	"fechaHora": func(t time.Time) string {
+		return t.Format("02/01/2006 15:04 MST")
+	},
+	"temperatura": func(grados float64) string {
+		return fmt.Sprintf("%.1f °C", grados)
+	},
+	"tasa": func(tasa float64) string {
+		return fmt.Sprintf("%g%%", math.Round(tasa*10000)/100)
+	},
+	"porcentaje": func(porcentaje float64) string {
+		return fmt.Sprintf("%g%%", porcentaje)
+	},
+	"unir": strings.Join,
+}
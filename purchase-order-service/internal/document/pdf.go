@@ -0,0 +1,159 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Página A4 en puntos, con la fuente monoespaciada estándar Courier para que las columnas de
+// la plantilla de texto queden alineadas sin incrustar fuentes
+const (
+	anchoPagina     = 595
+	altoPagina      = 842
+	margen          = 30
+	tamanoFuente    = 8
+	altoLinea       = 11
+	caracteresLinea = 110
+	lineasPagina    = (altoPagina - 2*margen - 2*altoLinea) / altoLinea
+)
+
+// lineaPDF es una línea de texto del documento ya partida al ancho de la página
+type lineaPDF struct {
+	texto   string
+	negrita bool
+}
+
+// escribirPDF arma un PDF con las líneas indicadas, paginado y con el título y el número de
+// página al pie
+func escribirPDF(titulo string, lineas []string) []byte {
+	paginas := paginar(lineas)
+
+	var buf bytes.Buffer
+	offsets := []int{}
+	objeto := func(contenido string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), contenido)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1 catálogo, 2 árbol de páginas, 3 y 4 fuentes, luego página y contenido por cada página y
+	// al final la información del documento
+	kids := make([]string, len(paginas))
+	for i := range paginas {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	objeto("<< /Type /Catalog /Pages 2 0 R >>")
+	objeto(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(paginas)))
+	objeto("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	objeto("<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, pagina := range paginas {
+		pie := fmt.Sprintf("%s - Página %d de %d", titulo, i+1, len(paginas))
+		contenido := contenidoPagina(pagina, pie)
+		objeto(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			anchoPagina, altoPagina, 6+2*i))
+		objeto(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(contenido), contenido))
+	}
+
+	objeto(fmt.Sprintf("<< /Title (%s) /Producer (mediplus purchase-order-service) >>", textoPDF(titulo)))
+	info := len(offsets)
+
+	inicioXref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(offsets)+1, info, inicioXref)
+
+	return buf.Bytes()
+}
+
+// paginar parte las líneas largas al ancho de la página y las reparte en páginas
+func paginar(lineas []string) [][]lineaPDF {
+	paginas := [][]lineaPDF{{}}
+	for _, linea := range lineas {
+		negrita := strings.HasPrefix(linea, "# ")
+		if negrita {
+			linea = strings.TrimPrefix(linea, "# ")
+		}
+		for _, parte := range partir(linea, caracteresLinea) {
+			actual := len(paginas) - 1
+			if len(paginas[actual]) == lineasPagina {
+				paginas = append(paginas, []lineaPDF{})
+				actual++
+			}
+			paginas[actual] = append(paginas[actual], lineaPDF{texto: parte, negrita: negrita})
+		}
+	}
+	return paginas
+}
+
+// partir divide una línea en tramos de a lo sumo ancho caracteres
+func partir(linea string, ancho int) []string {
+	if utf8.RuneCountInString(linea) <= ancho {
+		return []string{linea}
+	}
+	partes := []string{}
+	runas := []rune(linea)
+	for len(runas) > ancho {
+		partes = append(partes, string(runas[:ancho]))
+		runas = runas[ancho:]
+	}
+	return append(partes, string(runas))
+}
+
+// contenidoPagina arma el flujo de operadores de texto de una página
+func contenidoPagina(lineas []lineaPDF, pie string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", tamanoFuente, altoLinea, margen, altoPagina-margen)
+	negrita := false
+	for _, linea := range lineas {
+		if linea.negrita != negrita {
+			fuente := "/F1"
+			if linea.negrita {
+				fuente = "/F2"
+			}
+			fmt.Fprintf(&b, "%s %d Tf\n", fuente, tamanoFuente)
+			negrita = linea.negrita
+		}
+		fmt.Fprintf(&b, "(%s) Tj T*\n", textoPDF(linea.texto))
+	}
+	fmt.Fprintf(&b, "ET\nBT\n/F1 %d Tf\n%d %d Td\n(%s) Tj\nET", tamanoFuente-1, margen, margen-altoLinea, textoPDF(pie))
+	return b.String()
+}
+
+// textoPDF codifica el texto en WinAnsi y escapa los caracteres especiales de las cadenas PDF.
+// Los caracteres sin representación en WinAnsi se reemplazan por "?".
+func textoPDF(texto string) string {
+	var b strings.Builder
+	for _, r := range texto {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\t':
+			b.WriteString("    ")
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			if c, ok := winAnsi[r]; ok {
+				fmt.Fprintf(&b, "\\%03o", c)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
+
+// winAnsi son los caracteres fuera de Latin-1 que tiene la codificación WinAnsi
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '•': 0x95, '–': 0x96, '—': 0x97,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '™': 0x99,
+}
@@ -20,26 +20,42 @@ const (
 	TopicNotifications   = "notifications.events"
 	TopicStockEvents     = "stock.events"
 	TopicOrderEvents     = "order.events"
-	//TopicExternalEvents  = "external.events"
+	TopicProductEvents   = "product.events"
+	TopicTelemetryEvents = "telemetry.events"
+	TopicExternalEvents  = "external.events"
 )
 
 // Eventos del dominio
 
-// ProveedorCalificadoEvent se emite cuando un proveedor es calificado
+// ProveedorCalificadoEvent se emite cuando un proveedor es calificado. Con el mismo contenido se
+// emiten proveedor.activado y proveedor.actualizado.
 type ProveedorCalificadoEvent struct {
 	EventID     string    `json:"event_id"`
 	EventType   string    `json:"event_type"`
 	ProveedorID string    `json:"proveedor_id"`
 	Timestamp   time.Time `json:"timestamp"`
 	Data        struct {
-		NombreLegal         string   `json:"nombre_legal"`
-		RazonSocial         string   `json:"razon_social"`
-		ScoreGeneral        float64  `json:"score_general"`
-		Certificaciones     []string `json:"certificaciones"`
-		CapacidadCadenaFrio bool     `json:"capacidad_cadena_frio"`
+		NombreLegal         string            `json:"nombre_legal"`
+		RazonSocial         string            `json:"razon_social"`
+		ScoreGeneral        float64           `json:"score_general"`
+		Certificaciones     []string          `json:"certificaciones"`
+		CapacidadCadenaFrio bool              `json:"capacidad_cadena_frio"`
+		TemperaturaMinima   float64           `json:"temperatura_minima"`
+		TemperaturaMaxima   float64           `json:"temperatura_maxima"`
+		ProductosOfrecidos  []OfertaProveedor `json:"productos_ofrecidos"`
 	} `json:"data"`
 }
 
+// OfertaProveedor es un producto del catálogo del proveedor con sus precios
+type OfertaProveedor struct {
+	ProductoID           string     `json:"producto_id"`
+	PrecioBase           float64    `json:"precio_base"`
+	Moneda               string     `json:"moneda"`
+	EstadoDisponibilidad string     `json:"estado_disponibilidad"`
+	PrecioContratado     float64    `json:"precio_contratado,omitempty"`
+	ContratoVigenteHasta *time.Time `json:"contrato_vigente_hasta,omitempty"`
+}
+
 // ProveedorSuspendidoEvent se emite cuando un proveedor es suspendido
 type ProveedorSuspendidoEvent struct {
 	EventID     string    `json:"event_id"`
@@ -93,10 +109,28 @@ type OrdenCompraGeneradaEvent struct {
 		MotivoGeneracion string  `json:"motivo_generacion"`
 		Prioridad        string  `json:"prioridad"`
 		TotalItems       int     `json:"total_items"`
+		Moneda           string  `json:"moneda"`
+		Subtotal         float64 `json:"subtotal"`
+		Descuento        float64 `json:"descuento"`
+		Impuesto         float64 `json:"impuesto"`
 		ValorTotal       float64 `json:"valor_total"`
 	} `json:"data"`
 }
 
+// OrdenCompraEnviadaEvent se emite cuando una orden de compra se envía al proveedor
+type OrdenCompraEnviadaEvent struct {
+	EventID   string    `json:"event_id"`
+	EventType string    `json:"event_type"`
+	OrdenID   string    `json:"orden_id"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      struct {
+		NumeroOrden   string    `json:"numero_orden"`
+		ProveedorID   string    `json:"proveedor_id"`
+		FechaEnvio    time.Time `json:"fecha_envio"`
+		HashDocumento string    `json:"hash_documento"`
+	} `json:"data"`
+}
+
 // OrdenCompraConfirmadaEvent se emite cuando se confirma una orden de compra
 type OrdenCompraConfirmadaEvent struct {
 	EventID   string    `json:"event_id"`
@@ -123,6 +157,89 @@ type OrdenCompraRecibidaEvent struct {
 	} `json:"data"`
 }
 
+// OrdenCompraCanceladaEvent se emite cuando se cancela una orden de compra
+type OrdenCompraCanceladaEvent struct {
+	EventID   string    `json:"event_id"`
+	EventType string    `json:"event_type"`
+	OrdenID   string    `json:"orden_id"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      struct {
+		NumeroOrden       string    `json:"numero_orden"`
+		ProveedorID       string    `json:"proveedor_id"`
+		EstadoAnterior    string    `json:"estado_anterior"`
+		MotivoCancelacion string    `json:"motivo_cancelacion"`
+		FechaCancelacion  time.Time `json:"fecha_cancelacion"`
+	} `json:"data"`
+}
+
+// OrdenAprobacionSolicitadaEvent se emite cuando una orden queda pendiente de aprobación
+type OrdenAprobacionSolicitadaEvent struct {
+	EventID   string    `json:"event_id"`
+	EventType string    `json:"event_type"`
+	OrdenID   string    `json:"orden_id"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      struct {
+		NumeroOrden     string    `json:"numero_orden"`
+		ProveedorID     string    `json:"proveedor_id"`
+		Prioridad       string    `json:"prioridad"`
+		Moneda          string    `json:"moneda"`
+		ValorTotal      float64   `json:"valor_total"`
+		Reglas          []string  `json:"reglas"`
+		RolesRequeridos []string  `json:"roles_requeridos"`
+		Expedita        bool      `json:"expedita"`
+		RolPendiente    string    `json:"rol_pendiente"`
+		Vence           time.Time `json:"vence"`
+	} `json:"data"`
+}
+
+// OrdenAprobadaEvent se emite cada vez que se aprueba un nivel de una orden
+type OrdenAprobadaEvent struct {
+	EventID   string    `json:"event_id"`
+	EventType string    `json:"event_type"`
+	OrdenID   string    `json:"orden_id"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      struct {
+		NumeroOrden        string `json:"numero_orden"`
+		ProveedorID        string `json:"proveedor_id"`
+		UsuarioID          string `json:"usuario_id"`
+		Rol                string `json:"rol"`
+		Comentario         string `json:"comentario,omitempty"`
+		AprobacionCompleta bool   `json:"aprobacion_completa"`
+		RolPendiente       string `json:"rol_pendiente,omitempty"`
+	} `json:"data"`
+}
+
+// OrdenRechazadaEvent se emite cuando un aprobador rechaza una orden
+type OrdenRechazadaEvent struct {
+	EventID   string    `json:"event_id"`
+	EventType string    `json:"event_type"`
+	OrdenID   string    `json:"orden_id"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      struct {
+		NumeroOrden string `json:"numero_orden"`
+		ProveedorID string `json:"proveedor_id"`
+		UsuarioID   string `json:"usuario_id"`
+		Rol         string `json:"rol"`
+		Comentario  string `json:"comentario"`
+	} `json:"data"`
+}
+
+// OrdenAprobacionEscaladaEvent se emite cuando un nivel de aprobación vence sin decisión
+type OrdenAprobacionEscaladaEvent struct {
+	EventID   string    `json:"event_id"`
+	EventType string    `json:"event_type"`
+	OrdenID   string    `json:"orden_id"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      struct {
+		NumeroOrden   string    `json:"numero_orden"`
+		RolRequerido  string    `json:"rol_requerido"`
+		RolAnterior   string    `json:"rol_anterior"`
+		RolAsignado   string    `json:"rol_asignado"`
+		Escalamientos int       `json:"escalamientos"`
+		Vence         time.Time `json:"vence"`
+	} `json:"data"`
+}
+
 // StockBajoEvent se emite cuando el stock de un producto está bajo
 type StockBajoEvent struct {
 	EventID    string    `json:"event_id"`
@@ -151,6 +268,46 @@ type LoteDanadoEvent struct {
 		TemperaturaRegistrada float64 `json:"temperatura_registrada"`
 		TemperaturaRequerida  float64 `json:"temperatura_requerida"`
 		MotivoDanio           string  `json:"motivo_danio"`
+
+		// Datos medidos de la excursión cuando el daño lo detecta la telemetría
+		UnidadAlmacenamiento string     `json:"unidad_almacenamiento,omitempty"`
+		TemperaturaMinima    float64    `json:"temperatura_minima,omitempty"`
+		TemperaturaMaxima    float64    `json:"temperatura_maxima,omitempty"`
+		InicioExcursion      *time.Time `json:"inicio_excursion,omitempty"`
+		DuracionExcursion    string     `json:"duracion_excursion,omitempty"`
+		LecturasFueraRango   int        `json:"lecturas_fuera_rango,omitempty"`
+	} `json:"data"`
+}
+
+// LecturaTemperaturaEvent transporta una lectura de temperatura de un sensor de almacenamiento
+type LecturaTemperaturaEvent struct {
+	EventID   string    `json:"event_id"`
+	EventType string    `json:"event_type"`
+	Timestamp time.Time `json:"timestamp"`
+	Source    string    `json:"source"`
+	Data      struct {
+		UnidadAlmacenamiento string    `json:"unidad_almacenamiento"`
+		SensorID             string    `json:"sensor_id"`
+		ProductoID           string    `json:"producto_id"`
+		NumeroLote           string    `json:"numero_lote"`
+		Temperatura          float64   `json:"temperatura"`
+		FechaLectura         time.Time `json:"fecha_lectura"`
+	} `json:"data"`
+}
+
+// LoteVencidoEvent se emite cuando se da de baja un lote por vencimiento
+type LoteVencidoEvent struct {
+	EventID    string    `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	ProductoID string    `json:"producto_id"`
+	Timestamp  time.Time `json:"timestamp"`
+	Data       struct {
+		NombreProducto   string     `json:"nombre_producto"`
+		NumeroLote       string     `json:"numero_lote"`
+		FechaVencimiento *time.Time `json:"fecha_vencimiento,omitempty"`
+		CantidadVencida  int        `json:"cantidad_vencida"`
+		OrdenID          string     `json:"orden_id,omitempty"`
+		ProveedorID      string     `json:"proveedor_id,omitempty"`
 	} `json:"data"`
 }
 
@@ -166,6 +323,55 @@ type PronosticoDemandaAltaEvent struct {
 		StockActual         int     `json:"stock_actual"`
 		CantidadRequerida   int     `json:"cantidad_requerida"`
 		ConfianzaPronostico float64 `json:"confianza_pronostico"`
+		MetodoPronostico    string  `json:"metodo_pronostico,omitempty"`
+		HorizonteDias       int     `json:"horizonte_dias,omitempty"`
+		LimiteInferior      float64 `json:"limite_inferior,omitempty"`
+		LimiteSuperior      float64 `json:"limite_superior,omitempty"`
+	} `json:"data"`
+}
+
+// ProductoCreadoEvent se emite cuando se agrega un producto al catálogo
+type ProductoCreadoEvent struct {
+	EventID    string    `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	ProductoID string    `json:"producto_id"`
+	Timestamp  time.Time `json:"timestamp"`
+	Data       struct {
+		NombreProducto      string  `json:"nombre_producto"`
+		StockActual         int     `json:"stock_actual"`
+		PuntoReorden        int     `json:"punto_reorden"`
+		StockMaximo         int     `json:"stock_maximo"`
+		CadenaFrioRequerida bool    `json:"cadena_frio_requerida"`
+		TemperaturaMinima   float64 `json:"temperatura_minima"`
+		TemperaturaMaxima   float64 `json:"temperatura_maxima"`
+	} `json:"data"`
+}
+
+// ProductoActualizadoEvent se emite cuando se modifica un producto del catálogo
+type ProductoActualizadoEvent struct {
+	EventID    string    `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	ProductoID string    `json:"producto_id"`
+	Timestamp  time.Time `json:"timestamp"`
+	Data       struct {
+		NombreProducto      string  `json:"nombre_producto"`
+		StockActual         int     `json:"stock_actual"`
+		PuntoReorden        int     `json:"punto_reorden"`
+		StockMaximo         int     `json:"stock_maximo"`
+		CadenaFrioRequerida bool    `json:"cadena_frio_requerida"`
+		TemperaturaMinima   float64 `json:"temperatura_minima"`
+		TemperaturaMaxima   float64 `json:"temperatura_maxima"`
+	} `json:"data"`
+}
+
+// ProductoEliminadoEvent se emite cuando se elimina un producto del catálogo
+type ProductoEliminadoEvent struct {
+	EventID    string    `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	ProductoID string    `json:"producto_id"`
+	Timestamp  time.Time `json:"timestamp"`
+	Data       struct {
+		NombreProducto string `json:"nombre_producto"`
 	} `json:"data"`
 }
 
@@ -249,15 +455,28 @@ const (
 	EventTypeProveedorCalificado    = "proveedor.calificado"
 	EventTypeProveedorSuspendido    = "proveedor.suspendido"
 	EventTypeProveedorActivado      = "proveedor.activado"
+	EventTypeProveedorActualizado   = "proveedor.actualizado"
 	EventTypeCertificacionPorVencer = "certificacion.por_vencer"
 	EventTypeEvaluacionActualizada  = "evaluacion.actualizada"
 	EventTypeCertificacionVencida   = "certificacion.vencida"
 	EventTypeOrdenCompraGenerada    = "orden.generada"
 	EventTypeOrdenCompraConfirmada  = "orden.confirmada"
 	EventTypeOrdenCompraRecibida    = "orden.recibida"
+	EventTypeOrdenCompraEnviada     = "orden.enviada"
+	EventTypeOrdenCompraCancelada   = "orden.cancelada"
 	EventTypeStockBajo              = "stock.bajo"
 	EventTypeLoteDanado             = "stock.lote_danado"
+	EventTypeLoteVencido            = "stock.lote_vencido"
 	EventTypePronosticoDemandaAlta  = "stock.demanda_alta"
+	EventTypeLecturaTemperatura     = "telemetria.temperatura"
+	EventTypeProductoCreado         = "producto.creado"
+	EventTypeProductoActualizado    = "producto.actualizado"
+	EventTypeProductoEliminado      = "producto.eliminado"
+	// Eventos de aprobación de órdenes
+	EventTypeOrdenAprobacionSolicitada = "orden.aprobacion_solicitada"
+	EventTypeOrdenAprobada             = "orden.aprobada"
+	EventTypeOrdenRechazada            = "orden.rechazada"
+	EventTypeOrdenAprobacionEscalada   = "orden.aprobacion_escalada"
 	// Eventos externos
 	EventTypeStockBajoExterno        = "external.stock.bajo"
 	EventTypeDemandaAltaExterna      = "external.demanda.alta"
@@ -37,6 +37,9 @@ func NewRabbitMQEventBus(rabbitmqURL string, log *logrus.Logger) (*RabbitMQEvent
 		TopicNotifications,
 		TopicStockEvents,
 		TopicOrderEvents,
+		TopicProductEvents,
+		TopicTelemetryEvents,
+		TopicExternalEvents,
 	}
 
 	for _, exchange := range exchanges {
@@ -58,15 +61,20 @@ func NewRabbitMQEventBus(rabbitmqURL string, log *logrus.Logger) (*RabbitMQEvent
 
 	// Declarar colas principales
 	queues := map[string]string{
-		"proveedor.events":            TopicProveedorEvents,
-		"notifications":               TopicNotifications,
-		"stock.events":                TopicStockEvents,
-		"order.events":                TopicOrderEvents,
-		"proveedor.audit":             TopicProveedorEvents,
-		"proveedor.evaluation":        TopicProveedorEvents,
-		"purchase-order-stock-bajo":   TopicStockEvents,
-		"purchase-order-lote-danado":  TopicStockEvents,
-		"purchase-order-demanda-alta": TopicStockEvents,
+		"proveedor.events":               TopicProveedorEvents,
+		"notifications":                  TopicNotifications,
+		"stock.events":                   TopicStockEvents,
+		"order.events":                   TopicOrderEvents,
+		"product.events":                 TopicProductEvents,
+		"proveedor.audit":                TopicProveedorEvents,
+		"proveedor.evaluation":           TopicProveedorEvents,
+		"purchase-order-stock-bajo":      TopicStockEvents,
+		"purchase-order-lote-danado":     TopicStockEvents,
+		"purchase-order-lote-vencido":    TopicStockEvents,
+		"purchase-order-demanda-alta":    TopicStockEvents,
+		"purchase-order-telemetria":      TopicTelemetryEvents,
+		"purchase-order-external-events": TopicExternalEvents,
+		"purchase-order-proveedores":     TopicProveedorEvents,
 	}
 
 	for queueName, exchange := range queues {
@@ -253,12 +261,34 @@ func (r *RabbitMQEventBus) getRoutingKey(event interface{}) string {
 		return "orden.confirmada"
 	case *OrdenCompraRecibidaEvent:
 		return "orden.recibida"
+	case *OrdenCompraEnviadaEvent:
+		return "orden.enviada"
+	case *OrdenCompraCanceladaEvent:
+		return "orden.cancelada"
+	case *OrdenAprobacionSolicitadaEvent:
+		return "orden.aprobacion_solicitada"
+	case *OrdenAprobadaEvent:
+		return "orden.aprobada"
+	case *OrdenRechazadaEvent:
+		return "orden.rechazada"
+	case *OrdenAprobacionEscaladaEvent:
+		return "orden.aprobacion_escalada"
 	case *StockBajoEvent:
 		return "stock.bajo"
 	case *LoteDanadoEvent:
 		return "stock.lote_danado"
+	case *LoteVencidoEvent:
+		return "stock.lote_vencido"
+	case *LecturaTemperaturaEvent:
+		return "telemetria.temperatura"
 	case *PronosticoDemandaAltaEvent:
 		return "stock.demanda_alta"
+	case *ProductoCreadoEvent:
+		return "producto.creado"
+	case *ProductoActualizadoEvent:
+		return "producto.actualizado"
+	case *ProductoEliminadoEvent:
+		return "producto.eliminado"
 	case *StockBajoExternoEvent:
 		return "external.stock.bajo"
 	case *DemandaAltaExternaEvent:
@@ -289,12 +319,34 @@ func (r *RabbitMQEventBus) getEventType(event interface{}) string {
 		return "OrdenCompraConfirmada"
 	case *OrdenCompraRecibidaEvent:
 		return "OrdenCompraRecibida"
+	case *OrdenCompraEnviadaEvent:
+		return "OrdenCompraEnviada"
+	case *OrdenCompraCanceladaEvent:
+		return "OrdenCompraCancelada"
+	case *OrdenAprobacionSolicitadaEvent:
+		return "OrdenAprobacionSolicitada"
+	case *OrdenAprobadaEvent:
+		return "OrdenAprobada"
+	case *OrdenRechazadaEvent:
+		return "OrdenRechazada"
+	case *OrdenAprobacionEscaladaEvent:
+		return "OrdenAprobacionEscalada"
 	case *StockBajoEvent:
 		return "StockBajo"
 	case *LoteDanadoEvent:
 		return "LoteDanado"
+	case *LoteVencidoEvent:
+		return "LoteVencido"
+	case *LecturaTemperaturaEvent:
+		return "LecturaTemperatura"
 	case *PronosticoDemandaAltaEvent:
 		return "PronosticoDemandaAlta"
+	case *ProductoCreadoEvent:
+		return "ProductoCreado"
+	case *ProductoActualizadoEvent:
+		return "ProductoActualizado"
+	case *ProductoEliminadoEvent:
+		return "ProductoEliminado"
 	case *StockBajoExternoEvent:
 		return "StockBajoExterno"
 	case *DemandaAltaExternaEvent:
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"mediplus/purchase-order-service/internal/models"
+	"mediplus/purchase-order-service/internal/repository"
+	"mediplus/purchase-order-service/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// BudgetHandler maneja las peticiones HTTP para presupuestos por centro de costo
+type BudgetHandler struct {
+	service service.BudgetService
+	log     *logrus.Logger
+}
+
+// NewBudgetHandler crea una nueva instancia de BudgetHandler
+func NewBudgetHandler(service service.BudgetService, log *logrus.Logger) *BudgetHandler {
+	return &BudgetHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// CreateBudgetRequest representa la petición para asignar un presupuesto
+type CreateBudgetRequest struct {
+	CentroCostoID string        `json:"centro_costo_id" binding:"required"`
+	Periodo       string        `json:"periodo" binding:"required"`
+	MontoAsignado *models.Monto `json:"monto_asignado" binding:"required"`
+}
+
+// UpdateBudgetRequest representa la petición para cambiar el monto asignado de un presupuesto
+type UpdateBudgetRequest struct {
+	MontoAsignado *models.Monto `json:"monto_asignado" binding:"required"`
+}
+
+// CreateBudget asigna el presupuesto de un centro de costo para un período
+func (h *BudgetHandler) CreateBudget(c *gin.Context) {
+	var req CreateBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Errorf("Error binding request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	presupuesto := models.NewPresupuesto(req.CentroCostoID, req.Periodo, *req.MontoAsignado)
+
+	err := h.service.CreateBudget(presupuesto)
+	if h.responderErrorPresupuesto(c, err) {
+		return
+	}
+	if err != nil {
+		h.log.Errorf("Error creating budget: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating budget"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Budget created successfully",
+		"data":    presupuesto,
+	})
+}
+
+// ListBudgets lista los presupuestos, opcionalmente los de un centro de costo
+func (h *BudgetHandler) ListBudgets(c *gin.Context) {
+	presupuestos, err := h.service.ListBudgets(c.Query("centro_costo_id"))
+	if err != nil {
+		h.log.Errorf("Error listing budgets: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing budgets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": presupuestos})
+}
+
+// GetBudget obtiene el presupuesto de un centro de costo para un período
+func (h *BudgetHandler) GetBudget(c *gin.Context) {
+	presupuesto, err := h.service.GetBudget(c.Param("centro"), c.Param("periodo"))
+	if err != nil {
+		h.log.Errorf("Error getting budget: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error getting budget"})
+		return
+	}
+
+	if presupuesto == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Budget not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": presupuesto})
+}
+
+// UpdateBudget cambia el monto asignado de un presupuesto
+func (h *BudgetHandler) UpdateBudget(c *gin.Context) {
+	var req UpdateBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Errorf("Error binding request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	presupuesto, err := h.service.UpdateBudgetAmount(c.Param("centro"), c.Param("periodo"), *req.MontoAsignado)
+	if h.responderErrorPresupuesto(c, err) {
+		return
+	}
+	if err != nil {
+		h.log.Errorf("Error updating budget: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating budget"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Budget updated successfully",
+		"data":    presupuesto,
+	})
+}
+
+// GetBudgetConsumption retorna lo comprometido, ejecutado y disponible de un presupuesto y el
+// detalle por orden
+func (h *BudgetHandler) GetBudgetConsumption(c *gin.Context) {
+	consumo, err := h.service.GetBudgetConsumption(c.Param("centro"), c.Param("periodo"))
+	if h.responderErrorPresupuesto(c, err) {
+		return
+	}
+	if err != nil {
+		h.log.Errorf("Error getting budget consumption: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error getting budget consumption"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": consumo})
+}
+
+// responderErrorPresupuesto responde los errores de validación y de estado de un presupuesto.
+// Retorna true si el error fue respondido.
+func (h *BudgetHandler) responderErrorPresupuesto(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, models.ErrPresupuestoInvalido):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrPresupuestoNoEncontrado):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Budget not found"})
+	case errors.Is(err, repository.ErrPresupuestoExistente), errors.Is(err, repository.ErrPresupuestoModificado):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrPresupuestoInsuficiente):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		return false
+	}
+	return true
+}
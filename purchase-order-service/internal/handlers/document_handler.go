@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"mediplus/purchase-order-service/internal/models"
+	"mediplus/purchase-order-service/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// DocumentHandler maneja las peticiones HTTP para los documentos de órdenes de compra
+type DocumentHandler struct {
+	service service.DocumentService
+	log     *logrus.Logger
+}
+
+// NewDocumentHandler crea una nueva instancia de DocumentHandler
+func NewDocumentHandler(service service.DocumentService, log *logrus.Logger) *DocumentHandler {
+	return &DocumentHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// GetOrderDocument retorna el documento de la orden en HTML o PDF (parámetro formato)
+func (h *DocumentHandler) GetOrderDocument(c *gin.Context) {
+	ordenID := c.Param("id")
+	if ordenID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Order ID is required"})
+		return
+	}
+
+	formato, err := models.ParseFormatoDocumento(c.Query("formato"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document format, expected html or pdf"})
+		return
+	}
+
+	archivo, err := h.service.GetOrderDocument(ordenID, formato)
+	if errors.Is(err, service.ErrOrderNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		return
+	}
+	if err != nil {
+		h.log.Errorf("Error getting order document: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error getting order document"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", archivo.Nombre))
+	c.Header("X-Document-Hash", archivo.Hash)
+	if archivo.Borrador {
+		c.Header("X-Document-Draft", "true")
+	}
+	c.Data(http.StatusOK, archivo.ContentType, archivo.Contenido)
+}
+
+// VerifyOrderDocument verifica el hash impreso en el documento de una orden
+func (h *DocumentHandler) VerifyOrderDocument(c *gin.Context) {
+	ordenID := c.Param("id")
+	hash := c.Query("hash")
+	if ordenID == "" || hash == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Order ID and hash are required"})
+		return
+	}
+
+	verificacion, err := h.service.VerifyOrderDocument(ordenID, hash)
+	switch {
+	case errors.Is(err, service.ErrOrderNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		return
+	case errors.Is(err, service.ErrDocumentNotIssued):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		h.log.Errorf("Error verifying order document: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error verifying order document"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": verificacion})
+}
@@ -32,6 +32,12 @@ func (h *EventHandler) HandleStockBajoEvent(eventData []byte) error {
 		return err
 	}
 
+	// Todas las colas de stock reciben cada evento del exchange; ignorar los de otro tipo
+	if stockEvent.EventType != events.EventTypeStockBajo {
+		h.log.Debugf("Ignoring %s event on StockBajo handler", stockEvent.EventType)
+		return nil
+	}
+
 	h.log.WithFields(logrus.Fields{
 		"event_id":           stockEvent.EventID,
 		"producto_id":        stockEvent.ProductoID,
@@ -65,11 +71,18 @@ func (h *EventHandler) HandleLoteDanadoEvent(eventData []byte) error {
 		return err
 	}
 
+	// Todas las colas de stock reciben cada evento del exchange; ignorar los de otro tipo
+	if loteEvent.EventType != events.EventTypeLoteDanado {
+		h.log.Debugf("Ignoring %s event on LoteDanado handler", loteEvent.EventType)
+		return nil
+	}
+
 	h.log.WithFields(logrus.Fields{
 		"event_id":        loteEvent.EventID,
 		"producto_id":     loteEvent.ProductoID,
 		"lote_id":         loteEvent.Data.LoteID,
 		"cantidad_danada": loteEvent.Data.CantidadDanada,
+		"motivo_danio":    loteEvent.Data.MotivoDanio,
 	}).Info("Processing LoteDanado event")
 
 	// Procesar el evento de lote dañado y crear orden automáticamente
@@ -77,7 +90,7 @@ func (h *EventHandler) HandleLoteDanadoEvent(eventData []byte) error {
 		loteEvent.ProductoID,
 		loteEvent.Data.LoteID,
 		loteEvent.Data.CantidadDanada,
-		loteEvent.Data.TemperaturaRegistrada,
+		loteEvent.Data.MotivoDanio,
 	)
 	if err != nil {
 		h.log.Errorf("Error processing damaged batch event: %v", err)
@@ -93,6 +106,49 @@ func (h *EventHandler) HandleLoteDanadoEvent(eventData []byte) error {
 	return nil
 }
 
+// HandleLoteVencidoEvent maneja eventos de lote vencido
+func (h *EventHandler) HandleLoteVencidoEvent(eventData []byte) error {
+	h.log.Info("Received LoteVencido event")
+
+	var loteEvent events.LoteVencidoEvent
+	if err := json.Unmarshal(eventData, &loteEvent); err != nil {
+		h.log.Errorf("Error unmarshaling LoteVencido event: %v", err)
+		return err
+	}
+
+	// Todas las colas de stock reciben cada evento del exchange; ignorar los de otro tipo
+	if loteEvent.EventType != events.EventTypeLoteVencido {
+		h.log.Debugf("Ignoring %s event on LoteVencido handler", loteEvent.EventType)
+		return nil
+	}
+
+	h.log.WithFields(logrus.Fields{
+		"event_id":         loteEvent.EventID,
+		"producto_id":      loteEvent.ProductoID,
+		"numero_lote":      loteEvent.Data.NumeroLote,
+		"cantidad_vencida": loteEvent.Data.CantidadVencida,
+	}).Info("Processing LoteVencido event")
+
+	// Reponer el producto si la baja lo dejó bajo el punto de reorden
+	err := h.orderService.ProcessLoteVencidoEvent(
+		loteEvent.ProductoID,
+		loteEvent.Data.NumeroLote,
+		loteEvent.Data.CantidadVencida,
+	)
+	if err != nil {
+		h.log.Errorf("Error processing expired lot event: %v", err)
+		return err
+	}
+
+	h.log.WithFields(logrus.Fields{
+		"event_id":    loteEvent.EventID,
+		"producto_id": loteEvent.ProductoID,
+		"numero_lote": loteEvent.Data.NumeroLote,
+	}).Info("Successfully processed LoteVencido event")
+
+	return nil
+}
+
 // HandlePronosticoDemandaAltaEvent maneja eventos de pronóstico de alta demanda
 func (h *EventHandler) HandlePronosticoDemandaAltaEvent(eventData []byte) error {
 	h.log.Info("Received PronosticoDemandaAlta event")
@@ -103,6 +159,12 @@ func (h *EventHandler) HandlePronosticoDemandaAltaEvent(eventData []byte) error
 		return err
 	}
 
+	// Todas las colas de stock reciben cada evento del exchange; ignorar los de otro tipo
+	if pronosticoEvent.EventType != events.EventTypePronosticoDemandaAlta {
+		h.log.Debugf("Ignoring %s event on PronosticoDemandaAlta handler", pronosticoEvent.EventType)
+		return nil
+	}
+
 	h.log.WithFields(logrus.Fields{
 		"event_id":             pronosticoEvent.EventID,
 		"producto_id":          pronosticoEvent.ProductoID,
@@ -169,14 +169,9 @@ func (h *ExternalEventHandler) handleAlertaInventarioExterna(eventData []byte) e
 
 // createAutoOrderFromStockBajo crea una orden automática basada en stock bajo
 func (h *ExternalEventHandler) createAutoOrderFromStockBajo(event *events.StockBajoExternoEvent) (*models.OrdenCompra, error) {
-	// Buscar proveedor para el producto (por simplicidad, usamos el primer proveedor activo)
-	// En un sistema real, esto debería buscar el mejor proveedor para el producto específico
-	proveedorID := "dea038d9-d9fa-4cc8-8016-fa20fdc4bcd7" // Proveedor de prueba
-
 	orden := &models.OrdenCompra{
 		OrdenID:          uuid.New().String(),
-		NumeroOrden:      h.generateOrderNumber("STOCK"),
-		ProveedorID:      proveedorID,
+		OrigenNumeracion: "STOCK",
 		FechaGeneracion:  time.Now(),
 		EstadoOrden:      models.EstadoGenerada,
 		Prioridad:        h.mapPriority(event.Data.Prioridad),
@@ -186,7 +181,7 @@ func (h *ExternalEventHandler) createAutoOrderFromStockBajo(event *events.StockB
 				ItemID:               uuid.New().String(),
 				ProductoID:           event.ProductoID,
 				CantidadSolicitada:   event.Data.CantidadRequerida,
-				PrecioUnitario:       0, // Se calculará después
+				PrecioUnitario:       0, // Se toma del proveedor al crear la orden
 				TemperaturaRequerida: 0,
 				EstadoItem:           models.EstadoItemPendiente,
 			},
@@ -195,21 +190,14 @@ func (h *ExternalEventHandler) createAutoOrderFromStockBajo(event *events.StockB
 		UpdatedAt: time.Now(),
 	}
 
-	err := h.orderService.CreateOrder(orden)
-	if err != nil {
-		return nil, err
-	}
-	return orden, nil
+	return h.createAutoOrder(orden)
 }
 
 // createAutoOrderFromDemandaAlta crea una orden automática basada en demanda alta
 func (h *ExternalEventHandler) createAutoOrderFromDemandaAlta(event *events.DemandaAltaExternaEvent) (*models.OrdenCompra, error) {
-	proveedorID := "dea038d9-d9fa-4cc8-8016-fa20fdc4bcd7" // Proveedor de prueba
-
 	orden := &models.OrdenCompra{
 		OrdenID:          uuid.New().String(),
-		NumeroOrden:      h.generateOrderNumber("DEMANDA"),
-		ProveedorID:      proveedorID,
+		OrigenNumeracion: "DEMANDA",
 		FechaGeneracion:  time.Now(),
 		EstadoOrden:      models.EstadoGenerada,
 		Prioridad:        h.mapPriority(event.Data.Prioridad),
@@ -228,21 +216,14 @@ func (h *ExternalEventHandler) createAutoOrderFromDemandaAlta(event *events.Dema
 		UpdatedAt: time.Now(),
 	}
 
-	err := h.orderService.CreateOrder(orden)
-	if err != nil {
-		return nil, err
-	}
-	return orden, nil
+	return h.createAutoOrder(orden)
 }
 
 // createAutoOrderFromLoteDanado crea una orden automática basada en lote dañado
 func (h *ExternalEventHandler) createAutoOrderFromLoteDanado(event *events.LoteDanadoExternoEvent) (*models.OrdenCompra, error) {
-	proveedorID := "dea038d9-d9fa-4cc8-8016-fa20fdc4bcd7" // Proveedor de prueba
-
 	orden := &models.OrdenCompra{
 		OrdenID:          uuid.New().String(),
-		NumeroOrden:      h.generateOrderNumber("LOTE"),
-		ProveedorID:      proveedorID,
+		OrigenNumeracion: "LOTE",
 		FechaGeneracion:  time.Now(),
 		EstadoOrden:      models.EstadoGenerada,
 		Prioridad:        models.PrioridadAlta, // Siempre alta prioridad para lotes dañados
@@ -261,21 +242,14 @@ func (h *ExternalEventHandler) createAutoOrderFromLoteDanado(event *events.LoteD
 		UpdatedAt: time.Now(),
 	}
 
-	err := h.orderService.CreateOrder(orden)
-	if err != nil {
-		return nil, err
-	}
-	return orden, nil
+	return h.createAutoOrder(orden)
 }
 
 // createAutoOrderFromAlertaInventario crea una orden automática basada en alerta de inventario
 func (h *ExternalEventHandler) createAutoOrderFromAlertaInventario(event *events.AlertaInventarioExternaEvent) (*models.OrdenCompra, error) {
-	proveedorID := "dea038d9-d9fa-4cc8-8016-fa20fdc4bcd7" // Proveedor de prueba
-
 	orden := &models.OrdenCompra{
 		OrdenID:          uuid.New().String(),
-		NumeroOrden:      h.generateOrderNumber("ALERTA"),
-		ProveedorID:      proveedorID,
+		OrigenNumeracion: "ALERTA",
 		FechaGeneracion:  time.Now(),
 		EstadoOrden:      models.EstadoGenerada,
 		Prioridad:        h.mapPriority(event.Data.Prioridad),
@@ -294,19 +268,19 @@ func (h *ExternalEventHandler) createAutoOrderFromAlertaInventario(event *events
 		UpdatedAt: time.Now(),
 	}
 
-	err := h.orderService.CreateOrder(orden)
-	if err != nil {
+	return h.createAutoOrder(orden)
+}
+
+// createAutoOrder asigna un proveedor calificado a la orden y la crea
+func (h *ExternalEventHandler) createAutoOrder(orden *models.OrdenCompra) (*models.OrdenCompra, error) {
+	if err := h.orderService.AssignSupplier(orden); err != nil {
 		return nil, err
 	}
-	return orden, nil
-}
 
-// generateOrderNumber genera un número de orden único
-func (h *ExternalEventHandler) generateOrderNumber(prefix string) string {
-	return fmt.Sprintf("ORD-%s-%s-%s",
-		time.Now().Format("20060102"),
-		prefix,
-		uuid.New().String()[:8])
+	if err := h.orderService.CreateOrder(orden); err != nil {
+		return nil, err
+	}
+	return orden, nil
 }
 
 // mapPriority mapea prioridades de string a enum
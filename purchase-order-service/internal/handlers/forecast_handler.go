@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"mediplus/purchase-order-service/internal/models"
+	"mediplus/purchase-order-service/internal/service"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ForecastHandler expone el pronóstico de demanda de los productos
+type ForecastHandler struct {
+	service service.ForecastService
+	log     *logrus.Logger
+}
+
+// NewForecastHandler crea una nueva instancia de ForecastHandler
+func NewForecastHandler(service service.ForecastService, log *logrus.Logger) *ForecastHandler {
+	return &ForecastHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// GetForecast pronostica la demanda de un producto con intervalos de confianza y las métricas
+// de error de cada método
+func (h *ForecastHandler) GetForecast(c *gin.Context) {
+	productoID := c.Param("id")
+	if productoID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Product ID is required"})
+		return
+	}
+
+	horizonte := 0
+	if valor := c.Query("horizonte"); valor != "" {
+		dias, err := strconv.Atoi(valor)
+		if err != nil || dias <= 0 || dias > models.HorizonteMaximoDias {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("horizonte must be between 1 and %d days", models.HorizonteMaximoDias)})
+			return
+		}
+		horizonte = dias
+	}
+
+	metodo := models.MetodoPronostico(strings.ToUpper(c.Query("metodo")))
+
+	pronostico, err := h.service.ForecastDemand(productoID, horizonte, metodo)
+	switch {
+	case errors.Is(err, service.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	case errors.Is(err, models.ErrPronosticoInvalido):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, models.ErrHistorialInsuficiente):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		h.log.Errorf("Error forecasting demand: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error forecasting demand"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": pronostico})
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"errors"
+	"mediplus/purchase-order-service/internal/models"
+	"mediplus/purchase-order-service/internal/repository"
+	"mediplus/purchase-order-service/internal/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// InventoryHandler maneja las peticiones HTTP del libro de movimientos de inventario
+type InventoryHandler struct {
+	service service.InventoryService
+	log     *logrus.Logger
+}
+
+// NewInventoryHandler crea una nueva instancia de InventoryHandler
+func NewInventoryHandler(service service.InventoryService, log *logrus.Logger) *InventoryHandler {
+	return &InventoryHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// RecordMovementRequest representa la petición para registrar un movimiento de inventario.
+// Para AJUSTE la cantidad lleva signo; para el resto de tipos se indica en positivo.
+// En las salidas sin numero_lote se descuenta de los lotes que vencen primero.
+type RecordMovementRequest struct {
+	Tipo                models.TipoMovimiento `json:"tipo" binding:"required"`
+	Cantidad            int                   `json:"cantidad" binding:"required"`
+	Motivo              string                `json:"motivo" binding:"required"`
+	UsuarioID           string                `json:"usuario_id" binding:"required"`
+	DocumentoReferencia string                `json:"documento_referencia"`
+	NumeroLote          string                `json:"numero_lote"`
+	FechaVencimiento    *time.Time            `json:"fecha_vencimiento"`
+}
+
+// RecordMovement registra un movimiento de inventario para un producto
+func (h *InventoryHandler) RecordMovement(c *gin.Context) {
+	productoID := c.Param("id")
+	if productoID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Product ID is required"})
+		return
+	}
+
+	var req RecordMovementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Errorf("Error binding request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	movimiento, err := h.service.RecordMovement(productoID, req.Tipo, req.Cantidad, req.Motivo, req.UsuarioID,
+		req.DocumentoReferencia, req.NumeroLote, req.FechaVencimiento)
+	switch {
+	case errors.Is(err, service.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	case errors.Is(err, service.ErrLotNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lot not found"})
+		return
+	case errors.Is(err, models.ErrMovimientoInvalido):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, repository.ErrStockInsuficiente), errors.Is(err, repository.ErrLoteNoActivo):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		h.log.Errorf("Error recording inventory movement: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error recording inventory movement"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Inventory movement recorded successfully",
+		"data":    movimiento,
+	})
+}
+
+// ListMovements lista los movimientos de inventario de un producto
+func (h *InventoryHandler) ListMovements(c *gin.Context) {
+	productoID := c.Param("id")
+	if productoID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Product ID is required"})
+		return
+	}
+
+	movimientos, err := h.service.ListMovements(productoID)
+	if errors.Is(err, service.ErrProductNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	if err != nil {
+		h.log.Errorf("Error listing inventory movements: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing inventory movements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": movimientos})
+}
+
+// ReconcileProduct compara el stock de un producto con su libro de movimientos
+func (h *InventoryHandler) ReconcileProduct(c *gin.Context) {
+	productoID := c.Param("id")
+	if productoID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Product ID is required"})
+		return
+	}
+
+	conciliacion, err := h.service.ReconcileProduct(productoID)
+	if errors.Is(err, service.ErrProductNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	if err != nil {
+		h.log.Errorf("Error reconciling product stock: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error reconciling product stock"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": conciliacion})
+}
+
+// ReconcileAll lista los productos cuyo stock difiere de su libro de movimientos
+func (h *InventoryHandler) ReconcileAll(c *gin.Context) {
+	diferencias, err := h.service.ReconcileAll()
+	if err != nil {
+		h.log.Errorf("Error reconciling stock: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error reconciling stock"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": diferencias})
+}
+
+// ListLots lista los lotes de un producto, primero el que vence antes
+func (h *InventoryHandler) ListLots(c *gin.Context) {
+	productoID := c.Param("id")
+	if productoID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Product ID is required"})
+		return
+	}
+
+	lotes, err := h.service.ListLots(productoID)
+	if errors.Is(err, service.ErrProductNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	if err != nil {
+		h.log.Errorf("Error listing product lots: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing product lots"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": lotes})
+}
+
+// ListLotsNearExpiry lista los lotes con existencias que vencen en los próximos días (por defecto 30)
+func (h *InventoryHandler) ListLotsNearExpiry(c *gin.Context) {
+	dias, err := strconv.Atoi(c.DefaultQuery("dias", "30"))
+	if err != nil || dias < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dias must be a non-negative integer"})
+		return
+	}
+
+	lotes, err := h.service.ListLotsNearExpiry(dias)
+	if err != nil {
+		h.log.Errorf("Error listing lots near expiry: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing lots near expiry"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": lotes})
+}
@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"errors"
+	"mediplus/purchase-order-service/internal/clients"
 	"mediplus/purchase-order-service/internal/models"
+	"mediplus/purchase-order-service/internal/repository"
 	"mediplus/purchase-order-service/internal/service"
 	"net/http"
 
@@ -30,6 +33,7 @@ type CreateOrderRequest struct {
 	Prioridad        models.Prioridad         `json:"prioridad" binding:"required"`
 	Items            []models.ItemOrdenCompra `json:"items" binding:"required"`
 	Evaluacion       *models.Evaluacion       `json:"evaluacion"`
+	CentroCostoID    string                   `json:"centro_costo_id"`
 }
 
 // UpdateOrderRequest representa la petición para actualizar una orden
@@ -41,6 +45,26 @@ type UpdateOrderRequest struct {
 	Evaluacion       *models.Evaluacion       `json:"evaluacion"`
 }
 
+// CancelOrderRequest representa la petición para cancelar una orden
+type CancelOrderRequest struct {
+	Motivo string `json:"motivo" binding:"required"`
+}
+
+// RegisterReceiptRequest representa la petición para registrar una entrega contra una orden
+type RegisterReceiptRequest struct {
+	RecepcionID           string                  `json:"recepcion_id"` // opcional; hace idempotentes los reintentos
+	RecibidoPor           string                  `json:"recibido_por"`
+	PermitirExcederMaximo bool                    `json:"permitir_exceder_maximo"`
+	Lineas                []models.LineaRecepcion `json:"lineas" binding:"required"`
+}
+
+// ApprovalDecisionRequest representa la aprobación o el rechazo de una orden
+type ApprovalDecisionRequest struct {
+	UsuarioID  string `json:"usuario_id" binding:"required"`
+	Rol        string `json:"rol" binding:"required"`
+	Comentario string `json:"comentario"`
+}
+
 // AutoGenerateOrderRequest representa la petición para generar automáticamente una orden
 type AutoGenerateOrderRequest struct {
 	Trigger string `json:"trigger" binding:"required"`
@@ -53,10 +77,10 @@ type ProcessStockLowRequest struct {
 
 // ProcessLoteDanadoRequest representa la petición para procesar lote dañado
 type ProcessLoteDanadoRequest struct {
-	ProductoID            string  `json:"producto_id" binding:"required"`
-	LoteID                string  `json:"lote_id" binding:"required"`
-	CantidadDanada        int     `json:"cantidad_danada" binding:"required"`
-	TemperaturaRegistrada float64 `json:"temperatura_registrada" binding:"required"`
+	ProductoID     string `json:"producto_id" binding:"required"`
+	LoteID         string `json:"lote_id" binding:"required"`
+	CantidadDanada int    `json:"cantidad_danada" binding:"required"`
+	MotivoDanio    string `json:"motivo_danio"`
 }
 
 // ProcessPronosticoDemandaAltaRequest representa la petición para procesar pronóstico de alta demanda
@@ -76,10 +100,23 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 
 	// Crear la orden
 	orden := models.NewOrdenCompra(req.ProveedorID, req.MotivoGeneracion, req.Prioridad)
-	orden.Items = req.Items
+	orden.AsignarItems(req.Items)
 	orden.Evaluacion = req.Evaluacion
+	orden.CentroCostoID = req.CentroCostoID
 
 	err := h.service.CreateOrder(orden)
+	if errors.Is(err, models.ErrImporteInvalido) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, repository.ErrNumeracionModificada) || errors.Is(err, repository.ErrNumeroOrdenDuplicado) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if proveedorRechazado(err) || service.PresupuestoRechazado(err) || errors.Is(err, models.ErrPrecioNoResuelto) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		h.log.Errorf("Error creating order: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating order"})
@@ -115,6 +152,23 @@ func (h *OrderHandler) GetOrder(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": orden})
 }
 
+// GetOrderByNumero obtiene una orden por su número
+func (h *OrderHandler) GetOrderByNumero(c *gin.Context) {
+	orden, err := h.service.GetOrderByNumero(c.Param("numero"))
+	if err != nil {
+		h.log.Errorf("Error getting order by number: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error getting order"})
+		return
+	}
+
+	if orden == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": orden})
+}
+
 // UpdateOrder actualiza una orden
 func (h *OrderHandler) UpdateOrder(c *gin.Context) {
 	ordenID := c.Param("id")
@@ -154,13 +208,24 @@ func (h *OrderHandler) UpdateOrder(c *gin.Context) {
 		orden.Prioridad = req.Prioridad
 	}
 	if req.Items != nil {
-		orden.Items = req.Items
+		orden.AsignarItems(req.Items)
 	}
 	if req.Evaluacion != nil {
 		orden.Evaluacion = req.Evaluacion
 	}
 
 	err = h.service.UpdateOrder(orden)
+	if h.responderErrorTransicion(c, err) {
+		return
+	}
+	if errors.Is(err, models.ErrImporteInvalido) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if proveedorRechazado(err) || service.PresupuestoRechazado(err) || errors.Is(err, models.ErrPrecioNoResuelto) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		h.log.Errorf("Error updating order: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating order"})
@@ -182,6 +247,9 @@ func (h *OrderHandler) DeleteOrder(c *gin.Context) {
 	}
 
 	err := h.service.DeleteOrder(ordenID)
+	if h.responderErrorTransicion(c, err) {
+		return
+	}
 	if err != nil {
 		h.log.Errorf("Error deleting order: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error deleting order"})
@@ -221,6 +289,27 @@ func (h *OrderHandler) ListOrders(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": ordenes})
 }
 
+// SendOrder marca una orden como enviada al proveedor
+func (h *OrderHandler) SendOrder(c *gin.Context) {
+	ordenID := c.Param("id")
+	if ordenID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Order ID is required"})
+		return
+	}
+
+	err := h.service.SendOrder(ordenID)
+	if h.responderErrorTransicion(c, err) {
+		return
+	}
+	if err != nil {
+		h.log.Errorf("Error sending order: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error sending order"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Order sent successfully"})
+}
+
 // ConfirmOrder confirma una orden
 func (h *OrderHandler) ConfirmOrder(c *gin.Context) {
 	ordenID := c.Param("id")
@@ -230,6 +319,13 @@ func (h *OrderHandler) ConfirmOrder(c *gin.Context) {
 	}
 
 	err := h.service.ConfirmOrder(ordenID)
+	if h.responderErrorTransicion(c, err) {
+		return
+	}
+	if proveedorRechazado(err) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		h.log.Errorf("Error confirming order: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error confirming order"})
@@ -248,6 +344,14 @@ func (h *OrderHandler) ReceiveOrder(c *gin.Context) {
 	}
 
 	err := h.service.ReceiveOrder(ordenID)
+	if h.responderErrorTransicion(c, err) {
+		return
+	}
+	if errors.Is(err, repository.ErrStockMaximoExcedido) || errors.Is(err, repository.ErrProductoNoEncontrado) ||
+		errors.Is(err, repository.ErrLoteNoActivo) || service.PresupuestoRechazado(err) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		h.log.Errorf("Error receiving order: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error receiving order"})
@@ -257,7 +361,82 @@ func (h *OrderHandler) ReceiveOrder(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Order received successfully"})
 }
 
-// AutoGenerateOrder genera automáticamente una orden
+// RegisterReceipt registra una entrega total o parcial de una orden
+func (h *OrderHandler) RegisterReceipt(c *gin.Context) {
+	ordenID := c.Param("id")
+	if ordenID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Order ID is required"})
+		return
+	}
+
+	var req RegisterReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Errorf("Error binding request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recepcion := &models.Recepcion{
+		RecepcionID:           req.RecepcionID,
+		RecibidoPor:           req.RecibidoPor,
+		PermitirExcederMaximo: req.PermitirExcederMaximo,
+		Lineas:                req.Lineas,
+	}
+
+	orden, err := h.service.RegisterReceipt(ordenID, recepcion)
+	if h.responderErrorTransicion(c, err) {
+		return
+	}
+	if errors.Is(err, models.ErrRecepcionInvalida) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, repository.ErrStockMaximoExcedido) || errors.Is(err, repository.ErrProductoNoEncontrado) ||
+		errors.Is(err, repository.ErrLoteNoActivo) || service.PresupuestoRechazado(err) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.log.Errorf("Error registering order receipt: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error registering order receipt"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Receipt registered successfully",
+		"data":    orden,
+	})
+}
+
+// CancelOrder cancela una orden que aún no ha sido recibida
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	ordenID := c.Param("id")
+	if ordenID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Order ID is required"})
+		return
+	}
+
+	var req CancelOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Errorf("Error binding request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.service.CancelOrder(ordenID, req.Motivo)
+	if h.responderErrorTransicion(c, err) {
+		return
+	}
+	if err != nil {
+		h.log.Errorf("Error cancelling order: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error cancelling order"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Order cancelled successfully"})
+}
+
+// AutoGenerateOrder ejecuta una corrida de reposición y retorna las órdenes creadas y los productos omitidos
 func (h *OrderHandler) AutoGenerateOrder(c *gin.Context) {
 	var req AutoGenerateOrderRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -266,14 +445,114 @@ func (h *OrderHandler) AutoGenerateOrder(c *gin.Context) {
 		return
 	}
 
-	err := h.service.AutoGenerateOrder(req.Trigger)
+	resumen, err := h.service.AutoGenerateOrder(req.Trigger)
+	if errors.Is(err, clients.ErrCircuitOpen) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Supplier service unavailable"})
+		return
+	}
 	if err != nil {
 		h.log.Errorf("Error auto-generating order: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error auto-generating order"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Order auto-generated successfully"})
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Replenishment run completed",
+		"data":    resumen,
+	})
+}
+
+// ApproveOrder aprueba el nivel en curso de una orden pendiente de aprobación
+func (h *OrderHandler) ApproveOrder(c *gin.Context) {
+	ordenID := c.Param("id")
+	if ordenID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Order ID is required"})
+		return
+	}
+
+	var req ApprovalDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Errorf("Error binding request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	orden, err := h.service.ApproveOrder(ordenID, req.UsuarioID, req.Rol, req.Comentario)
+	if h.responderErrorTransicion(c, err) || h.responderErrorAprobacion(c, err) {
+		return
+	}
+	if service.PresupuestoRechazado(err) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.log.Errorf("Error approving order: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error approving order"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Order approved successfully",
+		"data":    orden,
+	})
+}
+
+// RejectOrder rechaza una orden pendiente de aprobación; el comentario es obligatorio
+func (h *OrderHandler) RejectOrder(c *gin.Context) {
+	ordenID := c.Param("id")
+	if ordenID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Order ID is required"})
+		return
+	}
+
+	var req ApprovalDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Errorf("Error binding request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Comentario == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "comentario is required to reject an order"})
+		return
+	}
+
+	orden, err := h.service.RejectOrder(ordenID, req.UsuarioID, req.Rol, req.Comentario)
+	if h.responderErrorTransicion(c, err) || h.responderErrorAprobacion(c, err) {
+		return
+	}
+	if err != nil {
+		h.log.Errorf("Error rejecting order: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error rejecting order"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Order rejected successfully",
+		"data":    orden,
+	})
+}
+
+// ListPendingApprovals lista las órdenes pendientes de aprobación, opcionalmente las que puede decidir un rol
+func (h *OrderHandler) ListPendingApprovals(c *gin.Context) {
+	ordenes, err := h.service.ListPendingApprovals(c.Query("rol"))
+	if err != nil {
+		h.log.Errorf("Error listing orders pending approval: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing orders pending approval"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": ordenes})
+}
+
+// GetApprovalPolicy retorna la política de aprobación vigente
+func (h *OrderHandler) GetApprovalPolicy(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": h.service.GetApprovalPolicy()})
+}
+
+// GetTaxPolicy retorna la política de impuestos vigente
+func (h *OrderHandler) GetTaxPolicy(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": h.service.GetTaxPolicy()})
 }
 
 // ProcessStockLow procesa un evento de stock bajo
@@ -304,7 +583,7 @@ func (h *OrderHandler) ProcessLoteDanado(c *gin.Context) {
 		return
 	}
 
-	err := h.service.ProcessLoteDanadoEvent(req.ProductoID, req.LoteID, req.CantidadDanada, req.TemperaturaRegistrada)
+	err := h.service.ProcessLoteDanadoEvent(req.ProductoID, req.LoteID, req.CantidadDanada, req.MotivoDanio)
 	if err != nil {
 		h.log.Errorf("Error processing damaged batch event: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error processing damaged batch event"})
@@ -332,3 +611,34 @@ func (h *OrderHandler) ProcessPronosticoDemandaAlta(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "High demand forecast event processed successfully"})
 }
+
+// responderErrorTransicion responde 404 o 409 para los errores de cambio de estado de una orden.
+// Retorna true si el error fue respondido.
+func (h *OrderHandler) responderErrorTransicion(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrOrderNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+	case errors.Is(err, models.ErrTransicionInvalida), errors.Is(err, repository.ErrOrdenModificada):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		return false
+	}
+	return true
+}
+
+// responderErrorAprobacion responde 403 cuando quien decide no puede aprobar o rechazar el nivel
+// en curso. Retorna true si el error fue respondido.
+func (h *OrderHandler) responderErrorAprobacion(c *gin.Context, err error) bool {
+	if errors.Is(err, models.ErrRolInsuficiente) || errors.Is(err, models.ErrAprobadorRepetido) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return true
+	}
+	return false
+}
+
+// proveedorRechazado indica si el error corresponde a un proveedor que no puede recibir la orden
+func proveedorRechazado(err error) bool {
+	return errors.Is(err, service.ErrColdChainIncompatible) ||
+		errors.Is(err, service.ErrUnknownSupplier) ||
+		errors.Is(err, service.ErrSupplierNotActive)
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"errors"
+	"mediplus/purchase-order-service/internal/models"
+	"mediplus/purchase-order-service/internal/service"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ProductHandler maneja las peticiones HTTP para el catálogo de productos
+type ProductHandler struct {
+	service service.ProductService
+	log     *logrus.Logger
+}
+
+// NewProductHandler crea una nueva instancia de ProductHandler
+func NewProductHandler(service service.ProductService, log *logrus.Logger) *ProductHandler {
+	return &ProductHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// CreateProductRequest representa la petición para crear un producto
+type CreateProductRequest struct {
+	Nombre             string                     `json:"nombre" binding:"required"`
+	Categoria          string                     `json:"categoria"`
+	StockActual        int                        `json:"stock_actual"`
+	PuntoReorden       int                        `json:"punto_reorden"`
+	StockMaximo        int                        `json:"stock_maximo" binding:"required"`
+	Condiciones        *models.Condiciones        `json:"condiciones"`
+	PoliticaReposicion *models.PoliticaReposicion `json:"politica_reposicion"`
+}
+
+// UpdateProductRequest representa la petición para actualizar un producto.
+// El stock no se actualiza aquí sino registrando movimientos de inventario.
+type UpdateProductRequest struct {
+	Nombre             string                     `json:"nombre"`
+	Categoria          string                     `json:"categoria"`
+	PuntoReorden       *int                       `json:"punto_reorden"`
+	StockMaximo        *int                       `json:"stock_maximo"`
+	Condiciones        *models.Condiciones        `json:"condiciones"`
+	PoliticaReposicion *models.PoliticaReposicion `json:"politica_reposicion"`
+}
+
+// CreateProduct crea un nuevo producto
+func (h *ProductHandler) CreateProduct(c *gin.Context) {
+	var req CreateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Errorf("Error binding request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	producto := models.NewProducto(req.Nombre, req.StockActual, req.PuntoReorden, req.StockMaximo)
+	producto.Categoria = strings.ToUpper(req.Categoria)
+	producto.Condiciones = req.Condiciones
+	producto.PoliticaReposicion = req.PoliticaReposicion
+
+	err := h.service.CreateProduct(producto)
+	if errors.Is(err, models.ErrProductoInvalido) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.log.Errorf("Error creating product: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating product"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Product created successfully",
+		"data":    producto,
+	})
+}
+
+// GetProduct obtiene un producto por ID
+func (h *ProductHandler) GetProduct(c *gin.Context) {
+	productoID := c.Param("id")
+	if productoID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Product ID is required"})
+		return
+	}
+
+	producto, err := h.service.GetProduct(productoID)
+	if err != nil {
+		h.log.Errorf("Error getting product: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error getting product"})
+		return
+	}
+
+	if producto == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": producto})
+}
+
+// UpdateProduct actualiza un producto
+func (h *ProductHandler) UpdateProduct(c *gin.Context) {
+	productoID := c.Param("id")
+	if productoID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Product ID is required"})
+		return
+	}
+
+	var req UpdateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Errorf("Error binding request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Obtener el producto actual
+	producto, err := h.service.GetProduct(productoID)
+	if err != nil {
+		h.log.Errorf("Error getting product: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error getting product"})
+		return
+	}
+
+	if producto == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	// Actualizar campos
+	if req.Nombre != "" {
+		producto.Nombre = req.Nombre
+	}
+	if req.Categoria != "" {
+		producto.Categoria = strings.ToUpper(req.Categoria)
+	}
+	if req.PuntoReorden != nil {
+		producto.PuntoReorden = *req.PuntoReorden
+	}
+	if req.StockMaximo != nil {
+		producto.StockMaximo = *req.StockMaximo
+	}
+	if req.Condiciones != nil {
+		producto.Condiciones = req.Condiciones
+	}
+	if req.PoliticaReposicion != nil {
+		producto.PoliticaReposicion = req.PoliticaReposicion
+	}
+
+	err = h.service.UpdateProduct(producto)
+	if errors.Is(err, models.ErrProductoInvalido) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.log.Errorf("Error updating product: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating product"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Product updated successfully",
+		"data":    producto,
+	})
+}
+
+// DeleteProduct elimina un producto
+func (h *ProductHandler) DeleteProduct(c *gin.Context) {
+	productoID := c.Param("id")
+	if productoID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Product ID is required"})
+		return
+	}
+
+	err := h.service.DeleteProduct(productoID)
+	if err != nil {
+		h.log.Errorf("Error deleting product: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error deleting product"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Product deleted successfully"})
+}
+
+// ListProducts lista todos los productos
+func (h *ProductHandler) ListProducts(c *gin.Context) {
+	productos, err := h.service.ListProducts()
+	if err != nil {
+		h.log.Errorf("Error listing products: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing products"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": productos})
+}
+
+// GetLowStockProducts lista los productos en o por debajo de su punto de reorden
+func (h *ProductHandler) GetLowStockProducts(c *gin.Context) {
+	productos, err := h.service.GetLowStockProducts()
+	if err != nil {
+		h.log.Errorf("Error getting low stock products: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error getting low stock products"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": productos})
+}
+
+// PreviewReorder muestra la cantidad que pediría hoy cada política de reposición
+func (h *ProductHandler) PreviewReorder(c *gin.Context) {
+	productoID := c.Param("id")
+	if productoID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Product ID is required"})
+		return
+	}
+
+	calculos, err := h.service.PreviewReorder(productoID)
+	if errors.Is(err, service.ErrProductNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	if err != nil {
+		h.log.Errorf("Error previewing reorder policies: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error previewing reorder policies"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": calculos})
+}
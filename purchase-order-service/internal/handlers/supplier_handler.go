@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"mediplus/purchase-order-service/internal/clients"
+	"mediplus/purchase-order-service/internal/events"
+	"mediplus/purchase-order-service/internal/models"
+	"mediplus/purchase-order-service/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// SupplierHandler expone la proyección local de proveedores y la mantiene con los eventos de
+// supplier-service
+type SupplierHandler struct {
+	service service.SupplierProjectionService
+	log     *logrus.Logger
+}
+
+// NewSupplierHandler crea una nueva instancia de SupplierHandler
+func NewSupplierHandler(service service.SupplierProjectionService, log *logrus.Logger) *SupplierHandler {
+	return &SupplierHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// GetSupplier obtiene la proyección local de un proveedor
+func (h *SupplierHandler) GetSupplier(c *gin.Context) {
+	proveedorID := c.Param("id")
+	if proveedorID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Supplier ID is required"})
+		return
+	}
+
+	proveedor, err := h.service.GetSupplier(proveedorID)
+	if errors.Is(err, service.ErrSupplierProjectionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Supplier not found"})
+		return
+	}
+	if err != nil {
+		h.log.Errorf("Error getting supplier projection: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error getting supplier"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": proveedor})
+}
+
+// ListSuppliers lista la proyección local de todos los proveedores
+func (h *SupplierHandler) ListSuppliers(c *gin.Context) {
+	proveedores, err := h.service.ListSuppliers()
+	if err != nil {
+		h.log.Errorf("Error listing supplier projection: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing suppliers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": proveedores})
+}
+
+// RebuildProjection vuelve a cargar la proyección desde supplier-service
+func (h *SupplierHandler) RebuildProjection(c *gin.Context) {
+	resultado, err := h.service.Rebuild()
+	if errors.Is(err, clients.ErrCircuitOpen) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Supplier service unavailable"})
+		return
+	}
+	if err != nil {
+		h.log.Errorf("Error rebuilding supplier projection: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error rebuilding supplier projection"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Supplier projection rebuilt successfully",
+		"data":    resultado,
+	})
+}
+
+// HandleProveedorEvent aplica a la proyección los eventos de proveedores
+func (h *SupplierHandler) HandleProveedorEvent(eventData []byte) error {
+	var baseEvent struct {
+		EventType string `json:"event_type"`
+	}
+	if err := json.Unmarshal(eventData, &baseEvent); err != nil {
+		h.log.Errorf("Error unmarshaling supplier event: %v", err)
+		return err
+	}
+
+	switch baseEvent.EventType {
+	case events.EventTypeProveedorCalificado, events.EventTypeProveedorActivado:
+		return h.handleProveedorActivado(eventData)
+	case events.EventTypeProveedorActualizado:
+		return h.handleProveedorActualizado(eventData)
+	case events.EventTypeProveedorSuspendido:
+		return h.handleProveedorSuspendido(eventData)
+	case events.EventTypeEvaluacionActualizada:
+		return h.handleEvaluacionActualizada(eventData)
+	default:
+		// El exchange de proveedores también transporta solicitudes y avisos que no cambian la proyección
+		h.log.Debugf("Ignoring %s event on supplier projection handler", baseEvent.EventType)
+		return nil
+	}
+}
+
+// handleProveedorActivado registra un proveedor calificado o reactivado
+func (h *SupplierHandler) handleProveedorActivado(eventData []byte) error {
+	var event events.ProveedorCalificadoEvent
+	if err := json.Unmarshal(eventData, &event); err != nil {
+		h.log.Errorf("Error unmarshaling ProveedorCalificado event: %v", err)
+		return err
+	}
+
+	if err := h.service.ApplySupplierActivated(event.EventID, event.Timestamp, proyeccionDesdeEvento(&event)); err != nil {
+		h.log.Errorf("Error applying %s event to supplier projection: %v", event.EventType, err)
+		return err
+	}
+
+	h.log.WithFields(logrus.Fields{
+		"event_id":     event.EventID,
+		"event_type":   event.EventType,
+		"proveedor_id": event.ProveedorID,
+	}).Info("Supplier projection updated")
+
+	return nil
+}
+
+// handleProveedorActualizado aplica los datos y el catálogo de un proveedor modificado
+func (h *SupplierHandler) handleProveedorActualizado(eventData []byte) error {
+	var event events.ProveedorCalificadoEvent
+	if err := json.Unmarshal(eventData, &event); err != nil {
+		h.log.Errorf("Error unmarshaling ProveedorActualizado event: %v", err)
+		return err
+	}
+
+	if err := h.service.ApplySupplierUpdated(event.EventID, event.Timestamp, proyeccionDesdeEvento(&event)); err != nil {
+		h.log.Errorf("Error applying ProveedorActualizado event to supplier projection: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// proyeccionDesdeEvento arma los datos de la proyección que trae un evento de proveedor
+func proyeccionDesdeEvento(event *events.ProveedorCalificadoEvent) *models.ProveedorProyectado {
+	proveedor := &models.ProveedorProyectado{
+		ProveedorID:         event.ProveedorID,
+		NombreLegal:         event.Data.NombreLegal,
+		ScoreGeneral:        event.Data.ScoreGeneral,
+		Certificaciones:     event.Data.Certificaciones,
+		CapacidadCadenaFrio: event.Data.CapacidadCadenaFrio,
+		TemperaturaMinima:   event.Data.TemperaturaMinima,
+		TemperaturaMaxima:   event.Data.TemperaturaMaxima,
+	}
+	for _, oferta := range event.Data.ProductosOfrecidos {
+		proveedor.ProductosOfrecidos = append(proveedor.ProductosOfrecidos, models.ProductoOfrecido{
+			ProductoID:           oferta.ProductoID,
+			PrecioBase:           oferta.PrecioBase,
+			Moneda:               oferta.Moneda,
+			EstadoDisponibilidad: oferta.EstadoDisponibilidad,
+			PrecioContratado:     oferta.PrecioContratado,
+			ContratoVigenteHasta: oferta.ContratoVigenteHasta,
+		})
+	}
+	return proveedor
+}
+
+// handleProveedorSuspendido marca un proveedor como suspendido
+func (h *SupplierHandler) handleProveedorSuspendido(eventData []byte) error {
+	var event events.ProveedorSuspendidoEvent
+	if err := json.Unmarshal(eventData, &event); err != nil {
+		h.log.Errorf("Error unmarshaling ProveedorSuspendido event: %v", err)
+		return err
+	}
+
+	if err := h.service.ApplySupplierSuspended(event.ProveedorID, event.EventID, event.Timestamp); err != nil {
+		h.log.Errorf("Error applying ProveedorSuspendido event to supplier projection: %v", err)
+		return err
+	}
+
+	h.log.WithFields(logrus.Fields{
+		"event_id":     event.EventID,
+		"proveedor_id": event.ProveedorID,
+		"motivo":       event.Data.MotivoSuspension,
+	}).Info("Supplier suspended in projection")
+
+	return nil
+}
+
+// handleEvaluacionActualizada actualiza el score de un proveedor
+func (h *SupplierHandler) handleEvaluacionActualizada(eventData []byte) error {
+	var event events.EvaluacionActualizadaEvent
+	if err := json.Unmarshal(eventData, &event); err != nil {
+		h.log.Errorf("Error unmarshaling EvaluacionActualizada event: %v", err)
+		return err
+	}
+
+	if err := h.service.ApplyEvaluationUpdated(event.ProveedorID, event.EventID, event.Timestamp, event.Data.ScoreNuevo); err != nil {
+		h.log.Errorf("Error applying EvaluacionActualizada event to supplier projection: %v", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"mediplus/purchase-order-service/internal/events"
+	"mediplus/purchase-order-service/internal/models"
+	"mediplus/purchase-order-service/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// TelemetryHandler recibe lecturas de temperatura por HTTP y por el event bus
+type TelemetryHandler struct {
+	service service.TelemetryService
+	log     *logrus.Logger
+}
+
+// NewTelemetryHandler crea una nueva instancia de TelemetryHandler
+func NewTelemetryHandler(service service.TelemetryService, log *logrus.Logger) *TelemetryHandler {
+	return &TelemetryHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// IngestReadingsRequest representa un lote de lecturas enviado por una unidad de almacenamiento
+type IngestReadingsRequest struct {
+	Lecturas []models.LecturaTemperatura `json:"lecturas" binding:"required,min=1"`
+}
+
+// ResultadoLecturaResponse indica cómo se evaluó cada lectura
+type ResultadoLecturaResponse struct {
+	UnidadAlmacenamiento string                  `json:"unidad_almacenamiento"`
+	ProductoID           string                  `json:"producto_id"`
+	NumeroLote           string                  `json:"numero_lote"`
+	Resultado            models.ResultadoLectura `json:"resultado,omitempty"`
+	Error                string                  `json:"error,omitempty"`
+}
+
+// IngestReadings evalúa las lecturas recibidas en el orden en que llegan
+func (h *TelemetryHandler) IngestReadings(c *gin.Context) {
+	var req IngestReadingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Errorf("Error binding request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resultados := make([]ResultadoLecturaResponse, 0, len(req.Lecturas))
+	for i := range req.Lecturas {
+		lectura := &req.Lecturas[i]
+		resultado := ResultadoLecturaResponse{
+			UnidadAlmacenamiento: lectura.UnidadAlmacenamiento,
+			ProductoID:           lectura.ProductoID,
+			NumeroLote:           lectura.NumeroLote,
+		}
+
+		evaluacion, err := h.service.IngestReading(lectura)
+		switch {
+		case errors.Is(err, models.ErrLecturaInvalida):
+			resultado.Error = err.Error()
+		case err != nil:
+			h.log.Errorf("Error ingesting temperature reading: %v", err)
+			resultado.Error = "Error ingesting temperature reading"
+		default:
+			resultado.Resultado = evaluacion
+		}
+
+		resultados = append(resultados, resultado)
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"data": resultados})
+}
+
+// ListExcursions lista las excursiones de temperatura abiertas y comprometidas de un producto
+func (h *TelemetryHandler) ListExcursions(c *gin.Context) {
+	productoID := c.Param("id")
+	if productoID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Product ID is required"})
+		return
+	}
+
+	excursiones, err := h.service.ListExcursions(productoID)
+	if errors.Is(err, service.ErrProductNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	if err != nil {
+		h.log.Errorf("Error listing temperature excursions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing temperature excursions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": excursiones})
+}
+
+// HandleLecturaTemperaturaEvent maneja lecturas de temperatura publicadas por los sensores
+func (h *TelemetryHandler) HandleLecturaTemperaturaEvent(eventData []byte) error {
+	var lecturaEvent events.LecturaTemperaturaEvent
+	if err := json.Unmarshal(eventData, &lecturaEvent); err != nil {
+		h.log.Errorf("Error unmarshaling LecturaTemperatura event: %v", err)
+		return err
+	}
+
+	if lecturaEvent.EventType != events.EventTypeLecturaTemperatura {
+		h.log.Debugf("Ignoring %s event on LecturaTemperatura handler", lecturaEvent.EventType)
+		return nil
+	}
+
+	lectura := &models.LecturaTemperatura{
+		UnidadAlmacenamiento: lecturaEvent.Data.UnidadAlmacenamiento,
+		SensorID:             lecturaEvent.Data.SensorID,
+		ProductoID:           lecturaEvent.Data.ProductoID,
+		NumeroLote:           lecturaEvent.Data.NumeroLote,
+		Temperatura:          lecturaEvent.Data.Temperatura,
+		FechaLectura:         lecturaEvent.Data.FechaLectura,
+	}
+
+	resultado, err := h.service.IngestReading(lectura)
+	if errors.Is(err, models.ErrLecturaInvalida) {
+		// Una lectura incompleta no mejora al reintentarla
+		h.log.Warnf("Discarding invalid temperature reading %s: %v", lecturaEvent.EventID, err)
+		return nil
+	}
+	if err != nil {
+		h.log.Errorf("Error processing temperature reading: %v", err)
+		return err
+	}
+
+	h.log.WithFields(logrus.Fields{
+		"event_id":              lecturaEvent.EventID,
+		"unidad_almacenamiento": lectura.UnidadAlmacenamiento,
+		"producto_id":           lectura.ProductoID,
+		"numero_lote":           lectura.NumeroLote,
+		"temperatura":           lectura.Temperatura,
+		"resultado":             resultado,
+	}).Debug("Processed LecturaTemperatura event")
+
+	return nil
+}
@@ -0,0 +1,444 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrPoliticaAprobacionInvalida se retorna cuando la política de aprobación no es consistente
+var ErrPoliticaAprobacionInvalida = errors.New("política de aprobación inválida")
+
+// ErrRolInsuficiente se retorna cuando el rol de quien decide no alcanza el nivel pendiente
+var ErrRolInsuficiente = errors.New("el rol no puede decidir el nivel de aprobación pendiente")
+
+// ErrAprobadorRepetido se retorna cuando un usuario intenta aprobar más de un nivel de la misma orden
+var ErrAprobadorRepetido = errors.New("el usuario ya aprobó otro nivel de la orden")
+
+// EstadoNivelAprobacion representa la decisión sobre un nivel de aprobación
+type EstadoNivelAprobacion string
+
+const (
+	NivelPendiente EstadoNivelAprobacion = "PENDIENTE"
+	NivelAprobado  EstadoNivelAprobacion = "APROBADO"
+	NivelRechazado EstadoNivelAprobacion = "RECHAZADO"
+)
+
+// ReglaAprobacion exige los roles indicados a las órdenes que cumplen todas sus condiciones.
+// Las condiciones vacías no se evalúan.
+type ReglaAprobacion struct {
+	Nombre                string      `json:"nombre"`
+	MontoMinimo           Monto       `json:"monto_minimo,omitempty"`
+	Prioridades           []Prioridad `json:"prioridades,omitempty"`
+	Categorias            []string    `json:"categorias,omitempty"`
+	ScoreProveedorMenorA  float64     `json:"score_proveedor_menor_a,omitempty"`
+	PrecioFueraTolerancia bool        `json:"precio_fuera_tolerancia,omitempty"`
+	Roles                 []string    `json:"roles"`
+}
+
+// ReglaExpedita reemplaza la cadena de aprobación de las órdenes CRITICA hasta un monto máximo
+// (0 sin límite), con un plazo de escalamiento propio
+type ReglaExpedita struct {
+	Roles                    []string `json:"roles"`
+	MontoMaximo              Monto    `json:"monto_maximo,omitempty"`
+	PlazoEscalamientoMinutos int      `json:"plazo_escalamiento_minutos"`
+}
+
+// PoliticaAprobacion define la jerarquía de roles (de menor a mayor), las reglas que exigen
+// aprobación y cuánto puede esperar un nivel antes de escalar al rol superior
+type PoliticaAprobacion struct {
+	Roles                    []string          `json:"roles"`
+	Reglas                   []ReglaAprobacion `json:"reglas"`
+	Expedita                 *ReglaExpedita    `json:"expedita,omitempty"`
+	PlazoEscalamientoMinutos int               `json:"plazo_escalamiento_minutos"`
+}
+
+// ContextoAprobacion reúne los datos de una orden que evalúan las reglas
+type ContextoAprobacion struct {
+	MontoTotal     Monto
+	Prioridad      Prioridad
+	Categorias     []string
+	ScoreProveedor *float64
+
+	// PrecioFueraTolerancia indica si algún item tiene un precio manual fuera de la tolerancia
+	PrecioFueraTolerancia bool
+}
+
+// NivelAprobacion es un paso de la cadena de aprobación de una orden. RolRequerido es el que
+// exigió la política; RolAsignado cambia cuando el nivel se escala.
+type NivelAprobacion struct {
+	RolRequerido  string                `json:"rol_requerido" dynamodbav:"rol_requerido"`
+	RolAsignado   string                `json:"rol_asignado" dynamodbav:"rol_asignado"`
+	Estado        EstadoNivelAprobacion `json:"estado" dynamodbav:"estado"`
+	Vence         *time.Time            `json:"vence,omitempty" dynamodbav:"vence,omitempty"`
+	Escalamientos int                   `json:"escalamientos,omitempty" dynamodbav:"escalamientos,omitempty"`
+	UsuarioID     string                `json:"usuario_id,omitempty" dynamodbav:"usuario_id,omitempty"`
+	RolDecisor    string                `json:"rol_decisor,omitempty" dynamodbav:"rol_decisor,omitempty"`
+	Comentario    string                `json:"comentario,omitempty" dynamodbav:"comentario,omitempty"`
+	FechaDecision *time.Time            `json:"fecha_decision,omitempty" dynamodbav:"fecha_decision,omitempty"`
+}
+
+// AprobacionOrden es la cadena de aprobación que la política exigió a una orden
+type AprobacionOrden struct {
+	Reglas                   []string          `json:"reglas" dynamodbav:"reglas"`
+	Expedita                 bool              `json:"expedita" dynamodbav:"expedita"`
+	MontoTotal               Monto             `json:"monto_total" dynamodbav:"monto_total"`
+	PlazoEscalamientoMinutos int               `json:"plazo_escalamiento_minutos" dynamodbav:"plazo_escalamiento_minutos"`
+	Niveles                  []NivelAprobacion `json:"niveles" dynamodbav:"niveles"`
+	FechaSolicitud           time.Time         `json:"fecha_solicitud" dynamodbav:"fecha_solicitud"`
+	FechaResolucion          *time.Time        `json:"fecha_resolucion,omitempty" dynamodbav:"fecha_resolucion,omitempty"`
+}
+
+// PoliticaAprobacionPorDefecto es la política que se usa cuando no se configura un archivo
+func PoliticaAprobacionPorDefecto() *PoliticaAprobacion {
+	return &PoliticaAprobacion{
+		Roles: []string{"SUPERVISOR_COMPRAS", "JEFE_COMPRAS", "DIRECTOR_FINANCIERO"},
+		Reglas: []ReglaAprobacion{
+			{Nombre: "monto-medio", MontoMinimo: MontoDesdeFloat(10000), Roles: []string{"JEFE_COMPRAS"}},
+			{Nombre: "monto-alto", MontoMinimo: MontoDesdeFloat(50000), Roles: []string{"JEFE_COMPRAS", "DIRECTOR_FINANCIERO"}},
+			{Nombre: "medicamentos-controlados", Categorias: []string{"CONTROLADO"}, Roles: []string{"JEFE_COMPRAS"}},
+			{Nombre: "proveedor-riesgo", ScoreProveedorMenorA: 60, Roles: []string{"JEFE_COMPRAS"}},
+			{Nombre: "precio-fuera-tolerancia", PrecioFueraTolerancia: true, Roles: []string{"JEFE_COMPRAS"}},
+		},
+		Expedita: &ReglaExpedita{
+			Roles:                    []string{"SUPERVISOR_COMPRAS"},
+			MontoMaximo:              MontoDesdeFloat(100000),
+			PlazoEscalamientoMinutos: 60,
+		},
+		PlazoEscalamientoMinutos: 24 * 60,
+	}
+}
+
+// Validar verifica que los roles de las reglas existan en la jerarquía y que cada regla tenga
+// al menos una condición
+func (p *PoliticaAprobacion) Validar() error {
+	if len(p.Roles) == 0 {
+		return fmt.Errorf("%w: la jerarquía de roles está vacía", ErrPoliticaAprobacionInvalida)
+	}
+	if p.PlazoEscalamientoMinutos <= 0 {
+		return fmt.Errorf("%w: plazo_escalamiento_minutos debe ser mayor a cero", ErrPoliticaAprobacionInvalida)
+	}
+	for _, regla := range p.Reglas {
+		if regla.MontoMinimo <= 0 && len(regla.Prioridades) == 0 && len(regla.Categorias) == 0 &&
+			regla.ScoreProveedorMenorA <= 0 && !regla.PrecioFueraTolerancia {
+			return fmt.Errorf("%w: la regla %q no tiene condiciones", ErrPoliticaAprobacionInvalida, regla.Nombre)
+		}
+		if len(regla.Roles) == 0 {
+			return fmt.Errorf("%w: la regla %q no exige roles", ErrPoliticaAprobacionInvalida, regla.Nombre)
+		}
+		if err := p.validarRoles(regla.Roles); err != nil {
+			return fmt.Errorf("%w: regla %q: %v", ErrPoliticaAprobacionInvalida, regla.Nombre, err)
+		}
+	}
+	if p.Expedita != nil {
+		if p.Expedita.PlazoEscalamientoMinutos <= 0 {
+			return fmt.Errorf("%w: la regla expedita requiere plazo_escalamiento_minutos mayor a cero", ErrPoliticaAprobacionInvalida)
+		}
+		if err := p.validarRoles(p.Expedita.Roles); err != nil {
+			return fmt.Errorf("%w: regla expedita: %v", ErrPoliticaAprobacionInvalida, err)
+		}
+	}
+	return nil
+}
+
+// validarRoles verifica que todos los roles estén en la jerarquía
+func (p *PoliticaAprobacion) validarRoles(roles []string) error {
+	for _, rol := range roles {
+		if p.rango(rol) < 0 {
+			return fmt.Errorf("rol %q desconocido", rol)
+		}
+	}
+	return nil
+}
+
+// Evaluar aplica las reglas a una orden y retorna la cadena de aprobación, o nil si la orden no
+// requiere aprobación. Los roles de todas las reglas cumplidas se piden una vez, del menor al mayor.
+// Las órdenes CRITICA dentro del monto de la regla expedita usan solo los roles de esa regla.
+func (p *PoliticaAprobacion) Evaluar(contexto ContextoAprobacion, ahora time.Time) *AprobacionOrden {
+	reglas := []string{}
+	requeridos := map[string]bool{}
+	for _, regla := range p.Reglas {
+		if !regla.cumple(contexto) {
+			continue
+		}
+		reglas = append(reglas, regla.Nombre)
+		for _, rol := range regla.Roles {
+			requeridos[strings.ToUpper(rol)] = true
+		}
+	}
+	if len(reglas) == 0 {
+		return nil
+	}
+
+	aprobacion := &AprobacionOrden{
+		Reglas:                   reglas,
+		MontoTotal:               contexto.MontoTotal,
+		PlazoEscalamientoMinutos: p.PlazoEscalamientoMinutos,
+		FechaSolicitud:           ahora,
+	}
+
+	if p.Expedita != nil && contexto.Prioridad == PrioridadCritica &&
+		(p.Expedita.MontoMaximo <= 0 || contexto.MontoTotal <= p.Expedita.MontoMaximo) {
+		aprobacion.Expedita = true
+		aprobacion.PlazoEscalamientoMinutos = p.Expedita.PlazoEscalamientoMinutos
+		requeridos = map[string]bool{}
+		for _, rol := range p.Expedita.Roles {
+			requeridos[strings.ToUpper(rol)] = true
+		}
+	}
+
+	// Recorrer la jerarquía deja los niveles ordenados del rol menor al mayor
+	for _, rol := range p.Roles {
+		if requeridos[strings.ToUpper(rol)] {
+			aprobacion.Niveles = append(aprobacion.Niveles, NivelAprobacion{
+				RolRequerido: rol,
+				RolAsignado:  rol,
+				Estado:       NivelPendiente,
+			})
+		}
+	}
+	if len(aprobacion.Niveles) == 0 {
+		return nil
+	}
+
+	return aprobacion
+}
+
+// PuedeDecidir indica si el rol alcanza al rol asignado de un nivel
+func (p *PoliticaAprobacion) PuedeDecidir(rol, rolAsignado string) bool {
+	rango := p.rango(rol)
+	return rango >= 0 && rango >= p.rango(rolAsignado)
+}
+
+// RolSuperior retorna el rol inmediatamente superior en la jerarquía, o "" si no lo hay
+func (p *PoliticaAprobacion) RolSuperior(rol string) string {
+	rango := p.rango(rol)
+	if rango < 0 || rango+1 >= len(p.Roles) {
+		return ""
+	}
+	return p.Roles[rango+1]
+}
+
+// rango retorna la posición del rol en la jerarquía, o -1 si no existe
+func (p *PoliticaAprobacion) rango(rol string) int {
+	for i, r := range p.Roles {
+		if strings.EqualFold(r, rol) {
+			return i
+		}
+	}
+	return -1
+}
+
+// cumple indica si el contexto cumple todas las condiciones de la regla
+func (r *ReglaAprobacion) cumple(contexto ContextoAprobacion) bool {
+	if r.MontoMinimo > 0 && contexto.MontoTotal < r.MontoMinimo {
+		return false
+	}
+	if len(r.Prioridades) > 0 && !contienePrioridad(r.Prioridades, contexto.Prioridad) {
+		return false
+	}
+	if len(r.Categorias) > 0 && !intersectan(r.Categorias, contexto.Categorias) {
+		return false
+	}
+	if r.ScoreProveedorMenorA > 0 && (contexto.ScoreProveedor == nil || *contexto.ScoreProveedor >= r.ScoreProveedorMenorA) {
+		return false
+	}
+	if r.PrecioFueraTolerancia && !contexto.PrecioFueraTolerancia {
+		return false
+	}
+	return true
+}
+
+// NivelActual retorna el primer nivel pendiente, o nil si no queda ninguno
+func (a *AprobacionOrden) NivelActual() *NivelAprobacion {
+	for i := range a.Niveles {
+		if a.Niveles[i].Estado == NivelPendiente {
+			return &a.Niveles[i]
+		}
+	}
+	return nil
+}
+
+// Cubre indica si esta aprobación, ya otorgada, alcanza para la cadena que exige otra
+// evaluación: el monto no aumentó y ya se aprobaron todos los roles que se piden
+func (a *AprobacionOrden) Cubre(otra *AprobacionOrden) bool {
+	if a.FechaResolucion == nil || a.NivelActual() != nil || otra.MontoTotal > a.MontoTotal {
+		return false
+	}
+	aprobados := map[string]bool{}
+	for _, nivel := range a.Niveles {
+		aprobados[nivel.RolRequerido] = true
+	}
+	for _, nivel := range otra.Niveles {
+		if !aprobados[nivel.RolRequerido] {
+			return false
+		}
+	}
+	return true
+}
+
+// plazo retorna cuánto puede esperar un nivel antes de escalar
+func (a *AprobacionOrden) plazo() time.Duration {
+	return time.Duration(a.PlazoEscalamientoMinutos) * time.Minute
+}
+
+// SolicitarAprobacion deja la orden pendiente de la cadena indicada, con el primer nivel en curso
+func (o *OrdenCompra) SolicitarAprobacion(aprobacion *AprobacionOrden, ahora time.Time) error {
+	if o.EstadoOrden != EstadoPendienteAprobacion {
+		if err := o.transicionar(EstadoPendienteAprobacion); err != nil {
+			return err
+		}
+	}
+	vence := ahora.Add(aprobacion.plazo())
+	aprobacion.NivelActual().Vence = &vence
+	o.Aprobacion = aprobacion
+	return nil
+}
+
+// RequiereNuevaAprobacion indica si, tras editar la orden, debe pasar por la cadena nueva: una
+// orden pendiente cuando aumentó el monto o cambiaron los roles, y una orden generada cuando lo
+// ya aprobado no cubre lo que se exige ahora
+func (o *OrdenCompra) RequiereNuevaAprobacion(nueva *AprobacionOrden) bool {
+	if o.Aprobacion == nil {
+		return true
+	}
+	if o.EstadoOrden != EstadoPendienteAprobacion {
+		return !o.Aprobacion.Cubre(nueva)
+	}
+	if nueva.MontoTotal > o.Aprobacion.MontoTotal || len(nueva.Niveles) != len(o.Aprobacion.Niveles) {
+		return true
+	}
+	for i, nivel := range nueva.Niveles {
+		if nivel.RolRequerido != o.Aprobacion.Niveles[i].RolRequerido {
+			return true
+		}
+	}
+	return false
+}
+
+// RetirarAprobacion libera una orden pendiente que, tras editarse, ya no requiere aprobación
+func (o *OrdenCompra) RetirarAprobacion() error {
+	if err := o.transicionar(EstadoGenerada); err != nil {
+		return err
+	}
+	o.Aprobacion = nil
+	return nil
+}
+
+// Aprobar registra la aprobación del nivel en curso. Cuando se aprueba el último nivel la orden
+// pasa a GENERADA y retorna true.
+func (o *OrdenCompra) Aprobar(usuarioID, rol, comentario string, politica *PoliticaAprobacion, ahora time.Time) (bool, error) {
+	nivel, err := o.nivelADecidir(rol, politica)
+	if err != nil {
+		return false, err
+	}
+	for _, anterior := range o.Aprobacion.Niveles {
+		if anterior.Estado == NivelAprobado && anterior.UsuarioID == usuarioID {
+			return false, ErrAprobadorRepetido
+		}
+	}
+
+	nivel.decidir(NivelAprobado, usuarioID, rol, comentario, ahora)
+
+	if siguiente := o.Aprobacion.NivelActual(); siguiente != nil {
+		vence := ahora.Add(o.Aprobacion.plazo())
+		siguiente.Vence = &vence
+		o.UpdatedAt = ahora
+		return false, nil
+	}
+
+	if err := o.transicionar(EstadoGenerada); err != nil {
+		return false, err
+	}
+	o.Aprobacion.FechaResolucion = &ahora
+	return true, nil
+}
+
+// Rechazar registra el rechazo del nivel en curso y cierra la orden como RECHAZADA
+func (o *OrdenCompra) Rechazar(usuarioID, rol, comentario string, politica *PoliticaAprobacion, ahora time.Time) error {
+	nivel, err := o.nivelADecidir(rol, politica)
+	if err != nil {
+		return err
+	}
+
+	if err := o.transicionar(EstadoRechazada); err != nil {
+		return err
+	}
+	nivel.decidir(NivelRechazado, usuarioID, rol, comentario, ahora)
+	o.Aprobacion.FechaResolucion = &ahora
+	for i := range o.Items {
+		o.Items[i].EstadoItem = EstadoItemCancelado
+	}
+	return nil
+}
+
+// EscalarAprobacion asigna el nivel en curso al rol superior cuando venció su plazo. Si ya está
+// en el rol más alto solo renueva el plazo, para volver a avisar. Retorna el nivel escalado y el
+// rol que tenía, o nil si no correspondía escalar.
+func (o *OrdenCompra) EscalarAprobacion(politica *PoliticaAprobacion, ahora time.Time) (*NivelAprobacion, string) {
+	if o.EstadoOrden != EstadoPendienteAprobacion || o.Aprobacion == nil {
+		return nil, ""
+	}
+	nivel := o.Aprobacion.NivelActual()
+	if nivel == nil || nivel.Vence == nil || ahora.Before(*nivel.Vence) {
+		return nil, ""
+	}
+
+	rolAnterior := nivel.RolAsignado
+	if superior := politica.RolSuperior(nivel.RolAsignado); superior != "" {
+		nivel.RolAsignado = superior
+	}
+	nivel.Escalamientos++
+	vence := ahora.Add(o.Aprobacion.plazo())
+	nivel.Vence = &vence
+	o.UpdatedAt = ahora
+	return nivel, rolAnterior
+}
+
+// nivelADecidir verifica que la orden espere aprobación y que el rol alcance el nivel en curso
+func (o *OrdenCompra) nivelADecidir(rol string, politica *PoliticaAprobacion) (*NivelAprobacion, error) {
+	if o.EstadoOrden != EstadoPendienteAprobacion || o.Aprobacion == nil {
+		return nil, fmt.Errorf("%w: la orden está %s y no espera aprobación", ErrTransicionInvalida, o.EstadoOrden)
+	}
+	nivel := o.Aprobacion.NivelActual()
+	if nivel == nil {
+		return nil, fmt.Errorf("%w: la orden no tiene niveles pendientes", ErrTransicionInvalida)
+	}
+	if !politica.PuedeDecidir(rol, nivel.RolAsignado) {
+		return nil, fmt.Errorf("%w: se requiere %s o superior", ErrRolInsuficiente, nivel.RolAsignado)
+	}
+	return nivel, nil
+}
+
+// decidir registra la decisión sobre el nivel
+func (n *NivelAprobacion) decidir(estado EstadoNivelAprobacion, usuarioID, rol, comentario string, ahora time.Time) {
+	n.Estado = estado
+	n.UsuarioID = usuarioID
+	n.RolDecisor = rol
+	n.Comentario = comentario
+	n.FechaDecision = &ahora
+	n.Vence = nil
+}
+
+// contienePrioridad indica si la prioridad está en la lista
+func contienePrioridad(prioridades []Prioridad, prioridad Prioridad) bool {
+	for _, p := range prioridades {
+		if p == prioridad {
+			return true
+		}
+	}
+	return false
+}
+
+// intersectan indica si las listas comparten algún valor, sin distinguir mayúsculas
+func intersectan(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if strings.EqualFold(x, y) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// ErrImporteInvalido se retorna cuando un precio, descuento o monto no es válido
+var ErrImporteInvalido = errors.New("importe inválido")
+
+// escalaMonto es la cantidad de centésimos por unidad de moneda
+const escalaMonto = 100
+
+// Monto es una cantidad de dinero con dos decimales fijos. Se guarda en centésimos para que
+// sumas y multiplicaciones no acumulen los errores de redondeo de float64; en JSON y en
+// DynamoDB se representa como número decimal (por ejemplo 1234.50).
+type Monto int64
+
+// MontoDesdeFloat convierte un valor decimal a Monto redondeando al centésimo
+func MontoDesdeFloat(valor float64) Monto {
+	return Monto(math.Round(valor * escalaMonto))
+}
+
+// ParseMonto interpreta un número decimal de forma exacta y lo redondea al centésimo (la mitad
+// se aleja de cero)
+func ParseMonto(texto string) (Monto, error) {
+	valor, ok := new(big.Rat).SetString(texto)
+	if !ok {
+		return 0, fmt.Errorf("%w: %q no es un número", ErrImporteInvalido, texto)
+	}
+
+	valor.Mul(valor, big.NewRat(escalaMonto, 1))
+	cociente, resto := new(big.Int).QuoRem(valor.Num(), valor.Denom(), new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(resto), big.NewInt(2)).Cmp(valor.Denom()) >= 0 {
+		cociente.Add(cociente, big.NewInt(int64(valor.Sign())))
+	}
+	if !cociente.IsInt64() {
+		return 0, fmt.Errorf("%w: %q excede el rango permitido", ErrImporteInvalido, texto)
+	}
+
+	return Monto(cociente.Int64()), nil
+}
+
+// Float64 retorna el monto como float64, para cálculos que no son contables (porcentajes)
+func (m Monto) Float64() float64 {
+	return float64(m) / escalaMonto
+}
+
+// String retorna el monto con dos decimales
+func (m Monto) String() string {
+	signo := ""
+	centesimos := int64(m)
+	if centesimos < 0 {
+		signo = "-"
+		centesimos = -centesimos
+	}
+	return fmt.Sprintf("%s%d.%02d", signo, centesimos/escalaMonto, centesimos%escalaMonto)
+}
+
+// Por multiplica el monto por una cantidad de unidades
+func (m Monto) Por(cantidad int) Monto {
+	return m * Monto(cantidad)
+}
+
+// Proporcion retorna numerador/denominador del monto redondeado al centésimo
+func (m Monto) Proporcion(numerador, denominador int) Monto {
+	if denominador == 0 {
+		return 0
+	}
+	return Monto(dividirRedondeando(int64(m)*int64(numerador), int64(denominador)))
+}
+
+// AplicarTasa retorna el monto multiplicado por una tasa (por ejemplo 0.19) redondeado al centésimo
+func (m Monto) AplicarTasa(tasa float64) Monto {
+	return Monto(math.Round(float64(m) * tasa))
+}
+
+// MarshalJSON representa el monto como número decimal
+func (m Monto) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON acepta el monto como número o como texto
+func (m *Monto) UnmarshalJSON(data []byte) error {
+	texto := string(data)
+	if texto == "null" {
+		return nil
+	}
+	if sinComillas, err := strconv.Unquote(texto); err == nil {
+		texto = sinComillas
+	}
+
+	monto, err := ParseMonto(texto)
+	if err != nil {
+		return err
+	}
+	*m = monto
+	return nil
+}
+
+// MarshalDynamoDBAttributeValue guarda el monto como número decimal
+func (m Monto) MarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue) error {
+	av.N = aws.String(m.String())
+	return nil
+}
+
+// UnmarshalDynamoDBAttributeValue lee el monto desde un número de DynamoDB
+func (m *Monto) UnmarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue) error {
+	var texto string
+	switch {
+	case av.N != nil:
+		texto = *av.N
+	case av.S != nil:
+		texto = *av.S
+	default:
+		*m = 0
+		return nil
+	}
+
+	monto, err := ParseMonto(texto)
+	if err != nil {
+		return err
+	}
+	*m = monto
+	return nil
+}
+
+// dividirRedondeando divide enteros redondeando la mitad lejos de cero
+func dividirRedondeando(dividendo, divisor int64) int64 {
+	if divisor < 0 {
+		dividendo, divisor = -dividendo, -divisor
+	}
+	if dividendo < 0 {
+		return -((-dividendo*2 + divisor) / (divisor * 2))
+	}
+	return (dividendo*2 + divisor) / (divisor * 2)
+}
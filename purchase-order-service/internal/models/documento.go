@@ -0,0 +1,46 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrFormatoDocumentoInvalido se retorna cuando se pide el documento en un formato no soportado
+var ErrFormatoDocumentoInvalido = errors.New("formato de documento inválido")
+
+// FormatoDocumento es el formato en que se genera el documento de una orden
+type FormatoDocumento string
+
+const (
+	FormatoDocumentoHTML FormatoDocumento = "html"
+	FormatoDocumentoPDF  FormatoDocumento = "pdf"
+)
+
+// ParseFormatoDocumento valida el formato pedido; vacío equivale a HTML
+func ParseFormatoDocumento(valor string) (FormatoDocumento, error) {
+	switch FormatoDocumento(strings.ToLower(valor)) {
+	case "", FormatoDocumentoHTML:
+		return FormatoDocumentoHTML, nil
+	case FormatoDocumentoPDF:
+		return FormatoDocumentoPDF, nil
+	}
+	return "", ErrFormatoDocumentoInvalido
+}
+
+// DocumentoOrden es la copia del documento de la orden que se envió al proveedor: su hash de
+// verificación y dónde quedaron archivadas las versiones HTML y PDF
+type DocumentoOrden struct {
+	Hash         string    `json:"hash" dynamodbav:"hash"`
+	ClaveHTML    string    `json:"clave_html" dynamodbav:"clave_html"`
+	ClavePDF     string    `json:"clave_pdf" dynamodbav:"clave_pdf"`
+	FechaEmision time.Time `json:"fecha_emision" dynamodbav:"fecha_emision"`
+}
+
+// Clave retorna dónde está archivada la copia en el formato indicado
+func (d *DocumentoOrden) Clave(formato FormatoDocumento) string {
+	if formato == FormatoDocumentoPDF {
+		return d.ClavePDF
+	}
+	return d.ClaveHTML
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrPoliticaImpuestosInvalida se retorna cuando las tasas de la política de impuestos no son válidas
+var ErrPoliticaImpuestosInvalida = errors.New("política de impuestos inválida")
+
+// PoliticaImpuestos define el IVA que se aplica a los items según la categoría del producto.
+// Las categorías exentas (por ejemplo, medicamentos) no pagan IVA y las de tasa reducida usan
+// su propia tasa; el resto paga la tasa general.
+type PoliticaImpuestos struct {
+	TasaGeneral       float64            `json:"tasa_general"`
+	TasasReducidas    map[string]float64 `json:"tasas_reducidas,omitempty"`
+	CategoriasExentas []string           `json:"categorias_exentas,omitempty"`
+}
+
+// PoliticaImpuestosPorDefecto retorna la política que se usa si no se configura un archivo
+func PoliticaImpuestosPorDefecto() *PoliticaImpuestos {
+	return &PoliticaImpuestos{
+		TasaGeneral:       0.19,
+		CategoriasExentas: []string{"MEDICAMENTO", "CONTROLADO", "VACUNA"},
+	}
+}
+
+// Validar verifica que las tasas estén entre 0 y 1
+func (p *PoliticaImpuestos) Validar() error {
+	if p.TasaGeneral < 0 || p.TasaGeneral >= 1 {
+		return fmt.Errorf("%w: la tasa general debe estar entre 0 y 1", ErrPoliticaImpuestosInvalida)
+	}
+	for categoria, tasa := range p.TasasReducidas {
+		if tasa < 0 || tasa >= 1 {
+			return fmt.Errorf("%w: la tasa de %s debe estar entre 0 y 1", ErrPoliticaImpuestosInvalida, categoria)
+		}
+	}
+	return nil
+}
+
+// Tasa retorna la tasa de IVA de una categoría y si está exenta
+func (p *PoliticaImpuestos) Tasa(categoria string) (float64, bool) {
+	categoria = strings.ToUpper(categoria)
+	for _, exenta := range p.CategoriasExentas {
+		if strings.ToUpper(exenta) == categoria {
+			return 0, true
+		}
+	}
+	for reducida, tasa := range p.TasasReducidas {
+		if strings.ToUpper(reducida) == categoria {
+			return tasa, false
+		}
+	}
+	return p.TasaGeneral, false
+}
+
+// TotalesOrden son los importes de la orden, sumados de sus items, en la moneda de la orden
+type TotalesOrden struct {
+	Moneda        string `json:"moneda" dynamodbav:"moneda"`
+	Subtotal      Monto  `json:"subtotal" dynamodbav:"subtotal"`
+	Descuento     Monto  `json:"descuento" dynamodbav:"descuento"`
+	BaseImponible Monto  `json:"base_imponible" dynamodbav:"base_imponible"`
+	Impuesto      Monto  `json:"impuesto" dynamodbav:"impuesto"`
+	Total         Monto  `json:"total" dynamodbav:"total"`
+}
+
+// CalcularImportes calcula el subtotal, el descuento, el IVA y el total del item con la tasa indicada
+func (i *ItemOrdenCompra) CalcularImportes(tasa float64, exento bool) error {
+	if i.CantidadSolicitada <= 0 {
+		return fmt.Errorf("%w: la cantidad solicitada del producto %s debe ser mayor a 0", ErrImporteInvalido, i.ProductoID)
+	}
+	if i.PrecioUnitario < 0 {
+		return fmt.Errorf("%w: el precio unitario del producto %s no puede ser negativo", ErrImporteInvalido, i.ProductoID)
+	}
+	if i.DescuentoPorcentaje < 0 || i.DescuentoPorcentaje > 100 {
+		return fmt.Errorf("%w: el descuento del producto %s debe estar entre 0 y 100", ErrImporteInvalido, i.ProductoID)
+	}
+
+	i.Subtotal = i.PrecioUnitario.Por(i.CantidadSolicitada)
+	i.Descuento = i.Subtotal.AplicarTasa(i.DescuentoPorcentaje / 100)
+	i.TasaImpuesto = tasa
+	i.ExentoImpuesto = exento
+	i.Impuesto = (i.Subtotal - i.Descuento).AplicarTasa(tasa)
+	i.Total = i.Subtotal - i.Descuento + i.Impuesto
+	return nil
+}
+
+// ValorCantidad valoriza una cantidad del item a su total por unidad (descuento e IVA incluidos)
+func (i *ItemOrdenCompra) ValorCantidad(cantidad int) Monto {
+	return i.Total.Proporcion(cantidad, i.CantidadSolicitada)
+}
+
+// CalcularTotales calcula los importes de cada item con la tasa de IVA de la categoría de su
+// producto y guarda los totales en la orden
+func (o *OrdenCompra) CalcularTotales(politica *PoliticaImpuestos, categorias map[string]string) error {
+	totales := TotalesOrden{Moneda: o.Moneda}
+	for i := range o.Items {
+		item := &o.Items[i]
+		tasa, exento := politica.Tasa(categorias[item.ProductoID])
+		if err := item.CalcularImportes(tasa, exento); err != nil {
+			return err
+		}
+
+		totales.Subtotal += item.Subtotal
+		totales.Descuento += item.Descuento
+		totales.Impuesto += item.Impuesto
+		totales.Total += item.Total
+	}
+	totales.BaseImponible = totales.Subtotal - totales.Descuento
+
+	o.Totales = totales
+	return nil
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// EstadoLote representa el estado de un lote de producto
+type EstadoLote string
+
+const (
+	EstadoLoteActivo  EstadoLote = "ACTIVO"
+	EstadoLoteVencido EstadoLote = "VENCIDO"
+	EstadoLoteDanado  EstadoLote = "DANADO"
+)
+
+// Lote representa un lote de un producto con su vencimiento y la cantidad disponible.
+// La suma de los lotes puede ser menor que StockActual: el stock anterior al seguimiento
+// por lotes o recibido sin número de lote queda como stock sin lote.
+type Lote struct {
+	ProductoID         string     `json:"producto_id" dynamodbav:"producto_id"`
+	NumeroLote         string     `json:"numero_lote" dynamodbav:"numero_lote"`
+	FechaVencimiento   *time.Time `json:"fecha_vencimiento,omitempty" dynamodbav:"fecha_vencimiento,omitempty"`
+	FechaRecepcion     time.Time  `json:"fecha_recepcion" dynamodbav:"fecha_recepcion"`
+	OrdenID            string     `json:"orden_id,omitempty" dynamodbav:"orden_id,omitempty"`
+	ProveedorID        string     `json:"proveedor_id,omitempty" dynamodbav:"proveedor_id,omitempty"`
+	CantidadInicial    int        `json:"cantidad_inicial" dynamodbav:"cantidad_inicial"`
+	CantidadDisponible int        `json:"cantidad_disponible" dynamodbav:"cantidad_disponible"`
+	Estado             EstadoLote `json:"estado" dynamodbav:"estado"`
+	CreatedAt          time.Time  `json:"created_at" dynamodbav:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at" dynamodbav:"updated_at"`
+}
+
+// MovimientoLote indica cuánto de un movimiento corresponde a un lote. La cantidad lleva el
+// mismo signo que el movimiento; los datos de origen solo se usan al crear el lote.
+type MovimientoLote struct {
+	NumeroLote       string     `json:"numero_lote" dynamodbav:"numero_lote"`
+	Cantidad         int        `json:"cantidad" dynamodbav:"cantidad"`
+	FechaVencimiento *time.Time `json:"fecha_vencimiento,omitempty" dynamodbav:"fecha_vencimiento,omitempty"`
+	OrdenID          string     `json:"orden_id,omitempty" dynamodbav:"orden_id,omitempty"`
+	ProveedorID      string     `json:"proveedor_id,omitempty" dynamodbav:"proveedor_id,omitempty"`
+}
+
+// Vencido indica si el lote ya pasó su fecha de vencimiento
+func (l *Lote) Vencido(ahora time.Time) bool {
+	return l.FechaVencimiento != nil && !ahora.Before(*l.FechaVencimiento)
+}
+
+// Consumible indica si el lote puede usarse para despachar
+func (l *Lote) Consumible(ahora time.Time) bool {
+	return l.Estado == EstadoLoteActivo && l.CantidadDisponible > 0 && !l.Vencido(ahora)
+}
+
+// PorVencer indica si el lote tiene existencias y vence antes del límite indicado
+func (l *Lote) PorVencer(ahora, limite time.Time) bool {
+	return l.Consumible(ahora) && l.FechaVencimiento != nil && l.FechaVencimiento.Before(limite)
+}
+
+// OrdenarFEFO ordena los lotes por vencimiento (primero el que vence antes); los lotes sin
+// vencimiento van al final y, a igual vencimiento, primero el recibido antes
+func OrdenarFEFO(lotes []*Lote) {
+	sort.SliceStable(lotes, func(i, j int) bool {
+		a, b := lotes[i].FechaVencimiento, lotes[j].FechaVencimiento
+		switch {
+		case a == nil && b == nil:
+			return lotes[i].FechaRecepcion.Before(lotes[j].FechaRecepcion)
+		case a == nil:
+			return false
+		case b == nil:
+			return true
+		case a.Equal(*b):
+			return lotes[i].FechaRecepcion.Before(lotes[j].FechaRecepcion)
+		default:
+			return a.Before(*b)
+		}
+	})
+}
+
+// AsignarFEFO reparte una salida entre los lotes consumibles, primero el que vence antes.
+// Retorna las asignaciones (con cantidad negativa) y lo que no se pudo cubrir con lotes.
+func AsignarFEFO(lotes []*Lote, cantidad int, ahora time.Time) ([]MovimientoLote, int) {
+	consumibles := []*Lote{}
+	for _, lote := range lotes {
+		if lote.Consumible(ahora) {
+			consumibles = append(consumibles, lote)
+		}
+	}
+	OrdenarFEFO(consumibles)
+
+	asignaciones := []MovimientoLote{}
+	restante := cantidad
+	for _, lote := range consumibles {
+		if restante == 0 {
+			break
+		}
+		tomar := min(restante, lote.CantidadDisponible)
+		asignaciones = append(asignaciones, MovimientoLote{
+			NumeroLote: lote.NumeroLote,
+			Cantidad:   -tomar,
+		})
+		restante -= tomar
+	}
+
+	return asignaciones, restante
+}
+
+// StockSinLote retorna la parte del stock del producto que no está asignada a ningún lote
+func StockSinLote(producto *Producto, lotes []*Lote) int {
+	enLotes := 0
+	for _, lote := range lotes {
+		enLotes += lote.CantidadDisponible
+	}
+	if sinLote := producto.StockActual - enLotes; sinLote > 0 {
+		return sinLote
+	}
+	return 0
+}
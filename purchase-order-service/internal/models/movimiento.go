@@ -0,0 +1,121 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TipoMovimiento representa el tipo de un movimiento de inventario
+type TipoMovimiento string
+
+const (
+	MovimientoRecepcion            TipoMovimiento = "RECEPCION"
+	MovimientoConsumo              TipoMovimiento = "CONSUMO"
+	MovimientoBajaDano             TipoMovimiento = "BAJA_DANO"
+	MovimientoTransferenciaEntrada TipoMovimiento = "TRANSFERENCIA_ENTRADA"
+	MovimientoTransferenciaSalida  TipoMovimiento = "TRANSFERENCIA_SALIDA"
+	MovimientoAjuste               TipoMovimiento = "AJUSTE"
+	MovimientoBajaVencimiento      TipoMovimiento = "BAJA_VENCIMIENTO"
+)
+
+// ErrMovimientoInvalido se retorna cuando los datos de un movimiento no son consistentes
+var ErrMovimientoInvalido = errors.New("movimiento de inventario inválido")
+
+// MovimientoInventario es una entrada inmutable del libro de movimientos de un producto.
+// Cantidad lleva signo: positiva para entradas y negativa para salidas.
+type MovimientoInventario struct {
+	ProductoID          string           `json:"producto_id" dynamodbav:"producto_id"`
+	MovimientoID        string           `json:"movimiento_id" dynamodbav:"movimiento_id"`
+	Tipo                TipoMovimiento   `json:"tipo" dynamodbav:"tipo"`
+	Cantidad            int              `json:"cantidad" dynamodbav:"cantidad"`
+	Motivo              string           `json:"motivo" dynamodbav:"motivo"`
+	UsuarioID           string           `json:"usuario_id" dynamodbav:"usuario_id"`
+	DocumentoReferencia string           `json:"documento_referencia,omitempty" dynamodbav:"documento_referencia,omitempty"`
+	Lotes               []MovimientoLote `json:"lotes,omitempty" dynamodbav:"lotes,omitempty"`
+	FechaMovimiento     time.Time        `json:"fecha_movimiento" dynamodbav:"fecha_movimiento"`
+}
+
+// ConciliacionStock compara el stock proyectado del producto con la suma de su libro de movimientos
+type ConciliacionStock struct {
+	ProductoID     string    `json:"producto_id"`
+	NombreProducto string    `json:"nombre_producto"`
+	StockActual    int       `json:"stock_actual"`
+	StockLibro     int       `json:"stock_libro"`
+	Diferencia     int       `json:"diferencia"`
+	Movimientos    int       `json:"movimientos"`
+	Conciliado     bool      `json:"conciliado"`
+	FechaRevision  time.Time `json:"fecha_revision"`
+}
+
+// NewMovimientoInventario crea un movimiento aplicando el signo que corresponde al tipo.
+// Para AJUSTE la cantidad se toma con su signo; para el resto se espera una cantidad positiva.
+func NewMovimientoInventario(productoID string, tipo TipoMovimiento, cantidad int, motivo, usuarioID, documentoReferencia string) (*MovimientoInventario, error) {
+	if motivo == "" {
+		return nil, fmt.Errorf("%w: el motivo es obligatorio", ErrMovimientoInvalido)
+	}
+	if usuarioID == "" {
+		return nil, fmt.Errorf("%w: el usuario es obligatorio", ErrMovimientoInvalido)
+	}
+
+	switch tipo {
+	case MovimientoAjuste:
+		if cantidad == 0 {
+			return nil, fmt.Errorf("%w: un ajuste no puede ser de cero unidades", ErrMovimientoInvalido)
+		}
+	case MovimientoRecepcion, MovimientoTransferenciaEntrada:
+		if cantidad <= 0 {
+			return nil, fmt.Errorf("%w: la cantidad de %s debe ser positiva", ErrMovimientoInvalido, tipo)
+		}
+	case MovimientoConsumo, MovimientoBajaDano, MovimientoTransferenciaSalida, MovimientoBajaVencimiento:
+		if cantidad <= 0 {
+			return nil, fmt.Errorf("%w: la cantidad de %s debe ser positiva", ErrMovimientoInvalido, tipo)
+		}
+		cantidad = -cantidad
+	default:
+		return nil, fmt.Errorf("%w: tipo de movimiento desconocido %q", ErrMovimientoInvalido, tipo)
+	}
+
+	now := time.Now()
+	return &MovimientoInventario{
+		ProductoID:          productoID,
+		MovimientoID:        newMovimientoID(now),
+		Tipo:                tipo,
+		Cantidad:            cantidad,
+		Motivo:              motivo,
+		UsuarioID:           usuarioID,
+		DocumentoReferencia: documentoReferencia,
+		FechaMovimiento:     now,
+	}, nil
+}
+
+// newMovimientoID genera un ID que ordena cronológicamente los movimientos de un producto
+func newMovimientoID(t time.Time) string {
+	return t.UTC().Format("20060102T150405.000000000Z") + "-" + uuid.New().String()[:8]
+}
+
+// SumarMovimientos retorna el stock que resulta de aplicar los movimientos del libro
+func SumarMovimientos(movimientos []*MovimientoInventario) int {
+	total := 0
+	for _, m := range movimientos {
+		total += m.Cantidad
+	}
+	return total
+}
+
+// Conciliar compara el stock actual del producto contra su libro de movimientos
+func (p *Producto) Conciliar(movimientos []*MovimientoInventario) *ConciliacionStock {
+	stockLibro := SumarMovimientos(movimientos)
+	return &ConciliacionStock{
+		ProductoID:     p.ProductoID,
+		NombreProducto: p.Nombre,
+		StockActual:    p.StockActual,
+		StockLibro:     stockLibro,
+		Diferencia:     p.StockActual - stockLibro,
+		Movimientos:    len(movimientos),
+		Conciliado:     p.StockActual == stockLibro,
+		FechaRevision:  time.Now(),
+	}
+}
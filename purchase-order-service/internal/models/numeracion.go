@@ -0,0 +1,35 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NumeracionOrden es el número que se asigna a una orden nueva dentro de su serie (prefijo y año).
+// Anterior es el último número de la serie leído del contador; la orden se guarda solo si sigue
+// siéndolo, de modo que la serie no tenga huecos ni repetidos.
+type NumeracionOrden struct {
+	Serie     string
+	Anterior  int64
+	Secuencia int64
+}
+
+// SerieNumeracion retorna la serie de un prefijo para el año de la fecha (por ejemplo "ORD-2026")
+func SerieNumeracion(prefijo string, fecha time.Time) string {
+	return fmt.Sprintf("%s-%d", strings.ToUpper(prefijo), fecha.UTC().Year())
+}
+
+// NuevaNumeracion retorna el número siguiente al último asignado en la serie
+func NuevaNumeracion(serie string, ultimo int64) *NumeracionOrden {
+	return &NumeracionOrden{
+		Serie:     serie,
+		Anterior:  ultimo,
+		Secuencia: ultimo + 1,
+	}
+}
+
+// Numero retorna el número de orden con la secuencia de seis dígitos (por ejemplo "ORD-2026-000042")
+func (n *NumeracionOrden) Numero() string {
+	return fmt.Sprintf("%s-%06d", n.Serie, n.Secuencia)
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +17,10 @@ const (
 	EstadoConfirmada EstadoOrden = "CONFIRMADA"
 	EstadoRecibida   EstadoOrden = "RECIBIDA"
 	EstadoCancelada  EstadoOrden = "CANCELADA"
+
+	EstadoParcialmenteRecibida EstadoOrden = "PARCIALMENTE_RECIBIDA"
+	EstadoPendienteAprobacion  EstadoOrden = "PENDIENTE_APROBACION"
+	EstadoRechazada            EstadoOrden = "RECHAZADA"
 )
 
 // Prioridad representa la prioridad de una orden
@@ -35,21 +41,35 @@ const (
 	EstadoItemConfirmado EstadoItem = "CONFIRMADO"
 	EstadoItemRecibido   EstadoItem = "RECIBIDO"
 	EstadoItemCancelado  EstadoItem = "CANCELADO"
+
+	EstadoItemParcialmenteRecibido EstadoItem = "PARCIALMENTE_RECIBIDO"
 )
 
 // OrdenCompra representa la entidad raíz del agregado OrdenCompraAutomatica
 type OrdenCompra struct {
-	OrdenID          string            `json:"orden_id" dynamodbav:"orden_id"`
-	NumeroOrden      string            `json:"numero_orden" dynamodbav:"numero_orden"`
-	ProveedorID      string            `json:"proveedor_id" dynamodbav:"proveedor_id"`
-	FechaGeneracion  time.Time         `json:"fecha_generacion" dynamodbav:"fecha_generacion"`
-	EstadoOrden      EstadoOrden       `json:"estado_orden" dynamodbav:"estado_orden"`
-	Prioridad        Prioridad         `json:"prioridad" dynamodbav:"prioridad"`
-	MotivoGeneracion string            `json:"motivo_generacion" dynamodbav:"motivo_generacion"`
-	Items            []ItemOrdenCompra `json:"items" dynamodbav:"items"`
-	Evaluacion       *Evaluacion       `json:"evaluacion" dynamodbav:"evaluacion"`
-	CreatedAt        time.Time         `json:"created_at" dynamodbav:"created_at"`
-	UpdatedAt        time.Time         `json:"updated_at" dynamodbav:"updated_at"`
+	OrdenID           string                 `json:"orden_id" dynamodbav:"orden_id"`
+	NumeroOrden       string                 `json:"numero_orden" dynamodbav:"numero_orden"`
+	ProveedorID       string                 `json:"proveedor_id" dynamodbav:"proveedor_id"`
+	FechaGeneracion   time.Time              `json:"fecha_generacion" dynamodbav:"fecha_generacion"`
+	EstadoOrden       EstadoOrden            `json:"estado_orden" dynamodbav:"estado_orden"`
+	Prioridad         Prioridad              `json:"prioridad" dynamodbav:"prioridad"`
+	MotivoGeneracion  string                 `json:"motivo_generacion" dynamodbav:"motivo_generacion"`
+	Items             []ItemOrdenCompra      `json:"items" dynamodbav:"items"`
+	Evaluacion        *Evaluacion            `json:"evaluacion" dynamodbav:"evaluacion"`
+	MotivoCancelacion string                 `json:"motivo_cancelacion,omitempty" dynamodbav:"motivo_cancelacion,omitempty"`
+	Recepciones       []Recepcion            `json:"recepciones,omitempty" dynamodbav:"recepciones,omitempty"`
+	Aprobacion        *AprobacionOrden       `json:"aprobacion,omitempty" dynamodbav:"aprobacion,omitempty"`
+	CentroCostoID     string                 `json:"centro_costo_id,omitempty" dynamodbav:"centro_costo_id,omitempty"`
+	Compromiso        *CompromisoPresupuesto `json:"compromiso,omitempty" dynamodbav:"compromiso,omitempty"`
+	Moneda            string                 `json:"moneda" dynamodbav:"moneda"`
+	Totales           TotalesOrden           `json:"totales" dynamodbav:"totales"`
+	Documento         *DocumentoOrden        `json:"documento,omitempty" dynamodbav:"documento,omitempty"`
+	CreatedAt         time.Time              `json:"created_at" dynamodbav:"created_at"`
+	UpdatedAt         time.Time              `json:"updated_at" dynamodbav:"updated_at"`
+
+	// OrigenNumeracion se agrega al prefijo de la serie con que se numera una orden nueva según su
+	// origen (por ejemplo STOCK); no se guarda
+	OrigenNumeracion string `json:"-" dynamodbav:"-"`
 }
 
 // ItemOrdenCompra representa un item de la orden de compra
@@ -57,9 +77,27 @@ type ItemOrdenCompra struct {
 	ItemID               string     `json:"item_id" dynamodbav:"item_id"`
 	ProductoID           string     `json:"producto_id" dynamodbav:"producto_id"`
 	CantidadSolicitada   int        `json:"cantidad_solicitada" dynamodbav:"cantidad_solicitada"`
-	PrecioUnitario       float64    `json:"precio_unitario" dynamodbav:"precio_unitario"`
+	PrecioUnitario       Monto      `json:"precio_unitario" dynamodbav:"precio_unitario"`
 	TemperaturaRequerida float64    `json:"temperatura_requerida" dynamodbav:"temperatura_requerida"`
 	EstadoItem           EstadoItem `json:"estado_item" dynamodbav:"estado_item"`
+	CantidadRecibida     int        `json:"cantidad_recibida" dynamodbav:"cantidad_recibida"`
+	CantidadRechazada    int        `json:"cantidad_rechazada" dynamodbav:"cantidad_rechazada"`
+
+	// Origen del precio unitario y, si hay precio del proveedor, cuánto se desvía de él
+	OrigenPrecio          OrigenPrecio `json:"origen_precio,omitempty" dynamodbav:"origen_precio,omitempty"`
+	PrecioReferencia      Monto        `json:"precio_referencia,omitempty" dynamodbav:"precio_referencia,omitempty"`
+	DesvioPrecio          float64      `json:"desvio_precio,omitempty" dynamodbav:"desvio_precio,omitempty"`
+	PrecioFueraTolerancia bool         `json:"precio_fuera_tolerancia,omitempty" dynamodbav:"precio_fuera_tolerancia,omitempty"`
+
+	// Importes del item: el descuento es un porcentaje del subtotal y el IVA se aplica sobre
+	// el subtotal menos el descuento
+	DescuentoPorcentaje float64 `json:"descuento_porcentaje,omitempty" dynamodbav:"descuento_porcentaje,omitempty"`
+	Subtotal            Monto   `json:"subtotal" dynamodbav:"subtotal"`
+	Descuento           Monto   `json:"descuento" dynamodbav:"descuento"`
+	TasaImpuesto        float64 `json:"tasa_impuesto" dynamodbav:"tasa_impuesto"`
+	ExentoImpuesto      bool    `json:"exento_impuesto" dynamodbav:"exento_impuesto"`
+	Impuesto            Monto   `json:"impuesto" dynamodbav:"impuesto"`
+	Total               Monto   `json:"total" dynamodbav:"total"`
 }
 
 // Evaluacion representa la evaluación del proveedor para la orden
@@ -74,14 +112,16 @@ type Evaluacion struct {
 
 // Producto representa un producto en el catálogo
 type Producto struct {
-	ProductoID   string       `json:"producto_id" dynamodbav:"producto_id"`
-	Nombre       string       `json:"nombre" dynamodbav:"nombre"`
-	StockActual  int          `json:"stock_actual" dynamodbav:"stock_actual"`
-	PuntoReorden int          `json:"punto_reorden" dynamodbav:"punto_reorden"`
-	StockMaximo  int          `json:"stock_maximo" dynamodbav:"stock_maximo"`
-	Condiciones  *Condiciones `json:"condiciones" dynamodbav:"condiciones"`
-	CreatedAt    time.Time    `json:"created_at" dynamodbav:"created_at"`
-	UpdatedAt    time.Time    `json:"updated_at" dynamodbav:"updated_at"`
+	ProductoID         string              `json:"producto_id" dynamodbav:"producto_id"`
+	Nombre             string              `json:"nombre" dynamodbav:"nombre"`
+	Categoria          string              `json:"categoria,omitempty" dynamodbav:"categoria,omitempty"`
+	StockActual        int                 `json:"stock_actual" dynamodbav:"stock_actual"`
+	PuntoReorden       int                 `json:"punto_reorden" dynamodbav:"punto_reorden"`
+	StockMaximo        int                 `json:"stock_maximo" dynamodbav:"stock_maximo"`
+	Condiciones        *Condiciones        `json:"condiciones" dynamodbav:"condiciones"`
+	PoliticaReposicion *PoliticaReposicion `json:"politica_reposicion,omitempty" dynamodbav:"politica_reposicion,omitempty"`
+	CreatedAt          time.Time           `json:"created_at" dynamodbav:"created_at"`
+	UpdatedAt          time.Time           `json:"updated_at" dynamodbav:"updated_at"`
 }
 
 // Condiciones representa las condiciones requeridas para un producto
@@ -107,7 +147,6 @@ func NewOrdenCompra(proveedorID, motivoGeneracion string, prioridad Prioridad) *
 	now := time.Now()
 	return &OrdenCompra{
 		OrdenID:          uuid.New().String(),
-		NumeroOrden:      generateOrderNumber(),
 		ProveedorID:      proveedorID,
 		FechaGeneracion:  now,
 		EstadoOrden:      EstadoGenerada,
@@ -120,7 +159,7 @@ func NewOrdenCompra(proveedorID, motivoGeneracion string, prioridad Prioridad) *
 }
 
 // NewItemOrdenCompra crea un nuevo item de orden
-func NewItemOrdenCompra(productoID string, cantidad int, precioUnitario, temperaturaRequerida float64) ItemOrdenCompra {
+func NewItemOrdenCompra(productoID string, cantidad int, precioUnitario Monto, temperaturaRequerida float64) ItemOrdenCompra {
 	return ItemOrdenCompra{
 		ItemID:               uuid.New().String(),
 		ProductoID:           productoID,
@@ -156,39 +195,147 @@ func NewCondiciones(cadenaFrioRequerida bool, tempMin, tempMax float64, tiempoMa
 	}
 }
 
-// generateOrderNumber genera un número de orden único
-func generateOrderNumber() string {
-	return "ORD-" + time.Now().Format("20060102") + "-" + uuid.New().String()[:8]
-}
-
 // AddItem agrega un item a la orden
 func (o *OrdenCompra) AddItem(item ItemOrdenCompra) {
 	o.Items = append(o.Items, item)
 	o.UpdatedAt = time.Now()
 }
 
-// ConfirmOrder confirma la orden
-func (o *OrdenCompra) ConfirmOrder() {
-	o.EstadoOrden = EstadoConfirmada
-	o.UpdatedAt = time.Now()
+// ValidarEdicion verifica que la orden pueda editarse o eliminarse: solo mientras está GENERADA
+// o pendiente de aprobación
+func (o *OrdenCompra) ValidarEdicion() error {
+	if o.EstadoOrden != EstadoGenerada && o.EstadoOrden != EstadoPendienteAprobacion {
+		return fmt.Errorf("%w: la orden está %s y ya no puede modificarse", ErrTransicionInvalida, o.EstadoOrden)
+	}
+	return nil
 }
 
-// ReceiveOrder marca la orden como recibida
-func (o *OrdenCompra) ReceiveOrder() {
-	o.EstadoOrden = EstadoRecibida
+// AsignarItems reemplaza los items de la orden. Un item con el ItemID de uno existente conserva
+// su estado y lo recibido; el resto entra como línea nueva, pendiente y sin nada recibido, con
+// un ItemID propio.
+func (o *OrdenCompra) AsignarItems(items []ItemOrdenCompra) {
+	existentes := make(map[string]ItemOrdenCompra, len(o.Items))
+	for _, item := range o.Items {
+		existentes[item.ItemID] = item
+	}
+
+	asignados := make([]ItemOrdenCompra, 0, len(items))
+	for _, item := range items {
+		if existente, ok := existentes[item.ItemID]; ok && item.ItemID != "" {
+			item.EstadoItem = existente.EstadoItem
+			item.CantidadRecibida = existente.CantidadRecibida
+			item.CantidadRechazada = existente.CantidadRechazada
+			delete(existentes, item.ItemID)
+		} else {
+			item.ItemID = uuid.New().String()
+			item.EstadoItem = EstadoItemPendiente
+			item.CantidadRecibida = 0
+			item.CantidadRechazada = 0
+		}
+		asignados = append(asignados, item)
+	}
+
+	o.Items = asignados
 	o.UpdatedAt = time.Now()
 }
 
-// SendOrder marca la orden como enviada
-func (o *OrdenCompra) SendOrder() {
-	o.EstadoOrden = EstadoEnviada
-	o.UpdatedAt = time.Now()
+// ValorTotal retorna el total de la orden con descuentos e IVA, según sus totales calculados
+func (o *OrdenCompra) ValorTotal() Monto {
+	return o.Totales.Total
+}
+
+// ErrTransicionInvalida se retorna cuando el estado actual de la orden no admite el cambio solicitado
+var ErrTransicionInvalida = errors.New("transición de estado inválida")
+
+// transicionesOrden define los estados a los que puede pasar una orden desde cada estado.
+// RECIBIDA, CANCELADA y RECHAZADA son estados finales y una orden con recepciones ya no puede
+// cancelarse. Una orden generada vuelve a aprobación si un
+// cambio exige una aprobación que no tenía.
+var transicionesOrden = map[EstadoOrden][]EstadoOrden{
+	EstadoPendienteAprobacion:  {EstadoGenerada, EstadoRechazada, EstadoCancelada},
+	EstadoGenerada:             {EstadoEnviada, EstadoCancelada, EstadoPendienteAprobacion},
+	EstadoEnviada:              {EstadoConfirmada, EstadoCancelada},
+	EstadoConfirmada:           {EstadoParcialmenteRecibida, EstadoRecibida, EstadoCancelada},
+	EstadoParcialmenteRecibida: {EstadoParcialmenteRecibida, EstadoRecibida},
+}
+
+// PuedeTransicionar indica si la orden puede pasar al estado indicado
+func (o *OrdenCompra) PuedeTransicionar(destino EstadoOrden) bool {
+	for _, estado := range transicionesOrden[o.EstadoOrden] {
+		if estado == destino {
+			return true
+		}
+	}
+	return false
 }
 
-// CancelOrder cancela la orden
-func (o *OrdenCompra) CancelOrder() {
-	o.EstadoOrden = EstadoCancelada
+// transicionar cambia el estado de la orden validando la tabla de transiciones
+func (o *OrdenCompra) transicionar(destino EstadoOrden) error {
+	if !o.PuedeTransicionar(destino) {
+		return fmt.Errorf("%w: %s -> %s", ErrTransicionInvalida, o.EstadoOrden, destino)
+	}
+	o.EstadoOrden = destino
 	o.UpdatedAt = time.Now()
+	return nil
+}
+
+// SendOrder marca la orden como enviada al proveedor
+func (o *OrdenCompra) SendOrder() error {
+	return o.transicionar(EstadoEnviada)
+}
+
+// ConfirmOrder confirma la orden
+func (o *OrdenCompra) ConfirmOrder() error {
+	return o.transicionar(EstadoConfirmada)
+}
+
+// ReceiveOrder recibe de una vez todo lo pendiente de cada item y cierra la orden
+func (o *OrdenCompra) ReceiveOrder() error {
+	recepcion := o.RecepcionPendiente()
+	if len(recepcion.Lineas) == 0 {
+		return o.transicionar(EstadoRecibida)
+	}
+	return o.RegistrarRecepcion(recepcion)
+}
+
+// CancelOrder cancela la orden y sus items pendientes
+func (o *OrdenCompra) CancelOrder(motivo string) error {
+	if err := o.transicionar(EstadoCancelada); err != nil {
+		return err
+	}
+	o.MotivoCancelacion = motivo
+	for i := range o.Items {
+		if o.Items[i].EstadoItem != EstadoItemRecibido {
+			o.Items[i].EstadoItem = EstadoItemCancelado
+		}
+	}
+	return nil
+}
+
+// ErrProductoInvalido se retorna cuando los datos de un producto no son consistentes
+var ErrProductoInvalido = errors.New("producto inválido")
+
+// Validar verifica la consistencia de los niveles de stock y del rango de temperatura
+func (p *Producto) Validar() error {
+	if p.Nombre == "" {
+		return fmt.Errorf("%w: el nombre es obligatorio", ErrProductoInvalido)
+	}
+	if p.StockActual < 0 || p.PuntoReorden < 0 || p.StockMaximo <= 0 {
+		return fmt.Errorf("%w: los niveles de stock no pueden ser negativos y stock_maximo debe ser mayor a cero", ErrProductoInvalido)
+	}
+	if p.PuntoReorden >= p.StockMaximo {
+		return fmt.Errorf("%w: punto_reorden (%d) debe ser menor que stock_maximo (%d)", ErrProductoInvalido, p.PuntoReorden, p.StockMaximo)
+	}
+	if p.Condiciones != nil && p.Condiciones.TemperaturaMinima > p.Condiciones.TemperaturaMaxima {
+		return fmt.Errorf("%w: temperatura_minima (%.1f) no puede ser mayor que temperatura_maxima (%.1f)",
+			ErrProductoInvalido, p.Condiciones.TemperaturaMinima, p.Condiciones.TemperaturaMaxima)
+	}
+	if p.PoliticaReposicion != nil {
+		if err := p.PoliticaReposicion.Validar(); err != nil {
+			return fmt.Errorf("%w: %v", ErrProductoInvalido, err)
+		}
+	}
+	return nil
 }
 
 // IsLowStock verifica si el producto está bajo stock
@@ -0,0 +1,202 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrPoliticaIncompleta se retorna cuando a una política de reposición le faltan parámetros
+var ErrPoliticaIncompleta = errors.New("política de reposición incompleta")
+
+// TipoPolitica identifica cómo se calcula cuándo y cuánto reponer un producto
+type TipoPolitica string
+
+const (
+	// PoliticaMinMax repone hasta stock_maximo al llegar a punto_reorden
+	PoliticaMinMax TipoPolitica = "MIN_MAX"
+	// PoliticaCantidadFija pide siempre la misma cantidad al llegar a punto_reorden
+	PoliticaCantidadFija TipoPolitica = "CANTIDAD_FIJA"
+	// PoliticaEOQ pide el lote económico (fórmula de Wilson) al llegar a punto_reorden
+	PoliticaEOQ TipoPolitica = "EOQ"
+	// PoliticaPuntoReorden calcula el punto de reorden como demanda × tiempo de entrega más
+	// el stock de seguridad y repone hasta stock_maximo
+	PoliticaPuntoReorden TipoPolitica = "PUNTO_REORDEN"
+)
+
+// politicas lista las políticas disponibles en el orden en que se muestran
+var politicas = []TipoPolitica{PoliticaMinMax, PoliticaCantidadFija, PoliticaEOQ, PoliticaPuntoReorden}
+
+// diasPorAnio convierte la demanda diaria en anual para el lote económico
+const diasPorAnio = 365
+
+// PoliticaReposicion guarda la política elegida para un producto y sus parámetros. Cada
+// política usa solo los suyos; sin política se aplica MIN_MAX.
+type PoliticaReposicion struct {
+	Tipo               TipoPolitica `json:"tipo" dynamodbav:"tipo"`
+	CantidadFija       int          `json:"cantidad_fija,omitempty" dynamodbav:"cantidad_fija,omitempty"`
+	DemandaDiaria      float64      `json:"demanda_diaria,omitempty" dynamodbav:"demanda_diaria,omitempty"`
+	CostoPedido        float64      `json:"costo_pedido,omitempty" dynamodbav:"costo_pedido,omitempty"`
+	CostoMantenimiento float64      `json:"costo_mantenimiento,omitempty" dynamodbav:"costo_mantenimiento,omitempty"`
+	TiempoEntregaDias  int          `json:"tiempo_entrega_dias,omitempty" dynamodbav:"tiempo_entrega_dias,omitempty"`
+	StockSeguridad     int          `json:"stock_seguridad,omitempty" dynamodbav:"stock_seguridad,omitempty"`
+}
+
+// CalculoReposicion es lo que una política pediría hoy para un producto
+type CalculoReposicion struct {
+	Politica           TipoPolitica `json:"politica"`
+	Activa             bool         `json:"activa"`
+	PuntoReorden       int          `json:"punto_reorden"`
+	RequiereReposicion bool         `json:"requiere_reposicion"`
+	Cantidad           int          `json:"cantidad"`
+	Prioridad          Prioridad    `json:"prioridad,omitempty"`
+	Detalle            string       `json:"detalle,omitempty"`
+	Error              string       `json:"error,omitempty"`
+}
+
+// Validar verifica que la política sea conocida y tenga los parámetros que usa
+func (p *PoliticaReposicion) Validar() error {
+	switch p.Tipo {
+	case PoliticaMinMax:
+	case PoliticaCantidadFija:
+		if p.CantidadFija <= 0 {
+			return fmt.Errorf("%w: cantidad_fija debe ser mayor a cero", ErrPoliticaIncompleta)
+		}
+	case PoliticaEOQ:
+		if p.DemandaDiaria <= 0 || p.CostoPedido <= 0 || p.CostoMantenimiento <= 0 {
+			return fmt.Errorf("%w: EOQ requiere demanda_diaria, costo_pedido y costo_mantenimiento mayores a cero", ErrPoliticaIncompleta)
+		}
+	case PoliticaPuntoReorden:
+		if p.DemandaDiaria <= 0 || p.TiempoEntregaDias <= 0 || p.StockSeguridad < 0 {
+			return fmt.Errorf("%w: PUNTO_REORDEN requiere demanda_diaria y tiempo_entrega_dias mayores a cero y stock_seguridad no negativo", ErrPoliticaIncompleta)
+		}
+	default:
+		return fmt.Errorf("%w: tipo %q desconocido", ErrPoliticaIncompleta, p.Tipo)
+	}
+	return nil
+}
+
+// TipoPoliticaReposicion retorna la política que aplica al producto
+func (p *Producto) TipoPoliticaReposicion() TipoPolitica {
+	if p.PoliticaReposicion == nil {
+		return PoliticaMinMax
+	}
+	return p.PoliticaReposicion.Tipo
+}
+
+// AplicarPolitica actualiza punto_reorden cuando la política lo calcula (PUNTO_REORDEN), para
+// que el listado de stock bajo y los eventos de stock usen el mismo umbral
+func (p *Producto) AplicarPolitica() {
+	if p.TipoPoliticaReposicion() != PoliticaPuntoReorden || p.PoliticaReposicion.Validar() != nil {
+		return
+	}
+	p.PuntoReorden = p.PoliticaReposicion.puntoReordenCalculado()
+}
+
+// CalcularReposicion aplica la política del producto
+func (p *Producto) CalcularReposicion() (*CalculoReposicion, error) {
+	return p.calcularCon(p.TipoPoliticaReposicion())
+}
+
+// VistaPreviaReposicion calcula lo que pediría hoy cada política con los parámetros del
+// producto. Las políticas a las que les faltan parámetros se informan con el error.
+func (p *Producto) VistaPreviaReposicion() []*CalculoReposicion {
+	activa := p.TipoPoliticaReposicion()
+	calculos := make([]*CalculoReposicion, 0, len(politicas))
+	for _, tipo := range politicas {
+		calculo, err := p.calcularCon(tipo)
+		if err != nil {
+			calculo = &CalculoReposicion{Politica: tipo, Error: err.Error()}
+		}
+		calculo.Activa = tipo == activa
+		calculos = append(calculos, calculo)
+	}
+	return calculos
+}
+
+// calcularCon calcula la reposición del producto con la política indicada, tomando sus
+// parámetros de la política guardada en el producto
+func (p *Producto) calcularCon(tipo TipoPolitica) (*CalculoReposicion, error) {
+	parametros := PoliticaReposicion{Tipo: tipo}
+	if p.PoliticaReposicion != nil {
+		parametros = *p.PoliticaReposicion
+		parametros.Tipo = tipo
+	}
+	if err := parametros.Validar(); err != nil {
+		return nil, err
+	}
+
+	calculo := &CalculoReposicion{
+		Politica:     tipo,
+		PuntoReorden: p.PuntoReorden,
+	}
+	if tipo == PoliticaPuntoReorden {
+		calculo.PuntoReorden = parametros.puntoReordenCalculado()
+		calculo.Detalle = fmt.Sprintf("punto de reorden = %.2f u/día × %d días + %d de seguridad",
+			parametros.DemandaDiaria, parametros.TiempoEntregaDias, parametros.StockSeguridad)
+	}
+
+	calculo.RequiereReposicion = p.StockActual <= calculo.PuntoReorden
+	if !calculo.RequiereReposicion {
+		return calculo, nil
+	}
+
+	hastaMaximo := p.StockMaximo - p.StockActual
+	cantidad := hastaMaximo
+	switch tipo {
+	case PoliticaCantidadFija:
+		cantidad = parametros.CantidadFija
+	case PoliticaEOQ:
+		cantidad = parametros.loteEconomico()
+		calculo.Detalle = fmt.Sprintf("lote económico = √(2 × %.0f u/año × %.2f / %.2f)",
+			parametros.DemandaDiaria*diasPorAnio, parametros.CostoPedido, parametros.CostoMantenimiento)
+	}
+	if cantidad > hastaMaximo {
+		cantidad = hastaMaximo
+		calculo.Detalle = agregarDetalle(calculo.Detalle, "limitada por stock_maximo")
+	}
+	if cantidad < 0 {
+		cantidad = 0
+	}
+
+	calculo.Cantidad = cantidad
+	calculo.Prioridad = p.prioridadSegun(parametros, calculo.PuntoReorden)
+	return calculo, nil
+}
+
+// prioridadSegun determina la urgencia: CRITICA sin stock, ALTA por debajo del stock de
+// seguridad (o de la mitad del punto de reorden si la política no lo define) y MEDIA en otro caso
+func (p *Producto) prioridadSegun(parametros PoliticaReposicion, puntoReorden int) Prioridad {
+	nivelAlto := puntoReorden / 2
+	if parametros.Tipo == PoliticaPuntoReorden {
+		nivelAlto = parametros.StockSeguridad
+	}
+
+	switch {
+	case p.StockActual == 0:
+		return PrioridadCritica
+	case p.StockActual <= nivelAlto:
+		return PrioridadAlta
+	default:
+		return PrioridadMedia
+	}
+}
+
+// puntoReordenCalculado retorna demanda diaria × tiempo de entrega más el stock de seguridad
+func (p *PoliticaReposicion) puntoReordenCalculado() int {
+	return int(math.Ceil(p.DemandaDiaria*float64(p.TiempoEntregaDias))) + p.StockSeguridad
+}
+
+// loteEconomico retorna la cantidad que minimiza el costo de pedir y mantener inventario
+func (p *PoliticaReposicion) loteEconomico() int {
+	demandaAnual := p.DemandaDiaria * diasPorAnio
+	return int(math.Ceil(math.Sqrt(2 * demandaAnual * p.CostoPedido / p.CostoMantenimiento)))
+}
+
+// agregarDetalle concatena una nota al detalle de un cálculo
+func agregarDetalle(detalle, nota string) string {
+	if detalle == "" {
+		return nota
+	}
+	return detalle + "; " + nota
+}
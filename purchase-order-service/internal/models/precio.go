@@ -0,0 +1,131 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// ErrPrecioNoResuelto se retorna cuando un item sin precio no tiene precio de catálogo en su proveedor
+var ErrPrecioNoResuelto = errors.New("no se pudo resolver el precio del item")
+
+// OrigenPrecio indica de dónde sale el precio unitario de un item
+type OrigenPrecio string
+
+const (
+	OrigenPrecioContratado OrigenPrecio = "CONTRATO"
+	OrigenPrecioBase       OrigenPrecio = "PRECIO_BASE"
+	OrigenPrecioManual     OrigenPrecio = "MANUAL"
+)
+
+// PrecioProveedor es el precio vigente de un producto en el catálogo de un proveedor
+type PrecioProveedor struct {
+	Precio Monto
+	Origen OrigenPrecio
+}
+
+// PrecioVigente retorna el precio contratado mientras el contrato esté vigente o, si no, el
+// precio base. Retorna nil si la oferta no tiene precio.
+func (o *ProductoOfrecido) PrecioVigente(ahora time.Time) *PrecioProveedor {
+	if o.PrecioContratado > 0 && (o.ContratoVigenteHasta == nil || ahora.Before(*o.ContratoVigenteHasta)) {
+		return &PrecioProveedor{Precio: MontoDesdeFloat(o.PrecioContratado), Origen: OrigenPrecioContratado}
+	}
+	if o.PrecioBase > 0 {
+		return &PrecioProveedor{Precio: MontoDesdeFloat(o.PrecioBase), Origen: OrigenPrecioBase}
+	}
+	return nil
+}
+
+// PreciosVigentes retorna, por producto, el precio vigente de las ofertas del proveedor
+// proyectado en la moneda indicada
+func (p *ProveedorProyectado) PreciosVigentes(moneda string, ahora time.Time) map[string]PrecioProveedor {
+	return preciosVigentes(p.ProductosOfrecidos, moneda, ahora)
+}
+
+// preciosVigentes retorna, por producto, el precio vigente de las ofertas en la moneda indicada.
+// Las ofertas sin moneda se asumen en la moneda de la orden.
+func preciosVigentes(ofertas []ProductoOfrecido, moneda string, ahora time.Time) map[string]PrecioProveedor {
+	precios := map[string]PrecioProveedor{}
+	for i := range ofertas {
+		oferta := &ofertas[i]
+		if oferta.Moneda != "" && !strings.EqualFold(oferta.Moneda, moneda) {
+			continue
+		}
+		if precio := oferta.PrecioVigente(ahora); precio != nil {
+			precios[oferta.ProductoID] = *precio
+		}
+	}
+	return precios
+}
+
+// precioAutomatico indica si el precio del item es el que se resolvió del proveedor y nadie lo
+// cambió después
+func (i *ItemOrdenCompra) precioAutomatico() bool {
+	if i.OrigenPrecio != OrigenPrecioContratado && i.OrigenPrecio != OrigenPrecioBase {
+		return false
+	}
+	return i.PrecioUnitario == i.PrecioReferencia
+}
+
+// ResolverPrecio fija el precio del item y su origen. Un item sin precio, o con el precio que ya
+// se había resuelto del proveedor, toma el precio vigente del proveedor; uno con precio igual al
+// del proveedor conserva ese origen y uno distinto queda MANUAL, marcado si se desvía del precio
+// del proveedor más que la tolerancia (por ejemplo 0.05).
+func (i *ItemOrdenCompra) ResolverPrecio(referencia *PrecioProveedor, tolerancia float64) error {
+	automatico := i.precioAutomatico()
+	i.DesvioPrecio = 0
+	i.PrecioFueraTolerancia = false
+
+	if referencia == nil {
+		if i.PrecioUnitario == 0 {
+			i.PrecioReferencia = 0
+			return fmt.Errorf("%w: el proveedor no tiene precio para el producto %s", ErrPrecioNoResuelto, i.ProductoID)
+		}
+		// Sin precio vigente, un precio automático se conserva con su origen
+		if automatico {
+			return nil
+		}
+		i.PrecioReferencia = 0
+		i.OrigenPrecio = OrigenPrecioManual
+		return nil
+	}
+
+	i.PrecioReferencia = referencia.Precio
+	if i.PrecioUnitario == 0 || automatico || i.PrecioUnitario == referencia.Precio {
+		i.PrecioUnitario = referencia.Precio
+		i.OrigenPrecio = referencia.Origen
+		return nil
+	}
+
+	i.OrigenPrecio = OrigenPrecioManual
+	i.DesvioPrecio = math.Round(float64(i.PrecioUnitario-referencia.Precio)/float64(referencia.Precio)*10000) / 10000
+	i.PrecioFueraTolerancia = math.Abs(i.DesvioPrecio) > tolerancia
+	return nil
+}
+
+// ResolverPrecios resuelve el precio de cada item con los precios del proveedor por producto
+func (o *OrdenCompra) ResolverPrecios(precios map[string]PrecioProveedor, tolerancia float64) error {
+	for i := range o.Items {
+		item := &o.Items[i]
+		var referencia *PrecioProveedor
+		if precio, ok := precios[item.ProductoID]; ok {
+			referencia = &precio
+		}
+		if err := item.ResolverPrecio(referencia, tolerancia); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PreciosFueraTolerancia indica si algún item tiene un precio manual fuera de la tolerancia
+func (o *OrdenCompra) PreciosFueraTolerancia() bool {
+	for _, item := range o.Items {
+		if item.PrecioFueraTolerancia {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,238 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// ErrPresupuestoInvalido se retorna cuando los datos de un presupuesto no son válidos
+var ErrPresupuestoInvalido = errors.New("presupuesto inválido")
+
+// PeriodicidadPresupuesto define el período al que se asigna cada presupuesto
+type PeriodicidadPresupuesto string
+
+const (
+	// PeriodicidadMensual asigna un presupuesto por mes (período "2006-01")
+	PeriodicidadMensual PeriodicidadPresupuesto = "MENSUAL"
+	// PeriodicidadAnual asigna un presupuesto por año (período "2006")
+	PeriodicidadAnual PeriodicidadPresupuesto = "ANUAL"
+)
+
+// ParsePeriodicidadPresupuesto valida la periodicidad configurada
+func ParsePeriodicidadPresupuesto(valor string) (PeriodicidadPresupuesto, error) {
+	periodicidad := PeriodicidadPresupuesto(strings.ToUpper(valor))
+	if periodicidad != PeriodicidadMensual && periodicidad != PeriodicidadAnual {
+		return "", fmt.Errorf("%w: periodicidad %q desconocida", ErrPresupuestoInvalido, valor)
+	}
+	return periodicidad, nil
+}
+
+// formato retorna el layout de time del período
+func (p PeriodicidadPresupuesto) formato() string {
+	if p == PeriodicidadAnual {
+		return "2006"
+	}
+	return "2006-01"
+}
+
+// Periodo retorna el período al que corresponde la fecha
+func (p PeriodicidadPresupuesto) Periodo(fecha time.Time) string {
+	return fecha.UTC().Format(p.formato())
+}
+
+// PeriodoValido indica si el período tiene el formato de la periodicidad
+func (p PeriodicidadPresupuesto) PeriodoValido(periodo string) bool {
+	_, err := time.Parse(p.formato(), periodo)
+	return err == nil
+}
+
+// Presupuesto es el monto asignado a un centro de costo para un período. Lo comprometido son
+// las órdenes vigentes aún no recibidas y lo ejecutado, lo ya recibido. El disponible se guarda
+// para poder reservar con una escritura condicional.
+type Presupuesto struct {
+	CentroCostoID     string    `json:"centro_costo_id" dynamodbav:"centro_costo_id"`
+	Periodo           string    `json:"periodo" dynamodbav:"periodo"`
+	MontoAsignado     Monto     `json:"monto_asignado" dynamodbav:"monto_asignado"`
+	MontoComprometido Monto     `json:"monto_comprometido" dynamodbav:"monto_comprometido"`
+	MontoEjecutado    Monto     `json:"monto_ejecutado" dynamodbav:"monto_ejecutado"`
+	MontoDisponible   Monto     `json:"monto_disponible" dynamodbav:"monto_disponible"`
+	CreatedAt         time.Time `json:"created_at" dynamodbav:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at" dynamodbav:"updated_at"`
+}
+
+// NewPresupuesto crea un presupuesto sin consumo para el centro de costo y período
+func NewPresupuesto(centroCostoID, periodo string, montoAsignado Monto) *Presupuesto {
+	now := time.Now()
+	return &Presupuesto{
+		CentroCostoID:   centroCostoID,
+		Periodo:         periodo,
+		MontoAsignado:   montoAsignado,
+		MontoDisponible: montoAsignado,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+}
+
+// Validar verifica el centro de costo, el formato del período y el monto asignado
+func (p *Presupuesto) Validar(periodicidad PeriodicidadPresupuesto) error {
+	if p.CentroCostoID == "" {
+		return fmt.Errorf("%w: el centro de costo es obligatorio", ErrPresupuestoInvalido)
+	}
+	if !periodicidad.PeriodoValido(p.Periodo) {
+		return fmt.Errorf("%w: el período %q no corresponde a la periodicidad %s (%s)",
+			ErrPresupuestoInvalido, p.Periodo, periodicidad, periodicidad.formato())
+	}
+	if p.MontoAsignado < 0 {
+		return fmt.Errorf("%w: el monto asignado no puede ser negativo", ErrPresupuestoInvalido)
+	}
+	return nil
+}
+
+// CompromisoPresupuesto es lo que una orden tiene reservado y ejecutado en el presupuesto de su
+// centro de costo. El período se fija con la primera reserva.
+type CompromisoPresupuesto struct {
+	Periodo           string `json:"periodo" dynamodbav:"periodo"`
+	MontoComprometido Monto  `json:"monto_comprometido" dynamodbav:"monto_comprometido"`
+	MontoEjecutado    Monto  `json:"monto_ejecutado" dynamodbav:"monto_ejecutado"`
+}
+
+// MovimientoPresupuesto es el cambio que una escritura de la orden aplica a su presupuesto
+type MovimientoPresupuesto struct {
+	CentroCostoID string
+	Periodo       string
+	Comprometido  Monto
+	Ejecutado     Monto
+}
+
+// Consumo retorna cuánto disminuye el disponible con el movimiento (negativo si lo libera)
+func (m *MovimientoPresupuesto) Consumo() Monto {
+	return m.Comprometido + m.Ejecutado
+}
+
+// ConsumoOrden es lo que una orden tiene comprometido y ejecutado en un presupuesto
+type ConsumoOrden struct {
+	OrdenID           string      `json:"orden_id"`
+	NumeroOrden       string      `json:"numero_orden"`
+	ProveedorID       string      `json:"proveedor_id"`
+	EstadoOrden       EstadoOrden `json:"estado_orden"`
+	MontoTotal        Monto       `json:"monto_total"`
+	MontoComprometido Monto       `json:"monto_comprometido"`
+	MontoEjecutado    Monto       `json:"monto_ejecutado"`
+}
+
+// ConsumoPresupuesto detalla el consumo de un presupuesto por orden
+type ConsumoPresupuesto struct {
+	Presupuesto            *Presupuesto   `json:"presupuesto"`
+	PorcentajeComprometido float64        `json:"porcentaje_comprometido"`
+	PorcentajeEjecutado    float64        `json:"porcentaje_ejecutado"`
+	PorcentajeDisponible   float64        `json:"porcentaje_disponible"`
+	Ordenes                []ConsumoOrden `json:"ordenes"`
+}
+
+// NewConsumoPresupuesto calcula los porcentajes del presupuesto sobre el monto asignado
+func NewConsumoPresupuesto(presupuesto *Presupuesto) *ConsumoPresupuesto {
+	consumo := &ConsumoPresupuesto{
+		Presupuesto: presupuesto,
+		Ordenes:     []ConsumoOrden{},
+	}
+	if presupuesto.MontoAsignado > 0 {
+		consumo.PorcentajeComprometido = porcentaje(presupuesto.MontoComprometido, presupuesto.MontoAsignado)
+		consumo.PorcentajeEjecutado = porcentaje(presupuesto.MontoEjecutado, presupuesto.MontoAsignado)
+		consumo.PorcentajeDisponible = porcentaje(presupuesto.MontoDisponible, presupuesto.MontoAsignado)
+	}
+	return consumo
+}
+
+// AgregarOrden suma al detalle una orden con compromiso en el presupuesto
+func (c *ConsumoPresupuesto) AgregarOrden(orden *OrdenCompra) {
+	c.Ordenes = append(c.Ordenes, ConsumoOrden{
+		OrdenID:           orden.OrdenID,
+		NumeroOrden:       orden.NumeroOrden,
+		ProveedorID:       orden.ProveedorID,
+		EstadoOrden:       orden.EstadoOrden,
+		MontoTotal:        orden.Totales.Total,
+		MontoComprometido: orden.Compromiso.MontoComprometido,
+		MontoEjecutado:    orden.Compromiso.MontoEjecutado,
+	})
+}
+
+// porcentaje retorna parte sobre total en porcentaje con dos decimales
+func porcentaje(parte, total Monto) float64 {
+	return math.Round(float64(parte)/float64(total)*10000) / 100
+}
+
+// MontoPendiente valoriza, a su total por unidad, lo que falta por recibir de los items vigentes
+func (o *OrdenCompra) MontoPendiente() Monto {
+	var total Monto
+	for i := range o.Items {
+		total += o.Items[i].ValorCantidad(o.Items[i].CantidadPendiente())
+	}
+	return total
+}
+
+// MontoRecibido valoriza, a su total por unidad, las unidades aceptadas de los items
+func (o *OrdenCompra) MontoRecibido() Monto {
+	var total Monto
+	for i := range o.Items {
+		total += o.Items[i].ValorCantidad(o.Items[i].CantidadRecibida)
+	}
+	return total
+}
+
+// ReservaPresupuesto indica si la orden mantiene fondos reservados en su estado actual. Una orden
+// pendiente de aprobación aún no reserva y una orden cerrada libera lo que no recibió.
+func (o *OrdenCompra) ReservaPresupuesto() bool {
+	switch o.EstadoOrden {
+	case EstadoPendienteAprobacion, EstadoRecibida, EstadoCancelada, EstadoRechazada:
+		return false
+	}
+	return true
+}
+
+// LiberarCompromiso retorna el movimiento que devuelve al presupuesto lo que la orden tiene
+// comprometido, o nil si no reserva nada. Lo ejecutado no se devuelve: corresponde a lo recibido.
+func (o *OrdenCompra) LiberarCompromiso() *MovimientoPresupuesto {
+	if o.CentroCostoID == "" || o.Compromiso == nil || o.Compromiso.MontoComprometido == 0 {
+		return nil
+	}
+	return &MovimientoPresupuesto{
+		CentroCostoID: o.CentroCostoID,
+		Periodo:       o.Compromiso.Periodo,
+		Comprometido:  -o.Compromiso.MontoComprometido,
+	}
+}
+
+// AjustarCompromiso lleva el compromiso de la orden a lo que corresponde a su estado e items y
+// retorna el movimiento que debe aplicarse al presupuesto, o nil si no hay cambios. Lo recibido
+// pasa de comprometido a ejecutado.
+func (o *OrdenCompra) AjustarCompromiso(periodo string) *MovimientoPresupuesto {
+	if o.CentroCostoID == "" {
+		return nil
+	}
+	if o.Compromiso == nil {
+		o.Compromiso = &CompromisoPresupuesto{Periodo: periodo}
+	}
+
+	var comprometido Monto
+	if o.ReservaPresupuesto() {
+		comprometido = o.MontoPendiente()
+	}
+	ejecutado := o.MontoRecibido()
+
+	movimiento := &MovimientoPresupuesto{
+		CentroCostoID: o.CentroCostoID,
+		Periodo:       o.Compromiso.Periodo,
+		Comprometido:  comprometido - o.Compromiso.MontoComprometido,
+		Ejecutado:     ejecutado - o.Compromiso.MontoEjecutado,
+	}
+	if movimiento.Comprometido == 0 && movimiento.Ejecutado == 0 {
+		return nil
+	}
+
+	o.Compromiso.MontoComprometido = comprometido
+	o.Compromiso.MontoEjecutado = ejecutado
+	return movimiento
+}
@@ -0,0 +1,277 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrHistorialInsuficiente se retorna cuando no hay suficientes días de consumo para evaluar los métodos
+var ErrHistorialInsuficiente = errors.New("historial de consumo insuficiente para pronosticar")
+
+// ErrPronosticoInvalido se retorna cuando el método o los parámetros del pronóstico no son válidos
+var ErrPronosticoInvalido = errors.New("pronóstico inválido")
+
+// HorizonteMaximoDias es el horizonte más largo que se puede pronosticar
+const HorizonteMaximoDias = 365
+
+// MetodoPronostico identifica cómo se proyecta la demanda diaria
+type MetodoPronostico string
+
+const (
+	// MetodoPromedioMovil proyecta el promedio de los últimos días
+	MetodoPromedioMovil MetodoPronostico = "PROMEDIO_MOVIL"
+	// MetodoSuavizadoExponencial proyecta un nivel que pondera más los días recientes
+	MetodoSuavizadoExponencial MetodoPronostico = "SUAVIZADO_EXPONENCIAL"
+	// MetodoIngenuoEstacional repite la demanda de la última temporada (por ejemplo, la semana anterior)
+	MetodoIngenuoEstacional MetodoPronostico = "INGENUO_ESTACIONAL"
+)
+
+// metodosPronostico lista los métodos en el orden en que se evalúan y, a igual error, se prefieren
+var metodosPronostico = []MetodoPronostico{MetodoPromedioMovil, MetodoSuavizadoExponencial, MetodoIngenuoEstacional}
+
+// minObservacionesBacktest es la cantidad mínima de días evaluados para comparar los métodos
+const minObservacionesBacktest = 7
+
+// ParametrosPronostico configura los métodos y el intervalo de confianza
+type ParametrosPronostico struct {
+	Ventana        int     // días del promedio móvil
+	Alfa           float64 // peso del último día en el suavizado exponencial (0-1]
+	Temporada      int     // días de la temporada del método estacional
+	NivelConfianza float64 // nivel del intervalo de confianza (0-1)
+}
+
+// MetricasPronostico es el error de un método al pronosticar un día hacia adelante sobre el historial
+type MetricasPronostico struct {
+	Metodo        MetodoPronostico `json:"metodo"`
+	Observaciones int              `json:"observaciones"`
+	MAE           float64          `json:"mae"`
+	RMSE          float64          `json:"rmse"`
+	MAPE          *float64         `json:"mape,omitempty"`
+	Sesgo         float64          `json:"sesgo"`
+}
+
+// PuntoPronostico es la demanda pronosticada para un día
+type PuntoPronostico struct {
+	Fecha          time.Time `json:"fecha"`
+	Demanda        float64   `json:"demanda"`
+	LimiteInferior float64   `json:"limite_inferior"`
+	LimiteSuperior float64   `json:"limite_superior"`
+}
+
+// PronosticoDemanda es la demanda pronosticada de un producto para los próximos días
+type PronosticoDemanda struct {
+	ProductoID          string               `json:"producto_id"`
+	Metodo              MetodoPronostico     `json:"metodo"`
+	HorizonteDias       int                  `json:"horizonte_dias"`
+	DiasHistoria        int                  `json:"dias_historia"`
+	NivelConfianza      float64              `json:"nivel_confianza"`
+	DemandaHorizonte    float64              `json:"demanda_horizonte"`
+	LimiteInferior      float64              `json:"limite_inferior"`
+	LimiteSuperior      float64              `json:"limite_superior"`
+	DesviacionHorizonte float64              `json:"desviacion_horizonte"`
+	Diario              []PuntoPronostico    `json:"diario"`
+	Metricas            []MetricasPronostico `json:"metricas"`
+	FechaPronostico     time.Time            `json:"fecha_pronostico"`
+}
+
+// Validar verifica que los parámetros estén en rango
+func (p ParametrosPronostico) Validar() error {
+	if p.Ventana < 1 || p.Temporada < 2 {
+		return fmt.Errorf("%w: la ventana debe ser de al menos 1 día y la temporada de al menos 2", ErrPronosticoInvalido)
+	}
+	if p.Alfa <= 0 || p.Alfa > 1 {
+		return fmt.Errorf("%w: alfa debe estar entre 0 y 1", ErrPronosticoInvalido)
+	}
+	if p.NivelConfianza <= 0 || p.NivelConfianza >= 1 {
+		return fmt.Errorf("%w: el nivel de confianza debe estar entre 0 y 1", ErrPronosticoInvalido)
+	}
+	return nil
+}
+
+// MetodoPronosticoValido indica si el método es uno de los disponibles
+func MetodoPronosticoValido(metodo MetodoPronostico) bool {
+	for _, m := range metodosPronostico {
+		if m == metodo {
+			return true
+		}
+	}
+	return false
+}
+
+// SerieConsumoDiario suma las unidades consumidas por día (UTC) desde el día de desde hasta el
+// día anterior a hasta. Los días sin consumo cuentan como cero; solo se toman los movimientos CONSUMO.
+func SerieConsumoDiario(movimientos []*MovimientoInventario, desde, hasta time.Time) []float64 {
+	inicio := inicioDia(desde)
+	dias := int(inicioDia(hasta).Sub(inicio).Hours() / 24)
+	if dias <= 0 {
+		return []float64{}
+	}
+
+	serie := make([]float64, dias)
+	for _, m := range movimientos {
+		if m.Tipo != MovimientoConsumo {
+			continue
+		}
+		dia := int(inicioDia(m.FechaMovimiento).Sub(inicio).Hours() / 24)
+		if dia < 0 || dia >= dias {
+			continue
+		}
+		serie[dia] += math.Abs(float64(m.Cantidad))
+	}
+	return serie
+}
+
+// PronosticarDemanda proyecta la serie diaria horizonte días a partir de inicio. Cada método se
+// evalúa pronosticando un día hacia adelante sobre el historial; sin método indicado se usa el de
+// menor error absoluto medio. El intervalo de confianza supone errores diarios independientes.
+func PronosticarDemanda(serie []float64, inicio time.Time, horizonte int, metodo MetodoPronostico, parametros ParametrosPronostico) (*PronosticoDemanda, error) {
+	if err := parametros.Validar(); err != nil {
+		return nil, err
+	}
+	if horizonte < 1 || horizonte > HorizonteMaximoDias {
+		return nil, fmt.Errorf("%w: el horizonte debe ser de entre 1 y %d días", ErrPronosticoInvalido, HorizonteMaximoDias)
+	}
+	if metodo != "" && !MetodoPronosticoValido(metodo) {
+		return nil, fmt.Errorf("%w: método %q desconocido", ErrPronosticoInvalido, metodo)
+	}
+
+	// Todos los métodos se evalúan sobre los mismos días para que sus errores sean comparables
+	origen := parametros.Ventana
+	if parametros.Temporada > origen {
+		origen = parametros.Temporada
+	}
+	if len(serie)-origen < minObservacionesBacktest {
+		return nil, fmt.Errorf("%w: se necesitan al menos %d días y hay %d", ErrHistorialInsuficiente, origen+minObservacionesBacktest, len(serie))
+	}
+
+	metricas := make([]MetricasPronostico, 0, len(metodosPronostico))
+	var elegida *MetricasPronostico
+	for _, m := range metodosPronostico {
+		metricas = append(metricas, evaluarMetodo(m, serie, origen, parametros))
+	}
+	for i := range metricas {
+		switch {
+		case metodo != "" && metricas[i].Metodo == metodo:
+			elegida = &metricas[i]
+		case metodo == "" && (elegida == nil || metricas[i].MAE < elegida.MAE):
+			elegida = &metricas[i]
+		}
+	}
+
+	z := math.Sqrt2 * math.Erfinv(parametros.NivelConfianza)
+	sigma := elegida.RMSE
+
+	pronostico := &PronosticoDemanda{
+		Metodo:          elegida.Metodo,
+		HorizonteDias:   horizonte,
+		DiasHistoria:    len(serie),
+		NivelConfianza:  parametros.NivelConfianza,
+		Diario:          make([]PuntoPronostico, 0, horizonte),
+		Metricas:        metricas,
+		FechaPronostico: time.Now(),
+	}
+
+	primerDia := inicioDia(inicio)
+	for i, demanda := range proyectar(elegida.Metodo, serie, horizonte, parametros) {
+		pronostico.Diario = append(pronostico.Diario, PuntoPronostico{
+			Fecha:          primerDia.AddDate(0, 0, i),
+			Demanda:        demanda,
+			LimiteInferior: math.Max(0, demanda-z*sigma),
+			LimiteSuperior: demanda + z*sigma,
+		})
+		pronostico.DemandaHorizonte += demanda
+	}
+
+	pronostico.DesviacionHorizonte = sigma * math.Sqrt(float64(horizonte))
+	pronostico.LimiteInferior = math.Max(0, pronostico.DemandaHorizonte-z*pronostico.DesviacionHorizonte)
+	pronostico.LimiteSuperior = pronostico.DemandaHorizonte + z*pronostico.DesviacionHorizonte
+
+	return pronostico, nil
+}
+
+// ProbabilidadSuperar estima la probabilidad de que la demanda del horizonte supere la cantidad
+func (p *PronosticoDemanda) ProbabilidadSuperar(cantidad float64) float64 {
+	if p.DesviacionHorizonte == 0 {
+		if p.DemandaHorizonte > cantidad {
+			return 1
+		}
+		return 0
+	}
+	return 0.5 * math.Erfc((cantidad-p.DemandaHorizonte)/(p.DesviacionHorizonte*math.Sqrt2))
+}
+
+// evaluarMetodo pronostica cada día desde origen con los días anteriores y mide el error
+func evaluarMetodo(metodo MetodoPronostico, serie []float64, origen int, parametros ParametrosPronostico) MetricasPronostico {
+	metricas := MetricasPronostico{Metodo: metodo}
+
+	var sumaAbs, sumaCuad, sumaErr, sumaPorc float64
+	conDemanda := 0
+	for t := origen; t < len(serie); t++ {
+		pronostico := proyectar(metodo, serie[:t], 1, parametros)[0]
+		err := serie[t] - pronostico
+
+		sumaAbs += math.Abs(err)
+		sumaCuad += err * err
+		sumaErr += err
+		if serie[t] != 0 {
+			sumaPorc += math.Abs(err / serie[t])
+			conDemanda++
+		}
+		metricas.Observaciones++
+	}
+
+	n := float64(metricas.Observaciones)
+	metricas.MAE = sumaAbs / n
+	metricas.RMSE = math.Sqrt(sumaCuad / n)
+	metricas.Sesgo = sumaErr / n
+	if conDemanda > 0 {
+		mape := sumaPorc / float64(conDemanda) * 100
+		metricas.MAPE = &mape
+	}
+
+	return metricas
+}
+
+// proyectar pronostica los próximos horizonte días a partir del historial
+func proyectar(metodo MetodoPronostico, historia []float64, horizonte int, parametros ParametrosPronostico) []float64 {
+	resultado := make([]float64, horizonte)
+
+	switch metodo {
+	case MetodoPromedioMovil:
+		ventana := parametros.Ventana
+		if ventana > len(historia) {
+			ventana = len(historia)
+		}
+		suma := 0.0
+		for _, y := range historia[len(historia)-ventana:] {
+			suma += y
+		}
+		for h := range resultado {
+			resultado[h] = suma / float64(ventana)
+		}
+
+	case MetodoSuavizadoExponencial:
+		nivel := historia[0]
+		for _, y := range historia[1:] {
+			nivel = parametros.Alfa*y + (1-parametros.Alfa)*nivel
+		}
+		for h := range resultado {
+			resultado[h] = nivel
+		}
+
+	case MetodoIngenuoEstacional:
+		temporada := historia[len(historia)-parametros.Temporada:]
+		for h := range resultado {
+			resultado[h] = temporada[h%parametros.Temporada]
+		}
+	}
+
+	return resultado
+}
+
+// inicioDia trunca una fecha al comienzo de su día en UTC
+func inicioDia(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
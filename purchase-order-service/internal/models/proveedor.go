@@ -0,0 +1,277 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Estados de proveedor y disponibilidad tal como los publica supplier-service
+const (
+	EstadoProveedorActivo        = "ACTIVO"
+	EstadoProveedorSuspendido    = "SUSPENDIDO"
+	EstadoOfertaDisponible       = "DISPONIBLE"
+	EstadoCertificacionActiva    = "ACTIVA"
+	EstadoCertificacionPorVencer = "POR_VENCER"
+)
+
+// Proveedor es la vista de un proveedor de supplier-service que usa este servicio para
+// elegir a quién comprar
+type Proveedor struct {
+	ProveedorID           string                 `json:"proveedor_id" dynamodbav:"proveedor_id"`
+	NombreLegal           string                 `json:"nombre_legal" dynamodbav:"nombre_legal"`
+	RazonSocial           string                 `json:"razon_social,omitempty" dynamodbav:"razon_social,omitempty"`
+	IdentificacionFiscal  string                 `json:"identificacion_fiscal,omitempty" dynamodbav:"identificacion_fiscal,omitempty"`
+	Contactos             []ContactoProveedor    `json:"contactos,omitempty" dynamodbav:"contactos,omitempty"`
+	EstadoProveedor       string                 `json:"estado_proveedor" dynamodbav:"estado_proveedor"`
+	ProductosOfrecidos    []ProductoOfrecido     `json:"productos_ofrecidos" dynamodbav:"productos_ofrecidos"`
+	Certificaciones       []Certificacion        `json:"certificaciones" dynamodbav:"certificaciones"`
+	EvaluacionRendimiento *EvaluacionRendimiento `json:"evaluacion_rendimiento" dynamodbav:"evaluacion_rendimiento"`
+	CapacidadLogistica    *CapacidadLogistica    `json:"capacidad_logistica" dynamodbav:"capacidad_logistica"`
+}
+
+// ContactoProveedor es una persona de contacto del proveedor
+type ContactoProveedor struct {
+	Nombre              string `json:"nombre" dynamodbav:"nombre"`
+	Email               string `json:"email" dynamodbav:"email"`
+	Telefono            string `json:"telefono" dynamodbav:"telefono"`
+	Cargo               string `json:"cargo" dynamodbav:"cargo"`
+	EsContactoPrincipal bool   `json:"es_contacto_principal" dynamodbav:"es_contacto_principal"`
+}
+
+// ProductoOfrecido es un producto del catálogo de un proveedor
+type ProductoOfrecido struct {
+	ProductoID           string  `json:"producto_id" dynamodbav:"producto_id"`
+	CodigoProveedor      string  `json:"codigo_proveedor" dynamodbav:"codigo_proveedor"`
+	PrecioBase           float64 `json:"precio_base" dynamodbav:"precio_base"`
+	Moneda               string  `json:"moneda" dynamodbav:"moneda"`
+	EstadoDisponibilidad string  `json:"estado_disponibilidad" dynamodbav:"estado_disponibilidad"`
+
+	// Precio pactado por contrato; reemplaza al precio base mientras el contrato esté vigente
+	PrecioContratado     float64    `json:"precio_contratado,omitempty" dynamodbav:"precio_contratado,omitempty"`
+	ContratoVigenteHasta *time.Time `json:"contrato_vigente_hasta,omitempty" dynamodbav:"contrato_vigente_hasta,omitempty"`
+}
+
+// Certificacion es una certificación vigente o vencida de un proveedor
+type Certificacion struct {
+	TipoCertificacion string    `json:"tipo_certificacion" dynamodbav:"tipo_certificacion"`
+	FechaVencimiento  time.Time `json:"fecha_vencimiento" dynamodbav:"fecha_vencimiento"`
+	Estado            string    `json:"estado" dynamodbav:"estado"`
+}
+
+// EvaluacionRendimiento es la evaluación de desempeño que mantiene supplier-service
+type EvaluacionRendimiento struct {
+	ScoreGeneral       float64 `json:"score_general" dynamodbav:"score_general"`
+	CumplimientoPlazos float64 `json:"cumplimiento_plazos" dynamodbav:"cumplimiento_plazos"`
+	CalidadProductos   float64 `json:"calidad_productos" dynamodbav:"calidad_productos"`
+}
+
+// CapacidadLogistica es la capacidad de almacenamiento y entrega de un proveedor
+type CapacidadLogistica struct {
+	CapacidadCadenaFrio   bool    `json:"capacidad_cadena_frio" dynamodbav:"capacidad_cadena_frio"`
+	TemperaturaMinima     float64 `json:"temperatura_minima" dynamodbav:"temperatura_minima"`
+	TemperaturaMaxima     float64 `json:"temperatura_maxima" dynamodbav:"temperatura_maxima"`
+	TiempoEntregaPromedio int     `json:"tiempo_entrega_promedio" dynamodbav:"tiempo_entrega_promedio"`
+}
+
+// CertificacionesVigentes retorna los tipos de certificación activos y no vencidos
+func (p *Proveedor) CertificacionesVigentes(ahora time.Time) []string {
+	vigentes := []string{}
+	for _, cert := range p.Certificaciones {
+		if cert.Estado != EstadoCertificacionActiva && cert.Estado != EstadoCertificacionPorVencer {
+			continue
+		}
+		if !cert.FechaVencimiento.IsZero() && !ahora.Before(cert.FechaVencimiento) {
+			continue
+		}
+		vigentes = append(vigentes, cert.TipoCertificacion)
+	}
+	return vigentes
+}
+
+// Calificado indica si el proveedor está activo y tiene al menos una certificación vigente
+func (p *Proveedor) Calificado(ahora time.Time) bool {
+	return p.EstadoProveedor == EstadoProveedorActivo && len(p.CertificacionesVigentes(ahora)) > 0
+}
+
+// Oferta retorna el producto ofrecido disponible para el ID indicado (nil si no lo ofrece)
+func (p *Proveedor) Oferta(productoID string) *ProductoOfrecido {
+	for i := range p.ProductosOfrecidos {
+		oferta := &p.ProductosOfrecidos[i]
+		if oferta.ProductoID == productoID && oferta.EstadoDisponibilidad == EstadoOfertaDisponible {
+			return oferta
+		}
+	}
+	return nil
+}
+
+// CubreCondiciones indica si la logística del proveedor mantiene las condiciones del producto
+func (p *Proveedor) CubreCondiciones(condiciones *Condiciones) bool {
+	if condiciones == nil || !condiciones.CadenaFrioRequerida {
+		return true
+	}
+	logistica := p.CapacidadLogistica
+	return logistica != nil && logistica.CapacidadCadenaFrio &&
+		logistica.TemperaturaMinima <= condiciones.TemperaturaMinima &&
+		logistica.TemperaturaMaxima >= condiciones.TemperaturaMaxima
+}
+
+// PuedeAbastecer indica si el proveedor ofrece todos los productos y cubre sus condiciones
+func (p *Proveedor) PuedeAbastecer(productos []*Producto) bool {
+	for _, producto := range productos {
+		if p.Oferta(producto.ProductoID) == nil || !p.CubreCondiciones(producto.Condiciones) {
+			return false
+		}
+	}
+	return true
+}
+
+// NuevaEvaluacion arma la evaluación que se guarda en la orden con los datos del proveedor
+func (p *Proveedor) NuevaEvaluacion(ahora time.Time) *Evaluacion {
+	evaluacion := &Evaluacion{
+		ProveedorID:     p.ProveedorID,
+		Certificaciones: p.CertificacionesVigentes(ahora),
+		FechaEvaluacion: ahora,
+	}
+	if p.EvaluacionRendimiento != nil {
+		evaluacion.ScoreCalidad = p.EvaluacionRendimiento.CalidadProductos
+	}
+	if p.CapacidadLogistica != nil {
+		evaluacion.TiempoEntregaPromedio = float64(p.CapacidadLogistica.TiempoEntregaPromedio)
+		evaluacion.CapacidadCadenaFrio = p.CapacidadLogistica.CapacidadCadenaFrio
+	}
+	return evaluacion
+}
+
+// SeleccionarProveedor elige, entre los proveedores calificados que pueden abastecer todos los
+// productos, el de mejor score general; a igual score, el que entrega antes. Retorna nil si
+// ninguno califica.
+func SeleccionarProveedor(proveedores []*Proveedor, productos []*Producto, ahora time.Time) *Proveedor {
+	candidatos := []*Proveedor{}
+	for _, proveedor := range proveedores {
+		if proveedor.Calificado(ahora) && proveedor.PuedeAbastecer(productos) {
+			candidatos = append(candidatos, proveedor)
+		}
+	}
+	if len(candidatos) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(candidatos, func(i, j int) bool {
+		a, b := candidatos[i], candidatos[j]
+		if sa, sb := a.scoreGeneral(), b.scoreGeneral(); sa != sb {
+			return sa > sb
+		}
+		if ta, tb := a.tiempoEntrega(), b.tiempoEntrega(); ta != tb {
+			return ta < tb
+		}
+		return a.ProveedorID < b.ProveedorID
+	})
+
+	return candidatos[0]
+}
+
+// scoreGeneral retorna el score general del proveedor (0 si no fue evaluado)
+func (p *Proveedor) scoreGeneral() float64 {
+	if p.EvaluacionRendimiento == nil {
+		return 0
+	}
+	return p.EvaluacionRendimiento.ScoreGeneral
+}
+
+// tiempoEntrega retorna el tiempo de entrega promedio; sin datos cuenta como el más lento
+func (p *Proveedor) tiempoEntrega() int {
+	if p.CapacidadLogistica == nil || p.CapacidadLogistica.TiempoEntregaPromedio <= 0 {
+		return int(^uint(0) >> 1)
+	}
+	return p.CapacidadLogistica.TiempoEntregaPromedio
+}
+
+// ProveedorProyectado es la copia local del estado de un proveedor que se mantiene con los
+// eventos de supplier-service, para validar órdenes sin consultarlo
+type ProveedorProyectado struct {
+	ProveedorID         string    `json:"proveedor_id" dynamodbav:"proveedor_id"`
+	NombreLegal         string    `json:"nombre_legal" dynamodbav:"nombre_legal"`
+	EstadoProveedor     string    `json:"estado_proveedor" dynamodbav:"estado_proveedor"`
+	ScoreGeneral        float64   `json:"score_general" dynamodbav:"score_general"`
+	Certificaciones     []string  `json:"certificaciones" dynamodbav:"certificaciones"`
+	CapacidadCadenaFrio bool      `json:"capacidad_cadena_frio" dynamodbav:"capacidad_cadena_frio"`
+	UltimoEventoID      string    `json:"ultimo_evento_id,omitempty" dynamodbav:"ultimo_evento_id,omitempty"`
+	FechaUltimoEvento   time.Time `json:"fecha_ultimo_evento" dynamodbav:"fecha_ultimo_evento"`
+	UpdatedAt           time.Time `json:"updated_at" dynamodbav:"updated_at"`
+
+	// Rango de temperatura validado de la cadena de frío y catálogo con precios del proveedor
+	TemperaturaMinima  float64            `json:"temperatura_minima" dynamodbav:"temperatura_minima"`
+	TemperaturaMaxima  float64            `json:"temperatura_maxima" dynamodbav:"temperatura_maxima"`
+	ProductosOfrecidos []ProductoOfrecido `json:"productos_ofrecidos,omitempty" dynamodbav:"productos_ofrecidos,omitempty"`
+}
+
+// NuevaProyeccionProveedor arma la proyección de un proveedor leído de supplier-service
+func NuevaProyeccionProveedor(p *Proveedor, ahora time.Time) *ProveedorProyectado {
+	proyeccion := &ProveedorProyectado{
+		ProveedorID:        p.ProveedorID,
+		NombreLegal:        p.NombreLegal,
+		EstadoProveedor:    p.EstadoProveedor,
+		Certificaciones:    p.CertificacionesVigentes(ahora),
+		FechaUltimoEvento:  ahora,
+		UpdatedAt:          ahora,
+		ProductosOfrecidos: p.ProductosOfrecidos,
+	}
+	if p.EvaluacionRendimiento != nil {
+		proyeccion.ScoreGeneral = p.EvaluacionRendimiento.ScoreGeneral
+	}
+	if p.CapacidadLogistica != nil {
+		proyeccion.CapacidadCadenaFrio = p.CapacidadLogistica.CapacidadCadenaFrio
+		proyeccion.TemperaturaMinima = p.CapacidadLogistica.TemperaturaMinima
+		proyeccion.TemperaturaMaxima = p.CapacidadLogistica.TemperaturaMaxima
+	}
+	return proyeccion
+}
+
+// Activo indica si el proveedor puede recibir órdenes
+func (p *ProveedorProyectado) Activo() bool {
+	return p.EstadoProveedor == EstadoProveedorActivo
+}
+
+// CubreRangoTemperatura indica si el rango validado de la cadena de frío del proveedor cubre el
+// rango requerido y, si no lo cubre, el motivo
+func (p *ProveedorProyectado) CubreRangoTemperatura(tempMin, tempMax float64) (bool, string) {
+	if !p.CapacidadCadenaFrio {
+		return false, "el proveedor no tiene capacidad de cadena de frío"
+	}
+	if p.TemperaturaMinima > p.TemperaturaMaxima {
+		return false, "el rango de temperatura del proveedor es inválido"
+	}
+	if tempMin < p.TemperaturaMinima {
+		return false, fmt.Sprintf("la temperatura mínima requerida (%.1f°C) es inferior a la mínima del proveedor (%.1f°C)", tempMin, p.TemperaturaMinima)
+	}
+	if tempMax > p.TemperaturaMaxima {
+		return false, fmt.Sprintf("la temperatura máxima requerida (%.1f°C) es superior a la máxima del proveedor (%.1f°C)", tempMax, p.TemperaturaMaxima)
+	}
+	return true, ""
+}
+
+// Desactualizado indica si un evento de la fecha dada es anterior al último aplicado
+func (p *ProveedorProyectado) Desactualizado(fechaEvento time.Time) bool {
+	return fechaEvento.Before(p.FechaUltimoEvento)
+}
+
+// ResultadoReconstruccion resume la reconstrucción de la proyección de proveedores
+type ResultadoReconstruccion struct {
+	Actualizados int `json:"actualizados"`
+	Eliminados   int `json:"eliminados"`
+	Omitidos     int `json:"omitidos"`
+}
+
+// ContactoPrincipal retorna el contacto principal del proveedor o, si no marcó ninguno, el primero
+func (p *Proveedor) ContactoPrincipal() *ContactoProveedor {
+	for i := range p.Contactos {
+		if p.Contactos[i].EsContactoPrincipal {
+			return &p.Contactos[i]
+		}
+	}
+	if len(p.Contactos) > 0 {
+		return &p.Contactos[0]
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrRecepcionInvalida se retorna cuando las líneas de una recepción no son consistentes con la orden
+var ErrRecepcionInvalida = errors.New("recepción inválida")
+
+// Recepcion representa una entrega física del proveedor contra una orden de compra
+type Recepcion struct {
+	RecepcionID    string    `json:"recepcion_id" dynamodbav:"recepcion_id"`
+	FechaRecepcion time.Time `json:"fecha_recepcion" dynamodbav:"fecha_recepcion"`
+	RecibidoPor    string    `json:"recibido_por" dynamodbav:"recibido_por"`
+	// PermitirExcederMaximo autoriza que la entrada deje el stock por encima de StockMaximo
+	PermitirExcederMaximo bool             `json:"permitir_exceder_maximo" dynamodbav:"permitir_exceder_maximo"`
+	Lineas                []LineaRecepcion `json:"lineas" dynamodbav:"lineas"`
+}
+
+// LineaRecepcion registra lo recibido y lo rechazado de un item en una entrega
+type LineaRecepcion struct {
+	ItemID            string     `json:"item_id" dynamodbav:"item_id"`
+	CantidadRecibida  int        `json:"cantidad_recibida" dynamodbav:"cantidad_recibida"`
+	CantidadRechazada int        `json:"cantidad_rechazada" dynamodbav:"cantidad_rechazada"`
+	MotivoRechazo     string     `json:"motivo_rechazo,omitempty" dynamodbav:"motivo_rechazo,omitempty"`
+	Lote              string     `json:"lote,omitempty" dynamodbav:"lote,omitempty"`
+	FechaVencimiento  *time.Time `json:"fecha_vencimiento,omitempty" dynamodbav:"fecha_vencimiento,omitempty"`
+}
+
+// CantidadPendiente retorna lo que falta por recibir del item
+func (i *ItemOrdenCompra) CantidadPendiente() int {
+	if i.EstadoItem == EstadoItemCancelado {
+		return 0
+	}
+	if pendiente := i.CantidadSolicitada - i.CantidadRecibida; pendiente > 0 {
+		return pendiente
+	}
+	return 0
+}
+
+// RecepcionPendiente arma una recepción con todo lo que falta por recibir de cada item
+func (o *OrdenCompra) RecepcionPendiente() *Recepcion {
+	recepcion := &Recepcion{}
+	for _, item := range o.Items {
+		if pendiente := item.CantidadPendiente(); pendiente > 0 {
+			recepcion.Lineas = append(recepcion.Lineas, LineaRecepcion{
+				ItemID:           item.ItemID,
+				CantidadRecibida: pendiente,
+			})
+		}
+	}
+	return recepcion
+}
+
+// TieneRecepcion indica si la recepción con el ID indicado ya fue aplicada a la orden
+func (o *OrdenCompra) TieneRecepcion(recepcionID string) bool {
+	for _, r := range o.Recepciones {
+		if r.RecepcionID == recepcionID {
+			return true
+		}
+	}
+	return false
+}
+
+// CantidadesPorProducto suma las unidades aceptadas de la recepción por producto
+func (o *OrdenCompra) CantidadesPorProducto(recepcion *Recepcion) map[string]int {
+	cantidades := map[string]int{}
+	for _, linea := range recepcion.Lineas {
+		if item := o.BuscarItem(linea.ItemID); item != nil && linea.CantidadRecibida > 0 {
+			cantidades[item.ProductoID] += linea.CantidadRecibida
+		}
+	}
+	return cantidades
+}
+
+// LotesPorProducto agrupa las unidades aceptadas de la recepción por producto y número de lote.
+// Las líneas sin número de lote quedan como stock sin lote.
+func (o *OrdenCompra) LotesPorProducto(recepcion *Recepcion) map[string][]MovimientoLote {
+	lotes := map[string][]MovimientoLote{}
+	for _, linea := range recepcion.Lineas {
+		item := o.BuscarItem(linea.ItemID)
+		if item == nil || linea.CantidadRecibida <= 0 || linea.Lote == "" {
+			continue
+		}
+
+		agregado := false
+		for i := range lotes[item.ProductoID] {
+			if lotes[item.ProductoID][i].NumeroLote == linea.Lote {
+				lotes[item.ProductoID][i].Cantidad += linea.CantidadRecibida
+				agregado = true
+				break
+			}
+		}
+		if !agregado {
+			lotes[item.ProductoID] = append(lotes[item.ProductoID], MovimientoLote{
+				NumeroLote:       linea.Lote,
+				Cantidad:         linea.CantidadRecibida,
+				FechaVencimiento: linea.FechaVencimiento,
+				OrdenID:          o.OrdenID,
+				ProveedorID:      o.ProveedorID,
+			})
+		}
+	}
+	return lotes
+}
+
+// BuscarItem retorna el item de la orden con el ID indicado
+func (o *OrdenCompra) BuscarItem(itemID string) *ItemOrdenCompra {
+	for i := range o.Items {
+		if o.Items[i].ItemID == itemID {
+			return &o.Items[i]
+		}
+	}
+	return nil
+}
+
+// RegistrarRecepcion aplica una entrega a los items de la orden. Las cantidades rechazadas
+// no cuentan como recibidas, de modo que el item sigue pendiente hasta que se reponga.
+// La orden queda PARCIALMENTE_RECIBIDA o, si todos los items se completaron, RECIBIDA.
+func (o *OrdenCompra) RegistrarRecepcion(recepcion *Recepcion) error {
+	if len(recepcion.Lineas) == 0 {
+		return fmt.Errorf("%w: la recepción no tiene líneas", ErrRecepcionInvalida)
+	}
+
+	// Validar todas las líneas antes de modificar la orden
+	recibidoPorItem := map[string]int{}
+	for _, linea := range recepcion.Lineas {
+		item := o.BuscarItem(linea.ItemID)
+		if item == nil {
+			return fmt.Errorf("%w: el item %s no pertenece a la orden", ErrRecepcionInvalida, linea.ItemID)
+		}
+		if item.EstadoItem == EstadoItemCancelado {
+			return fmt.Errorf("%w: el item %s está cancelado", ErrRecepcionInvalida, linea.ItemID)
+		}
+		if linea.CantidadRecibida < 0 || linea.CantidadRechazada < 0 {
+			return fmt.Errorf("%w: las cantidades no pueden ser negativas (item %s)", ErrRecepcionInvalida, linea.ItemID)
+		}
+		if linea.CantidadRecibida == 0 && linea.CantidadRechazada == 0 {
+			return fmt.Errorf("%w: la línea del item %s no tiene cantidades", ErrRecepcionInvalida, linea.ItemID)
+		}
+		if linea.CantidadRechazada > 0 && linea.MotivoRechazo == "" {
+			return fmt.Errorf("%w: el rechazo del item %s requiere un motivo", ErrRecepcionInvalida, linea.ItemID)
+		}
+		recibidoPorItem[linea.ItemID] += linea.CantidadRecibida
+		if recibidoPorItem[linea.ItemID] > item.CantidadPendiente() {
+			return fmt.Errorf("%w: se reciben %d unidades del item %s y solo quedan %d pendientes",
+				ErrRecepcionInvalida, recibidoPorItem[linea.ItemID], linea.ItemID, item.CantidadPendiente())
+		}
+	}
+
+	// El estado destino depende de si la entrega completa todos los items
+	completa := true
+	for _, item := range o.Items {
+		if item.CantidadPendiente() > recibidoPorItem[item.ItemID] {
+			completa = false
+			break
+		}
+	}
+
+	destino := EstadoParcialmenteRecibida
+	if completa {
+		destino = EstadoRecibida
+	}
+	if err := o.transicionar(destino); err != nil {
+		return err
+	}
+
+	for _, linea := range recepcion.Lineas {
+		item := o.BuscarItem(linea.ItemID)
+		item.CantidadRecibida += linea.CantidadRecibida
+		item.CantidadRechazada += linea.CantidadRechazada
+		if item.CantidadPendiente() == 0 {
+			item.EstadoItem = EstadoItemRecibido
+		} else if item.CantidadRecibida > 0 {
+			item.EstadoItem = EstadoItemParcialmenteRecibido
+		}
+	}
+
+	if recepcion.RecepcionID == "" {
+		recepcion.RecepcionID = uuid.New().String()
+	}
+	if recepcion.FechaRecepcion.IsZero() {
+		recepcion.FechaRecepcion = time.Now()
+	}
+	o.Recepciones = append(o.Recepciones, *recepcion)
+
+	return nil
+}
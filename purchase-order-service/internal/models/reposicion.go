@@ -0,0 +1,90 @@
+package models
+
+// MotivoOmision explica por qué un producto bajo su punto de reorden no entró en la reposición
+type MotivoOmision string
+
+const (
+	OmisionSinCantidad        MotivoOmision = "SIN_CANTIDAD_A_PEDIR"
+	OmisionPoliticaIncompleta MotivoOmision = "POLITICA_INCOMPLETA"
+	OmisionOrdenPendiente     MotivoOmision = "ORDEN_PENDIENTE"
+	OmisionSinProveedor       MotivoOmision = "SIN_PROVEEDOR_CALIFICADO"
+	OmisionOrdenNoCreada      MotivoOmision = "ORDEN_NO_CREADA"
+	OmisionProveedorRechazado MotivoOmision = "PROVEEDOR_RECHAZADO"
+	OmisionSinPresupuesto     MotivoOmision = "SIN_PRESUPUESTO"
+	OmisionSinPrecio          MotivoOmision = "SIN_PRECIO"
+)
+
+// ProductoOmitido es un producto que la reposición no pudo pedir
+type ProductoOmitido struct {
+	ProductoID string        `json:"producto_id"`
+	Nombre     string        `json:"nombre"`
+	Motivo     MotivoOmision `json:"motivo"`
+	Detalle    string        `json:"detalle,omitempty"`
+}
+
+// OrdenReposicion resume una orden creada por la reposición
+type OrdenReposicion struct {
+	OrdenID     string    `json:"orden_id"`
+	NumeroOrden string    `json:"numero_orden"`
+	ProveedorID string    `json:"proveedor_id"`
+	Prioridad   Prioridad `json:"prioridad"`
+	Productos   []string  `json:"productos"`
+}
+
+// ResumenReposicion es el resultado de una corrida de reposición
+type ResumenReposicion struct {
+	Trigger           string            `json:"trigger"`
+	OrdenesCreadas    []OrdenReposicion `json:"ordenes_creadas"`
+	ProductosOmitidos []ProductoOmitido `json:"productos_omitidos"`
+}
+
+// NuevoResumenReposicion crea un resumen vacío para el trigger indicado
+func NuevoResumenReposicion(trigger string) *ResumenReposicion {
+	return &ResumenReposicion{
+		Trigger:           trigger,
+		OrdenesCreadas:    []OrdenReposicion{},
+		ProductosOmitidos: []ProductoOmitido{},
+	}
+}
+
+// Omitir registra un producto que no se pidió
+func (r *ResumenReposicion) Omitir(producto *Producto, motivo MotivoOmision, detalle string) {
+	r.ProductosOmitidos = append(r.ProductosOmitidos, ProductoOmitido{
+		ProductoID: producto.ProductoID,
+		Nombre:     producto.Nombre,
+		Motivo:     motivo,
+		Detalle:    detalle,
+	})
+}
+
+// RegistrarOrden agrega una orden creada al resumen
+func (r *ResumenReposicion) RegistrarOrden(orden *OrdenCompra) {
+	productos := make([]string, 0, len(orden.Items))
+	for _, item := range orden.Items {
+		productos = append(productos, item.ProductoID)
+	}
+
+	r.OrdenesCreadas = append(r.OrdenesCreadas, OrdenReposicion{
+		OrdenID:     orden.OrdenID,
+		NumeroOrden: orden.NumeroOrden,
+		ProveedorID: orden.ProveedorID,
+		Prioridad:   orden.Prioridad,
+		Productos:   productos,
+	})
+}
+
+// nivelPrioridad ordena las prioridades de menor a mayor urgencia
+var nivelPrioridad = map[Prioridad]int{
+	PrioridadBaja:    1,
+	PrioridadMedia:   2,
+	PrioridadAlta:    3,
+	PrioridadCritica: 4,
+}
+
+// MayorPrioridad retorna la más urgente de dos prioridades
+func MayorPrioridad(a, b Prioridad) Prioridad {
+	if nivelPrioridad[b] > nivelPrioridad[a] {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrLecturaInvalida se retorna cuando una lectura de temperatura está incompleta
+var ErrLecturaInvalida = errors.New("lectura de temperatura inválida")
+
+// EstadoExcursion representa el estado de una excursión de temperatura
+type EstadoExcursion string
+
+const (
+	EstadoExcursionAbierta      EstadoExcursion = "ABIERTA"
+	EstadoExcursionComprometida EstadoExcursion = "COMPROMETIDA"
+)
+
+// ResultadoLectura indica qué produjo una lectura al evaluarla contra las condiciones del producto
+type ResultadoLectura string
+
+const (
+	LecturaEnRango             ResultadoLectura = "EN_RANGO"
+	LecturaExcursionAbierta    ResultadoLectura = "EXCURSION_ABIERTA"
+	LecturaLoteComprometido    ResultadoLectura = "LOTE_COMPROMETIDO"
+	LecturaYaComprometida      ResultadoLectura = "LOTE_YA_COMPROMETIDO"
+	LecturaFueraDeOrden        ResultadoLectura = "FUERA_DE_ORDEN"
+	LecturaSinCondiciones      ResultadoLectura = "SIN_CONDICIONES"
+	LecturaProductoDesconocido ResultadoLectura = "PRODUCTO_DESCONOCIDO"
+)
+
+// LecturaTemperatura es una medición de un sensor para un lote en una unidad de almacenamiento
+type LecturaTemperatura struct {
+	UnidadAlmacenamiento string    `json:"unidad_almacenamiento"`
+	SensorID             string    `json:"sensor_id,omitempty"`
+	ProductoID           string    `json:"producto_id"`
+	NumeroLote           string    `json:"numero_lote"`
+	Temperatura          float64   `json:"temperatura"`
+	FechaLectura         time.Time `json:"fecha_lectura"`
+}
+
+// Validar verifica que la lectura identifique la unidad, el producto y el lote
+func (l *LecturaTemperatura) Validar() error {
+	if l.UnidadAlmacenamiento == "" {
+		return fmt.Errorf("%w: la unidad de almacenamiento es obligatoria", ErrLecturaInvalida)
+	}
+	if l.ProductoID == "" || l.NumeroLote == "" {
+		return fmt.Errorf("%w: el producto y el lote son obligatorios", ErrLecturaInvalida)
+	}
+	if math.IsNaN(l.Temperatura) || math.IsInf(l.Temperatura, 0) {
+		return fmt.Errorf("%w: temperatura no numérica", ErrLecturaInvalida)
+	}
+	return nil
+}
+
+// ReglasExcursion define cuándo una excursión compromete un lote: al durar DuracionMaxima
+// fuera de rango o, de inmediato, al alejarse del rango DesviacionCritica grados o más
+// (0 desactiva la regla de desviación)
+type ReglasExcursion struct {
+	DuracionMaxima    time.Duration
+	DesviacionCritica float64
+}
+
+// ExcursionTemperatura registra el periodo continuo en que un lote estuvo fuera de rango en
+// una unidad de almacenamiento. Una excursión comprometida se conserva para no repetir el aviso.
+type ExcursionTemperatura struct {
+	ProductoID           string          `json:"producto_id" dynamodbav:"producto_id"`
+	UnidadLote           string          `json:"unidad_lote" dynamodbav:"unidad_lote"`
+	UnidadAlmacenamiento string          `json:"unidad_almacenamiento" dynamodbav:"unidad_almacenamiento"`
+	NumeroLote           string          `json:"numero_lote" dynamodbav:"numero_lote"`
+	TemperaturaMinima    float64         `json:"temperatura_minima" dynamodbav:"temperatura_minima"`
+	TemperaturaMaxima    float64         `json:"temperatura_maxima" dynamodbav:"temperatura_maxima"`
+	TemperaturaExtrema   float64         `json:"temperatura_extrema" dynamodbav:"temperatura_extrema"`
+	UltimaTemperatura    float64         `json:"ultima_temperatura" dynamodbav:"ultima_temperatura"`
+	Lecturas             int             `json:"lecturas" dynamodbav:"lecturas"`
+	Inicio               time.Time       `json:"inicio" dynamodbav:"inicio"`
+	UltimaLectura        time.Time       `json:"ultima_lectura" dynamodbav:"ultima_lectura"`
+	Estado               EstadoExcursion `json:"estado" dynamodbav:"estado"`
+	FechaCompromiso      *time.Time      `json:"fecha_compromiso,omitempty" dynamodbav:"fecha_compromiso,omitempty"`
+
+	// BajaPendiente indica que el lote comprometido aún no se dio de baja en inventario
+	BajaPendiente bool `json:"baja_pendiente,omitempty" dynamodbav:"baja_pendiente,omitempty"`
+}
+
+// ClaveUnidadLote arma la clave de rango de una excursión (unidad + lote)
+func ClaveUnidadLote(unidad, numeroLote string) string {
+	return unidad + "#" + numeroLote
+}
+
+// Duracion retorna cuánto lleva la excursión según las lecturas recibidas
+func (e *ExcursionTemperatura) Duracion() time.Duration {
+	return e.UltimaLectura.Sub(e.Inicio)
+}
+
+// Desviacion retorna cuántos grados se alejó del rango la temperatura más extrema
+func (e *ExcursionTemperatura) Desviacion() float64 {
+	return desviacion(e.TemperaturaExtrema, e.TemperaturaMinima, e.TemperaturaMaxima)
+}
+
+// LimiteExcedido retorna el límite del rango que se superó (mínimo o máximo)
+func (e *ExcursionTemperatura) LimiteExcedido() float64 {
+	if e.TemperaturaExtrema < e.TemperaturaMinima {
+		return e.TemperaturaMinima
+	}
+	return e.TemperaturaMaxima
+}
+
+// Motivo describe la excursión con los datos medidos
+func (e *ExcursionTemperatura) Motivo() string {
+	return fmt.Sprintf("Temperatura de %.1f°C fuera del rango %.1f-%.1f°C durante %s en %s",
+		e.TemperaturaExtrema, e.TemperaturaMinima, e.TemperaturaMaxima,
+		e.Duracion().Round(time.Second), e.UnidadAlmacenamiento)
+}
+
+// EvaluarLectura aplica una lectura a la excursión en curso (nil si no la hay) y retorna la
+// excursión resultante (nil si la temperatura volvió al rango) junto con el resultado
+func EvaluarLectura(condiciones *Condiciones, excursion *ExcursionTemperatura, lectura *LecturaTemperatura, reglas ReglasExcursion) (*ExcursionTemperatura, ResultadoLectura) {
+	if excursion != nil && excursion.Estado == EstadoExcursionComprometida {
+		if excursion.BajaPendiente {
+			return excursion, LecturaLoteComprometido
+		}
+		return excursion, LecturaYaComprometida
+	}
+	if excursion != nil && lectura.FechaLectura.Before(excursion.UltimaLectura) {
+		return excursion, LecturaFueraDeOrden
+	}
+
+	minima, maxima := condiciones.TemperaturaMinima, condiciones.TemperaturaMaxima
+	if desviacion(lectura.Temperatura, minima, maxima) == 0 {
+		return nil, LecturaEnRango
+	}
+
+	if excursion == nil {
+		excursion = &ExcursionTemperatura{
+			ProductoID:           lectura.ProductoID,
+			UnidadLote:           ClaveUnidadLote(lectura.UnidadAlmacenamiento, lectura.NumeroLote),
+			UnidadAlmacenamiento: lectura.UnidadAlmacenamiento,
+			NumeroLote:           lectura.NumeroLote,
+			TemperaturaExtrema:   lectura.Temperatura,
+			Inicio:               lectura.FechaLectura,
+			Estado:               EstadoExcursionAbierta,
+		}
+	}
+
+	excursion.TemperaturaMinima = minima
+	excursion.TemperaturaMaxima = maxima
+	if desviacion(lectura.Temperatura, minima, maxima) > excursion.Desviacion() {
+		excursion.TemperaturaExtrema = lectura.Temperatura
+	}
+	excursion.UltimaTemperatura = lectura.Temperatura
+	excursion.UltimaLectura = lectura.FechaLectura
+	excursion.Lecturas++
+
+	critica := reglas.DesviacionCritica > 0 && excursion.Desviacion() >= reglas.DesviacionCritica
+	if critica || excursion.Duracion() >= reglas.DuracionMaxima {
+		fecha := lectura.FechaLectura
+		excursion.Estado = EstadoExcursionComprometida
+		excursion.FechaCompromiso = &fecha
+		excursion.BajaPendiente = true
+		return excursion, LecturaLoteComprometido
+	}
+
+	return excursion, LecturaExcursionAbierta
+}
+
+// desviacion retorna cuántos grados queda una temperatura fuera del rango (0 si está dentro)
+func desviacion(temperatura, minima, maxima float64) float64 {
+	switch {
+	case temperatura < minima:
+		return minima - temperatura
+	case temperatura > maxima:
+		return temperatura - maxima
+	default:
+		return 0
+	}
+}
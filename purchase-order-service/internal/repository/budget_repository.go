@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"mediplus/purchase-order-service/internal/database"
+	"mediplus/purchase-order-service/internal/models"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrPresupuestoNoEncontrado se retorna cuando el centro de costo no tiene presupuesto para el período
+	ErrPresupuestoNoEncontrado = errors.New("budget not found")
+	// ErrPresupuestoExistente se retorna al crear un presupuesto que ya existe
+	ErrPresupuestoExistente = errors.New("budget already exists")
+	// ErrPresupuestoInsuficiente se retorna cuando el disponible no cubre la reserva o la reducción
+	ErrPresupuestoInsuficiente = errors.New("insufficient budget")
+	// ErrPresupuestoModificado se retorna cuando el monto asignado cambió desde que fue leído
+	ErrPresupuestoModificado = errors.New("budget was modified concurrently")
+)
+
+// BudgetRepository define la interfaz para el repositorio de presupuestos
+type BudgetRepository interface {
+	Create(presupuesto *models.Presupuesto) error
+	Get(centroCostoID, periodo string) (*models.Presupuesto, error)
+	ListByCentroCosto(centroCostoID string) ([]*models.Presupuesto, error)
+	ListAll() ([]*models.Presupuesto, error)
+	UpdateAsignado(presupuesto *models.Presupuesto, montoAsignado models.Monto) (*models.Presupuesto, error)
+}
+
+// budgetRepository implementa BudgetRepository
+type budgetRepository struct {
+	db  *database.DynamoDBClient
+	log *logrus.Logger
+}
+
+// NewBudgetRepository crea una nueva instancia de BudgetRepository
+func NewBudgetRepository(db *database.DynamoDBClient, log *logrus.Logger) BudgetRepository {
+	return &budgetRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Create crea un presupuesto si el centro de costo aún no tiene uno para el período
+func (r *budgetRepository) Create(presupuesto *models.Presupuesto) error {
+	item, err := dynamodbattribute.MarshalMap(presupuesto)
+	if err != nil {
+		return err
+	}
+
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.AttributeNotExists(expression.Name("centro_costo_id"))).
+		Build()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.GetClient().PutItem(&dynamodb.PutItemInput{
+		TableName:                 aws.String("budgets"),
+		Item:                      item,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		var fallida *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &fallida) {
+			return ErrPresupuestoExistente
+		}
+		r.log.Errorf("Error creating budget: %v", err)
+		return err
+	}
+
+	r.log.Infof("Budget created successfully: %s %s", presupuesto.CentroCostoID, presupuesto.Periodo)
+	return nil
+}
+
+// Get obtiene el presupuesto de un centro de costo para un período
+func (r *budgetRepository) Get(centroCostoID, periodo string) (*models.Presupuesto, error) {
+	result, err := r.db.GetClient().GetItem(&dynamodb.GetItemInput{
+		TableName:      aws.String("budgets"),
+		Key:            clavePresupuesto(centroCostoID, periodo),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		r.log.Errorf("Error getting budget: %v", err)
+		return nil, err
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var presupuesto models.Presupuesto
+	err = dynamodbattribute.UnmarshalMap(result.Item, &presupuesto)
+	if err != nil {
+		r.log.Errorf("Error unmarshaling budget: %v", err)
+		return nil, err
+	}
+
+	return &presupuesto, nil
+}
+
+// ListByCentroCosto lista los presupuestos de un centro de costo ordenados por período
+func (r *budgetRepository) ListByCentroCosto(centroCostoID string) ([]*models.Presupuesto, error) {
+	keyCond := expression.Key("centro_costo_id").Equal(expression.Value(centroCostoID))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCond).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String("budgets"),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}
+
+	presupuestos := []*models.Presupuesto{}
+	err = r.db.GetClient().QueryPages(input, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		presupuestos = append(presupuestos, r.unmarshalPresupuestos(page.Items)...)
+		return true
+	})
+	if err != nil {
+		r.log.Errorf("Error querying budgets: %v", err)
+		return nil, err
+	}
+
+	return presupuestos, nil
+}
+
+// ListAll lista los presupuestos de todos los centros de costo
+func (r *budgetRepository) ListAll() ([]*models.Presupuesto, error) {
+	input := &dynamodb.ScanInput{
+		TableName: aws.String("budgets"),
+	}
+
+	presupuestos := []*models.Presupuesto{}
+	err := r.db.GetClient().ScanPages(input, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		presupuestos = append(presupuestos, r.unmarshalPresupuestos(page.Items)...)
+		return true
+	})
+	if err != nil {
+		r.log.Errorf("Error scanning budgets: %v", err)
+		return nil, err
+	}
+
+	return presupuestos, nil
+}
+
+// UpdateAsignado cambia el monto asignado del presupuesto leído y ajusta el disponible en la
+// misma diferencia. Una reducción exige que el disponible la cubra, de modo que lo comprometido
+// y lo ejecutado nunca superen lo asignado.
+func (r *budgetRepository) UpdateAsignado(presupuesto *models.Presupuesto, montoAsignado models.Monto) (*models.Presupuesto, error) {
+	diferencia := montoAsignado - presupuesto.MontoAsignado
+
+	condicion := expression.Name("monto_asignado").Equal(expression.Value(presupuesto.MontoAsignado))
+	if diferencia < 0 {
+		condicion = condicion.And(expression.Name("monto_disponible").GreaterThanEqual(expression.Value(-diferencia)))
+	}
+	update := expression.Set(expression.Name("monto_asignado"), expression.Value(montoAsignado)).
+		Add(expression.Name("monto_disponible"), expression.Value(diferencia)).
+		Set(expression.Name("updated_at"), expression.Value(time.Now()))
+
+	expr, err := expression.NewBuilder().WithCondition(condicion).WithUpdate(update).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.db.GetClient().UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 aws.String("budgets"),
+		Key:                       clavePresupuesto(presupuesto.CentroCostoID, presupuesto.Periodo),
+		ConditionExpression:       expr.Condition(),
+		UpdateExpression:          expr.Update(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ReturnValues:              aws.String(dynamodb.ReturnValueAllNew),
+	})
+	if err != nil {
+		var fallida *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &fallida) {
+			return nil, r.errorReduccion(presupuesto, diferencia)
+		}
+		r.log.Errorf("Error updating budget: %v", err)
+		return nil, err
+	}
+
+	var actualizado models.Presupuesto
+	if err := dynamodbattribute.UnmarshalMap(result.Attributes, &actualizado); err != nil {
+		r.log.Errorf("Error unmarshaling budget: %v", err)
+		return nil, err
+	}
+
+	return &actualizado, nil
+}
+
+// errorReduccion relee el presupuesto para distinguir un cambio concurrente de un disponible
+// que no cubre la reducción
+func (r *budgetRepository) errorReduccion(presupuesto *models.Presupuesto, diferencia models.Monto) error {
+	actual, err := r.Get(presupuesto.CentroCostoID, presupuesto.Periodo)
+	if err != nil {
+		return err
+	}
+	if actual == nil {
+		return ErrPresupuestoNoEncontrado
+	}
+	if actual.MontoAsignado != presupuesto.MontoAsignado {
+		return ErrPresupuestoModificado
+	}
+	return fmt.Errorf("%w: el disponible (%s) no cubre una reducción de %s",
+		ErrPresupuestoInsuficiente, actual.MontoDisponible, -diferencia)
+}
+
+// unmarshalPresupuestos convierte los items de DynamoDB en presupuestos, descartando los inválidos
+func (r *budgetRepository) unmarshalPresupuestos(items []map[string]*dynamodb.AttributeValue) []*models.Presupuesto {
+	presupuestos := make([]*models.Presupuesto, 0, len(items))
+	for _, item := range items {
+		var presupuesto models.Presupuesto
+		if err := dynamodbattribute.UnmarshalMap(item, &presupuesto); err != nil {
+			r.log.Errorf("Error unmarshaling budget: %v", err)
+			continue
+		}
+		presupuestos = append(presupuestos, &presupuesto)
+	}
+	return presupuestos
+}
+
+// clavePresupuesto arma la clave de un presupuesto (centro_costo_id + periodo)
+func clavePresupuesto(centroCostoID, periodo string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"centro_costo_id": {
+			S: aws.String(centroCostoID),
+		},
+		"periodo": {
+			S: aws.String(periodo),
+		},
+	}
+}
+
+// actualizarPresupuesto arma la escritura que aplica un movimiento al presupuesto dentro de la
+// transacción de una orden. Si el movimiento consume fondos se exige que el disponible lo cubra.
+func actualizarPresupuesto(movimiento *models.MovimientoPresupuesto, ahora time.Time) (*dynamodb.TransactWriteItem, error) {
+	consumo := movimiento.Consumo()
+
+	condicion := expression.AttributeExists(expression.Name("centro_costo_id"))
+	if consumo > 0 {
+		condicion = condicion.And(expression.Name("monto_disponible").GreaterThanEqual(expression.Value(consumo)))
+	}
+	update := expression.Add(expression.Name("monto_comprometido"), expression.Value(movimiento.Comprometido)).
+		Add(expression.Name("monto_ejecutado"), expression.Value(movimiento.Ejecutado)).
+		Add(expression.Name("monto_disponible"), expression.Value(-consumo)).
+		Set(expression.Name("updated_at"), expression.Value(ahora))
+
+	expr, err := expression.NewBuilder().WithCondition(condicion).WithUpdate(update).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return &dynamodb.TransactWriteItem{
+		Update: &dynamodb.Update{
+			TableName:                 aws.String("budgets"),
+			Key:                       clavePresupuesto(movimiento.CentroCostoID, movimiento.Periodo),
+			ConditionExpression:       expr.Condition(),
+			UpdateExpression:          expr.Update(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+		},
+	}, nil
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"errors"
+	"mediplus/purchase-order-service/internal/database"
+	"mediplus/purchase-order-service/internal/models"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrExcursionModificada se retorna cuando otra lectura actualizó la excursión después de leerla
+var ErrExcursionModificada = errors.New("temperature excursion was modified concurrently")
+
+// ExcursionRepository define la interfaz para el repositorio de excursiones de temperatura
+type ExcursionRepository interface {
+	Get(productoID, unidad, numeroLote string) (*models.ExcursionTemperatura, error)
+	Save(excursion *models.ExcursionTemperatura, ultimaLecturaAnterior *time.Time) error
+	Close(excursion *models.ExcursionTemperatura) error
+	RegistrarBaja(excursion *models.ExcursionTemperatura) error
+	ListByProducto(productoID string) ([]*models.ExcursionTemperatura, error)
+}
+
+// excursionRepository implementa ExcursionRepository
+type excursionRepository struct {
+	db  *database.DynamoDBClient
+	log *logrus.Logger
+}
+
+// NewExcursionRepository crea una nueva instancia de ExcursionRepository
+func NewExcursionRepository(db *database.DynamoDBClient, log *logrus.Logger) ExcursionRepository {
+	return &excursionRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Get obtiene la excursión de un lote en una unidad de almacenamiento
+func (r *excursionRepository) Get(productoID, unidad, numeroLote string) (*models.ExcursionTemperatura, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String("temperature_excursions"),
+		Key:       claveExcursion(productoID, models.ClaveUnidadLote(unidad, numeroLote)),
+	}
+
+	result, err := r.db.GetClient().GetItem(input)
+	if err != nil {
+		r.log.Errorf("Error getting temperature excursion: %v", err)
+		return nil, err
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var excursion models.ExcursionTemperatura
+	err = dynamodbattribute.UnmarshalMap(result.Item, &excursion)
+	if err != nil {
+		r.log.Errorf("Error unmarshaling temperature excursion: %v", err)
+		return nil, err
+	}
+
+	return &excursion, nil
+}
+
+// Save guarda la excursión solo si sigue como se leyó (sin excursión previa si ultimaLecturaAnterior
+// es nil), de modo que dos lecturas concurrentes no comprometan ni notifiquen dos veces el mismo lote
+func (r *excursionRepository) Save(excursion *models.ExcursionTemperatura, ultimaLecturaAnterior *time.Time) error {
+	item, err := dynamodbattribute.MarshalMap(excursion)
+	if err != nil {
+		return err
+	}
+
+	condicion := expression.AttributeNotExists(expression.Name("producto_id"))
+	if ultimaLecturaAnterior != nil {
+		condicion = expression.Name("estado").Equal(expression.Value(models.EstadoExcursionAbierta)).
+			And(expression.Name("ultima_lectura").Equal(expression.Value(*ultimaLecturaAnterior)))
+	}
+	expr, err := expression.NewBuilder().WithCondition(condicion).Build()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.GetClient().PutItem(&dynamodb.PutItemInput{
+		TableName:                 aws.String("temperature_excursions"),
+		Item:                      item,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		var fallida *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &fallida) {
+			return ErrExcursionModificada
+		}
+		r.log.Errorf("Error saving temperature excursion: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// RegistrarBaja marca que el lote de la excursión comprometida ya se dio de baja. Retorna
+// ErrExcursionModificada si otra lectura ya la registró.
+func (r *excursionRepository) RegistrarBaja(excursion *models.ExcursionTemperatura) error {
+	condicion := expression.Name("estado").Equal(expression.Value(models.EstadoExcursionComprometida)).
+		And(expression.Name("baja_pendiente").Equal(expression.Value(true)))
+	update := expression.Remove(expression.Name("baja_pendiente"))
+	expr, err := expression.NewBuilder().WithCondition(condicion).WithUpdate(update).Build()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.GetClient().UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 aws.String("temperature_excursions"),
+		Key:                       claveExcursion(excursion.ProductoID, excursion.UnidadLote),
+		ConditionExpression:       expr.Condition(),
+		UpdateExpression:          expr.Update(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		var fallida *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &fallida) {
+			return ErrExcursionModificada
+		}
+		r.log.Errorf("Error registering lot write-off for temperature excursion: %v", err)
+		return err
+	}
+
+	excursion.BajaPendiente = false
+	return nil
+}
+
+// Close elimina la excursión abierta cuando la temperatura vuelve al rango
+func (r *excursionRepository) Close(excursion *models.ExcursionTemperatura) error {
+	condicion := expression.Name("estado").Equal(expression.Value(models.EstadoExcursionAbierta)).
+		And(expression.Name("ultima_lectura").Equal(expression.Value(excursion.UltimaLectura)))
+	expr, err := expression.NewBuilder().WithCondition(condicion).Build()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.GetClient().DeleteItem(&dynamodb.DeleteItemInput{
+		TableName:                 aws.String("temperature_excursions"),
+		Key:                       claveExcursion(excursion.ProductoID, excursion.UnidadLote),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		var fallida *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &fallida) {
+			return ErrExcursionModificada
+		}
+		r.log.Errorf("Error closing temperature excursion: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ListByProducto lista las excursiones abiertas y comprometidas de un producto
+func (r *excursionRepository) ListByProducto(productoID string) ([]*models.ExcursionTemperatura, error) {
+	keyCond := expression.Key("producto_id").Equal(expression.Value(productoID))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCond).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String("temperature_excursions"),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}
+
+	excursiones := []*models.ExcursionTemperatura{}
+	err = r.db.GetClient().QueryPages(input, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			var excursion models.ExcursionTemperatura
+			if err := dynamodbattribute.UnmarshalMap(item, &excursion); err != nil {
+				r.log.Errorf("Error unmarshaling temperature excursion: %v", err)
+				continue
+			}
+			excursiones = append(excursiones, &excursion)
+		}
+		return true
+	})
+	if err != nil {
+		r.log.Errorf("Error querying temperature excursions: %v", err)
+		return nil, err
+	}
+
+	return excursiones, nil
+}
+
+// claveExcursion arma la clave de una excursión (producto_id + unidad_lote)
+func claveExcursion(productoID, unidadLote string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"producto_id": {
+			S: aws.String(productoID),
+		},
+		"unidad_lote": {
+			S: aws.String(unidadLote),
+		},
+	}
+}
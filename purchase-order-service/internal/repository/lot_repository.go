@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"mediplus/purchase-order-service/internal/database"
+	"mediplus/purchase-order-service/internal/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/sirupsen/logrus"
+)
+
+// LotRepository define la interfaz para el repositorio de lotes de productos.
+// Las cantidades de los lotes solo cambian junto con un movimiento de inventario.
+type LotRepository interface {
+	GetByNumero(productoID, numeroLote string) (*models.Lote, error)
+	ListByProducto(productoID string) ([]*models.Lote, error)
+	ListWithStock() ([]*models.Lote, error)
+}
+
+// lotRepository implementa LotRepository
+type lotRepository struct {
+	db  *database.DynamoDBClient
+	log *logrus.Logger
+}
+
+// NewLotRepository crea una nueva instancia de LotRepository
+func NewLotRepository(db *database.DynamoDBClient, log *logrus.Logger) LotRepository {
+	return &lotRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// GetByNumero obtiene un lote de un producto por su número
+func (r *lotRepository) GetByNumero(productoID, numeroLote string) (*models.Lote, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String("product_lots"),
+		Key:       claveLote(productoID, numeroLote),
+	}
+
+	result, err := r.db.GetClient().GetItem(input)
+	if err != nil {
+		r.log.Errorf("Error getting lot: %v", err)
+		return nil, err
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var lote models.Lote
+	err = dynamodbattribute.UnmarshalMap(result.Item, &lote)
+	if err != nil {
+		r.log.Errorf("Error unmarshaling lot: %v", err)
+		return nil, err
+	}
+
+	return &lote, nil
+}
+
+// ListByProducto lista los lotes de un producto
+func (r *lotRepository) ListByProducto(productoID string) ([]*models.Lote, error) {
+	keyCond := expression.Key("producto_id").Equal(expression.Value(productoID))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCond).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String("product_lots"),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}
+
+	lotes := []*models.Lote{}
+	err = r.db.GetClient().QueryPages(input, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		lotes = append(lotes, r.unmarshalLotes(page.Items)...)
+		return true
+	})
+	if err != nil {
+		r.log.Errorf("Error querying lots: %v", err)
+		return nil, err
+	}
+
+	return lotes, nil
+}
+
+// ListWithStock lista los lotes activos de todos los productos que aún tienen existencias
+func (r *lotRepository) ListWithStock() ([]*models.Lote, error) {
+	filter := expression.Name("cantidad_disponible").GreaterThan(expression.Value(0)).
+		And(expression.Name("estado").Equal(expression.Value(models.EstadoLoteActivo)))
+	expr, err := expression.NewBuilder().WithFilter(filter).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	input := &dynamodb.ScanInput{
+		TableName:                 aws.String("product_lots"),
+		FilterExpression:          expr.Filter(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}
+
+	lotes := []*models.Lote{}
+	err = r.db.GetClient().ScanPages(input, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		lotes = append(lotes, r.unmarshalLotes(page.Items)...)
+		return true
+	})
+	if err != nil {
+		r.log.Errorf("Error scanning lots: %v", err)
+		return nil, err
+	}
+
+	return lotes, nil
+}
+
+// unmarshalLotes convierte los items de DynamoDB en lotes, descartando los inválidos
+func (r *lotRepository) unmarshalLotes(items []map[string]*dynamodb.AttributeValue) []*models.Lote {
+	lotes := make([]*models.Lote, 0, len(items))
+	for _, item := range items {
+		var lote models.Lote
+		if err := dynamodbattribute.UnmarshalMap(item, &lote); err != nil {
+			r.log.Errorf("Error unmarshaling lot: %v", err)
+			continue
+		}
+		lotes = append(lotes, &lote)
+	}
+	return lotes
+}
+
+// claveLote arma la clave de un lote (producto_id + numero_lote)
+func claveLote(productoID, numeroLote string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"producto_id": {
+			S: aws.String(productoID),
+		},
+		"numero_lote": {
+			S: aws.String(numeroLote),
+		},
+	}
+}
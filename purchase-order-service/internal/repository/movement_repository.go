@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"mediplus/purchase-order-service/internal/database"
+	"mediplus/purchase-order-service/internal/models"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrStockInsuficiente se retorna cuando una salida dejaría el stock o un lote en negativo
+var ErrStockInsuficiente = errors.New("insufficient stock for movement")
+
+// ErrLoteNoActivo se retorna cuando una entrada apunta a un lote vencido o dado de baja
+var ErrLoteNoActivo = errors.New("lot is not active")
+
+// ErrLoteModificado se retorna cuando el lote a dar de baja ya no está activo o su cantidad cambió
+var ErrLoteModificado = errors.New("lot was modified concurrently")
+
+// MovementRepository define la interfaz para el libro de movimientos de inventario
+type MovementRepository interface {
+	Record(movimiento *models.MovimientoInventario) error
+	WriteOffLot(movimiento *models.MovimientoInventario, lote *models.Lote, estado models.EstadoLote) error
+	ListByProducto(productoID string) ([]*models.MovimientoInventario, error)
+}
+
+// movementRepository implementa MovementRepository
+type movementRepository struct {
+	db  *database.DynamoDBClient
+	log *logrus.Logger
+}
+
+// NewMovementRepository crea una nueva instancia de MovementRepository
+func NewMovementRepository(db *database.DynamoDBClient, log *logrus.Logger) MovementRepository {
+	return &movementRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Record agrega el movimiento al libro y aplica su cantidad a stock_actual y a los lotes
+// indicados en una sola transacción. Las salidas se rechazan si dejarían en negativo el
+// stock o alguno de los lotes.
+func (r *movementRepository) Record(movimiento *models.MovimientoInventario) error {
+	put, err := putMovimiento(movimiento)
+	if err != nil {
+		return err
+	}
+
+	condicion := expression.AttributeExists(expression.Name("producto_id"))
+	if movimiento.Cantidad < 0 {
+		condicion = condicion.And(expression.Name("stock_actual").GreaterThanEqual(expression.Value(-movimiento.Cantidad)))
+	}
+
+	update, err := updateStock(movimiento.ProductoID, movimiento.Cantidad, condicion)
+	if err != nil {
+		return err
+	}
+
+	transacciones := []*dynamodb.TransactWriteItem{put, update}
+	ahora := time.Now()
+	for _, ml := range movimiento.Lotes {
+		lote, err := updateLote(movimiento.ProductoID, ml, ahora)
+		if err != nil {
+			return err
+		}
+		transacciones = append(transacciones, lote)
+	}
+
+	_, err = r.db.GetClient().TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+		TransactItems: transacciones,
+	})
+	if err != nil {
+		var cancelada *dynamodb.TransactionCanceledException
+		if errors.As(err, &cancelada) {
+			// Índices: 0 es el movimiento, 1 el producto y luego los lotes
+			for i, motivo := range cancelada.CancellationReasons {
+				if i == 0 || motivo == nil || aws.StringValue(motivo.Code) != "ConditionalCheckFailed" {
+					continue
+				}
+				if i == 1 && movimiento.Cantidad > 0 {
+					return ErrProductoNoEncontrado
+				}
+				if i >= 2 && i-2 < len(movimiento.Lotes) && movimiento.Lotes[i-2].Cantidad > 0 {
+					return fmt.Errorf("%w: %s", ErrLoteNoActivo, movimiento.Lotes[i-2].NumeroLote)
+				}
+				return ErrStockInsuficiente
+			}
+		}
+		r.log.Errorf("Error recording inventory movement: %v", err)
+		return err
+	}
+
+	r.log.Infof("Inventory movement recorded: %s %s %+d", movimiento.ProductoID, movimiento.Tipo, movimiento.Cantidad)
+	return nil
+}
+
+// WriteOffLot da de baja todo lo disponible de un lote y lo deja en el estado indicado
+// (VENCIDO o DANADO), registrando el movimiento en el libro en la misma transacción.
+// Retorna ErrLoteModificado si el lote ya no está activo o su cantidad cambió.
+func (r *movementRepository) WriteOffLot(movimiento *models.MovimientoInventario, lote *models.Lote, estado models.EstadoLote) error {
+	put, err := putMovimiento(movimiento)
+	if err != nil {
+		return err
+	}
+
+	update, err := updateStock(movimiento.ProductoID, movimiento.Cantidad,
+		expression.AttributeExists(expression.Name("producto_id")))
+	if err != nil {
+		return err
+	}
+
+	condicion := expression.Name("estado").Equal(expression.Value(models.EstadoLoteActivo)).
+		And(expression.Name("cantidad_disponible").Equal(expression.Value(lote.CantidadDisponible)))
+	actualizacion := expression.Set(expression.Name("estado"), expression.Value(estado)).
+		Set(expression.Name("cantidad_disponible"), expression.Value(0)).
+		Set(expression.Name("updated_at"), expression.Value(time.Now()))
+
+	expr, err := expression.NewBuilder().WithCondition(condicion).WithUpdate(actualizacion).Build()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.GetClient().TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+		TransactItems: []*dynamodb.TransactWriteItem{
+			put,
+			update,
+			{
+				Update: &dynamodb.Update{
+					TableName:                 aws.String("product_lots"),
+					Key:                       claveLote(lote.ProductoID, lote.NumeroLote),
+					ConditionExpression:       expr.Condition(),
+					UpdateExpression:          expr.Update(),
+					ExpressionAttributeNames:  expr.Names(),
+					ExpressionAttributeValues: expr.Values(),
+				},
+			},
+		},
+	})
+	if err != nil {
+		var cancelada *dynamodb.TransactionCanceledException
+		if errors.As(err, &cancelada) && len(cancelada.CancellationReasons) > 2 &&
+			aws.StringValue(cancelada.CancellationReasons[2].Code) == "ConditionalCheckFailed" {
+			return ErrLoteModificado
+		}
+		r.log.Errorf("Error writing off lot: %v", err)
+		return err
+	}
+
+	r.log.Infof("Lot written off: %s/%s (%s, %d units)", lote.ProductoID, lote.NumeroLote, estado, -movimiento.Cantidad)
+	return nil
+}
+
+// ListByProducto lista los movimientos de un producto en orden cronológico
+func (r *movementRepository) ListByProducto(productoID string) ([]*models.MovimientoInventario, error) {
+	keyCond := expression.Key("producto_id").Equal(expression.Value(productoID))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCond).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String("inventory_movements"),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ScanIndexForward:          aws.Bool(true),
+	}
+
+	movimientos := []*models.MovimientoInventario{}
+	err = r.db.GetClient().QueryPages(input, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			var movimiento models.MovimientoInventario
+			if err := dynamodbattribute.UnmarshalMap(item, &movimiento); err != nil {
+				r.log.Errorf("Error unmarshaling inventory movement: %v", err)
+				continue
+			}
+			movimientos = append(movimientos, &movimiento)
+		}
+		return true
+	})
+	if err != nil {
+		r.log.Errorf("Error querying inventory movements: %v", err)
+		return nil, err
+	}
+
+	return movimientos, nil
+}
+
+// putMovimiento arma la escritura de un movimiento del libro; nunca sobrescribe uno existente
+func putMovimiento(movimiento *models.MovimientoInventario) (*dynamodb.TransactWriteItem, error) {
+	item, err := dynamodbattribute.MarshalMap(movimiento)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dynamodb.TransactWriteItem{
+		Put: &dynamodb.Put{
+			TableName:           aws.String("inventory_movements"),
+			Item:                item,
+			ConditionExpression: aws.String("attribute_not_exists(movimiento_id)"),
+		},
+	}, nil
+}
+
+// updateStock arma la actualización atómica (ADD) de stock_actual de un producto bajo la condición indicada
+func updateStock(productoID string, cantidad int, condicion expression.ConditionBuilder) (*dynamodb.TransactWriteItem, error) {
+	update := expression.Add(expression.Name("stock_actual"), expression.Value(cantidad)).
+		Set(expression.Name("updated_at"), expression.Value(time.Now()))
+
+	expr, err := expression.NewBuilder().WithCondition(condicion).WithUpdate(update).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return &dynamodb.TransactWriteItem{
+		Update: &dynamodb.Update{
+			TableName: aws.String("products"),
+			Key: map[string]*dynamodb.AttributeValue{
+				"producto_id": {
+					S: aws.String(productoID),
+				},
+			},
+			ConditionExpression:       expr.Condition(),
+			UpdateExpression:          expr.Update(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+		},
+	}, nil
+}
+
+// updateLote arma la actualización de un lote. Las entradas crean el lote si no existe
+// (los datos de origen solo se fijan la primera vez); las salidas exigen que el lote
+// tenga la cantidad disponible.
+func updateLote(productoID string, ml models.MovimientoLote, ahora time.Time) (*dynamodb.TransactWriteItem, error) {
+	var condicion expression.ConditionBuilder
+	update := expression.Add(expression.Name("cantidad_disponible"), expression.Value(ml.Cantidad)).
+		Set(expression.Name("updated_at"), expression.Value(ahora))
+
+	if ml.Cantidad > 0 {
+		condicion = expression.AttributeNotExists(expression.Name("estado")).
+			Or(expression.Name("estado").Equal(expression.Value(models.EstadoLoteActivo)))
+		update = update.
+			Add(expression.Name("cantidad_inicial"), expression.Value(ml.Cantidad)).
+			Set(expression.Name("estado"), expression.IfNotExists(expression.Name("estado"), expression.Value(models.EstadoLoteActivo))).
+			Set(expression.Name("fecha_recepcion"), expression.IfNotExists(expression.Name("fecha_recepcion"), expression.Value(ahora))).
+			Set(expression.Name("created_at"), expression.IfNotExists(expression.Name("created_at"), expression.Value(ahora)))
+		if ml.FechaVencimiento != nil {
+			update = update.Set(expression.Name("fecha_vencimiento"),
+				expression.IfNotExists(expression.Name("fecha_vencimiento"), expression.Value(*ml.FechaVencimiento)))
+		}
+		if ml.OrdenID != "" {
+			update = update.Set(expression.Name("orden_id"),
+				expression.IfNotExists(expression.Name("orden_id"), expression.Value(ml.OrdenID)))
+		}
+		if ml.ProveedorID != "" {
+			update = update.Set(expression.Name("proveedor_id"),
+				expression.IfNotExists(expression.Name("proveedor_id"), expression.Value(ml.ProveedorID)))
+		}
+	} else {
+		condicion = expression.Name("cantidad_disponible").GreaterThanEqual(expression.Value(-ml.Cantidad))
+	}
+
+	expr, err := expression.NewBuilder().WithCondition(condicion).WithUpdate(update).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return &dynamodb.TransactWriteItem{
+		Update: &dynamodb.Update{
+			TableName:                 aws.String("product_lots"),
+			Key:                       claveLote(productoID, ml.NumeroLote),
+			ConditionExpression:       expr.Condition(),
+			UpdateExpression:          expr.Update(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+		},
+	}, nil
+}
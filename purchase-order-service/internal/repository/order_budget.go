@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"mediplus/purchase-order-service/internal/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// SaveWithBudget guarda la orden y aplica el movimiento a su presupuesto en una sola
+// transacción. Una orden nueva (actualizadaEn nil) no debe existir; una existente solo se
+// escribe si su updated_at sigue siendo el leído, de modo que un reintento no reserve dos veces.
+func (r *orderRepository) SaveWithBudget(orden *models.OrdenCompra, actualizadaEn *time.Time, movimiento *models.MovimientoPresupuesto) error {
+	item, err := dynamodbattribute.MarshalMap(orden)
+	if err != nil {
+		return err
+	}
+
+	condicion := expression.AttributeNotExists(expression.Name("orden_id"))
+	if actualizadaEn != nil {
+		condicion = expression.Name("updated_at").Equal(expression.Value(*actualizadaEn))
+	}
+	expr, err := expression.NewBuilder().WithCondition(condicion).Build()
+	if err != nil {
+		return err
+	}
+
+	presupuesto, err := actualizarPresupuesto(movimiento, time.Now())
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.GetClient().TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{
+				Put: &dynamodb.Put{
+					TableName:                 aws.String("orders"),
+					Item:                      item,
+					ConditionExpression:       expr.Condition(),
+					ExpressionAttributeNames:  expr.Names(),
+					ExpressionAttributeValues: expr.Values(),
+				},
+			},
+			presupuesto,
+		},
+	})
+	if err != nil {
+		var cancelada *dynamodb.TransactionCanceledException
+		if !errors.As(err, &cancelada) {
+			r.log.Errorf("Error saving order with budget: %v", err)
+			return err
+		}
+		for i, motivo := range cancelada.CancellationReasons {
+			if motivo == nil || aws.StringValue(motivo.Code) != "ConditionalCheckFailed" {
+				continue
+			}
+			if i == 0 {
+				return ErrOrdenModificada
+			}
+			return r.errorPresupuesto(movimiento)
+		}
+		r.log.Errorf("Order budget transaction cancelled: %v", err)
+		return err
+	}
+
+	r.log.Infof("Order saved with budget movement: %s (%s %s, comprometido %s, ejecutado %s)",
+		orden.OrdenID, movimiento.CentroCostoID, movimiento.Periodo, movimiento.Comprometido, movimiento.Ejecutado)
+	return nil
+}
+
+// ListByCentroCosto lista las órdenes cargadas a un centro de costo
+func (r *orderRepository) ListByCentroCosto(centroCostoID string) ([]*models.OrdenCompra, error) {
+	filter := expression.Name("centro_costo_id").Equal(expression.Value(centroCostoID))
+	expr, err := expression.NewBuilder().WithFilter(filter).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	input := &dynamodb.ScanInput{
+		TableName:                 aws.String("orders"),
+		FilterExpression:          expr.Filter(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}
+
+	ordenes := []*models.OrdenCompra{}
+	err = r.db.GetClient().ScanPages(input, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			var orden models.OrdenCompra
+			if err := dynamodbattribute.UnmarshalMap(item, &orden); err != nil {
+				r.log.Errorf("Error unmarshaling order: %v", err)
+				continue
+			}
+			ordenes = append(ordenes, &orden)
+		}
+		return true
+	})
+	if err != nil {
+		r.log.Errorf("Error scanning orders by cost center: %v", err)
+		return nil, err
+	}
+
+	return ordenes, nil
+}
+
+// errorPresupuesto relee el presupuesto cuya condición falló para distinguir un presupuesto
+// inexistente de uno sin disponible suficiente
+func (r *orderRepository) errorPresupuesto(movimiento *models.MovimientoPresupuesto) error {
+	result, err := r.db.GetClient().GetItem(&dynamodb.GetItemInput{
+		TableName:      aws.String("budgets"),
+		Key:            clavePresupuesto(movimiento.CentroCostoID, movimiento.Periodo),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		r.log.Errorf("Error getting budget: %v", err)
+		return err
+	}
+
+	if result.Item == nil {
+		return fmt.Errorf("%w: centro de costo %s, período %s",
+			ErrPresupuestoNoEncontrado, movimiento.CentroCostoID, movimiento.Periodo)
+	}
+
+	var presupuesto models.Presupuesto
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &presupuesto); err != nil {
+		r.log.Errorf("Error unmarshaling budget: %v", err)
+		return err
+	}
+
+	r.log.Warnf("Budget condition failed for %s %s (requested %s, available %s)",
+		movimiento.CentroCostoID, movimiento.Periodo, movimiento.Consumo(), presupuesto.MontoDisponible)
+	return fmt.Errorf("%w: centro de costo %s, período %s: disponible %s, requerido %s",
+		ErrPresupuestoInsuficiente, movimiento.CentroCostoID, movimiento.Periodo,
+		presupuesto.MontoDisponible, movimiento.Consumo())
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"mediplus/purchase-order-service/internal/database"
+	"mediplus/purchase-order-service/internal/models"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrNumeracionModificada se retorna cuando otra orden tomó el número siguiente de la serie
+	// desde que se leyó el contador
+	ErrNumeracionModificada = errors.New("order number sequence was modified concurrently")
+	// ErrNumeroOrdenDuplicado se retorna cuando el número de orden ya fue asignado a otra orden
+	ErrNumeroOrdenDuplicado = errors.New("order number already assigned")
+)
+
+// Prefijos de las claves de la tabla order_numbers
+const (
+	prefijoClaveSerie  = "SERIE#"
+	prefijoClaveNumero = "NUMERO#"
+)
+
+// OrderNumberRepository define la interfaz para el contador de números de orden
+type OrderNumberRepository interface {
+	UltimoNumero(serie string) (int64, error)
+}
+
+// orderNumberRepository implementa OrderNumberRepository
+type orderNumberRepository struct {
+	db  *database.DynamoDBClient
+	log *logrus.Logger
+}
+
+// NewOrderNumberRepository crea una nueva instancia de OrderNumberRepository
+func NewOrderNumberRepository(db *database.DynamoDBClient, log *logrus.Logger) OrderNumberRepository {
+	return &orderNumberRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// UltimoNumero retorna el último número asignado en la serie, o 0 si aún no tiene órdenes
+func (r *orderNumberRepository) UltimoNumero(serie string) (int64, error) {
+	result, err := r.db.GetClient().GetItem(&dynamodb.GetItemInput{
+		TableName:      aws.String("order_numbers"),
+		Key:            claveNumeracion(prefijoClaveSerie + serie),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		r.log.Errorf("Error getting order number counter: %v", err)
+		return 0, err
+	}
+
+	if result.Item == nil {
+		return 0, nil
+	}
+
+	var contador struct {
+		UltimoNumero int64 `dynamodbav:"ultimo_numero"`
+	}
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &contador); err != nil {
+		r.log.Errorf("Error unmarshaling order number counter: %v", err)
+		return 0, err
+	}
+
+	return contador.UltimoNumero, nil
+}
+
+// CreateNumbered crea la orden con el número reservado y, si lo hay, el movimiento de su
+// presupuesto en una sola transacción. El contador de la serie avanza solo si sigue en el número
+// leído y el número no puede estar asignado, de modo que la serie no tenga huecos ni repetidos.
+func (r *orderRepository) CreateNumbered(orden *models.OrdenCompra, numeracion *models.NumeracionOrden, movimiento *models.MovimientoPresupuesto) error {
+	item, err := dynamodbattribute.MarshalMap(orden)
+	if err != nil {
+		return err
+	}
+
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.AttributeNotExists(expression.Name("orden_id"))).
+		Build()
+	if err != nil {
+		return err
+	}
+
+	ahora := time.Now()
+	transaccion, err := reservarNumero(numeracion, orden.OrdenID, ahora)
+	if err != nil {
+		return err
+	}
+
+	transaccion = append(transaccion, &dynamodb.TransactWriteItem{
+		Put: &dynamodb.Put{
+			TableName:                 aws.String("orders"),
+			Item:                      item,
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+		},
+	})
+
+	if movimiento != nil {
+		presupuesto, err := actualizarPresupuesto(movimiento, ahora)
+		if err != nil {
+			return err
+		}
+		transaccion = append(transaccion, presupuesto)
+	}
+
+	_, err = r.db.GetClient().TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+		TransactItems: transaccion,
+	})
+	if err != nil {
+		var cancelada *dynamodb.TransactionCanceledException
+		if !errors.As(err, &cancelada) {
+			r.log.Errorf("Error creating numbered order: %v", err)
+			return err
+		}
+		for i, motivo := range cancelada.CancellationReasons {
+			if motivo == nil || aws.StringValue(motivo.Code) != "ConditionalCheckFailed" {
+				continue
+			}
+			switch i {
+			case 0:
+				return ErrNumeracionModificada
+			case 1:
+				return fmt.Errorf("%w: %s", ErrNumeroOrdenDuplicado, orden.NumeroOrden)
+			case 2:
+				return ErrOrdenModificada
+			}
+			return r.errorPresupuesto(movimiento)
+		}
+		r.log.Errorf("Numbered order transaction cancelled: %v", err)
+		return err
+	}
+
+	r.log.Infof("Order created successfully: %s (%s)", orden.OrdenID, orden.NumeroOrden)
+	return nil
+}
+
+// reservarNumero arma las escrituras que avanzan el contador de la serie al número de la orden
+// y registran ese número como asignado
+func reservarNumero(numeracion *models.NumeracionOrden, ordenID string, ahora time.Time) ([]*dynamodb.TransactWriteItem, error) {
+	condicion := expression.Name("ultimo_numero").Equal(expression.Value(numeracion.Anterior))
+	if numeracion.Anterior == 0 {
+		condicion = expression.AttributeNotExists(expression.Name("clave"))
+	}
+	update := expression.Set(expression.Name("ultimo_numero"), expression.Value(numeracion.Secuencia)).
+		Set(expression.Name("updated_at"), expression.Value(ahora))
+
+	exprContador, err := expression.NewBuilder().WithCondition(condicion).WithUpdate(update).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	registro, err := dynamodbattribute.MarshalMap(map[string]interface{}{
+		"clave":      prefijoClaveNumero + numeracion.Numero(),
+		"orden_id":   ordenID,
+		"serie":      numeracion.Serie,
+		"secuencia":  numeracion.Secuencia,
+		"created_at": ahora,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exprRegistro, err := expression.NewBuilder().
+		WithCondition(expression.AttributeNotExists(expression.Name("clave"))).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return []*dynamodb.TransactWriteItem{
+		{
+			Update: &dynamodb.Update{
+				TableName:                 aws.String("order_numbers"),
+				Key:                       claveNumeracion(prefijoClaveSerie + numeracion.Serie),
+				ConditionExpression:       exprContador.Condition(),
+				UpdateExpression:          exprContador.Update(),
+				ExpressionAttributeNames:  exprContador.Names(),
+				ExpressionAttributeValues: exprContador.Values(),
+			},
+		},
+		{
+			Put: &dynamodb.Put{
+				TableName:                 aws.String("order_numbers"),
+				Item:                      registro,
+				ConditionExpression:       exprRegistro.Condition(),
+				ExpressionAttributeNames:  exprRegistro.Names(),
+				ExpressionAttributeValues: exprRegistro.Values(),
+			},
+		},
+	}, nil
+}
+
+// claveNumeracion arma la clave de un item de la tabla order_numbers
+func claveNumeracion(clave string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"clave": {S: aws.String(clave)},
+	}
+}
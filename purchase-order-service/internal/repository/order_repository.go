@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"errors"
 	"mediplus/purchase-order-service/internal/database"
 	"mediplus/purchase-order-service/internal/models"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -15,12 +17,16 @@ import (
 type OrderRepository interface {
 	Create(orden *models.OrdenCompra) error
 	GetByID(ordenID string) (*models.OrdenCompra, error)
-	Update(orden *models.OrdenCompra) error
-	Delete(ordenID string) error
+	Update(orden *models.OrdenCompra, actualizadaEn time.Time) error
+	Delete(orden *models.OrdenCompra, movimiento *models.MovimientoPresupuesto) error
 	ListByEstado(estado models.EstadoOrden) ([]*models.OrdenCompra, error)
 	ListByProveedor(proveedorID string) ([]*models.OrdenCompra, error)
 	ListAll() ([]*models.OrdenCompra, error)
 	GetByNumeroOrden(numeroOrden string) (*models.OrdenCompra, error)
+	SaveReceipt(orden *models.OrdenCompra, actualizadaEn time.Time, incrementos []IncrementoStock, movimiento *models.MovimientoPresupuesto) error
+	SaveWithBudget(orden *models.OrdenCompra, actualizadaEn *time.Time, movimiento *models.MovimientoPresupuesto) error
+	CreateNumbered(orden *models.OrdenCompra, numeracion *models.NumeracionOrden, movimiento *models.MovimientoPresupuesto) error
+	ListByCentroCosto(centroCostoID string) ([]*models.OrdenCompra, error)
 }
 
 // orderRepository implementa OrderRepository
@@ -90,20 +96,35 @@ func (r *orderRepository) GetByID(ordenID string) (*models.OrdenCompra, error) {
 	return &orden, nil
 }
 
-// Update actualiza una orden existente
-func (r *orderRepository) Update(orden *models.OrdenCompra) error {
+// Update actualiza una orden existente solo si su updated_at sigue siendo actualizadaEn, el
+// leído antes de modificarla. Retorna ErrOrdenModificada si otra escritura la cambió.
+func (r *orderRepository) Update(orden *models.OrdenCompra, actualizadaEn time.Time) error {
 	item, err := dynamodbattribute.MarshalMap(orden)
 	if err != nil {
 		return err
 	}
 
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.Name("updated_at").Equal(expression.Value(actualizadaEn))).
+		Build()
+	if err != nil {
+		return err
+	}
+
 	input := &dynamodb.PutItemInput{
-		TableName: aws.String("orders"),
-		Item:      item,
+		TableName:                 aws.String("orders"),
+		Item:                      item,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
 	}
 
 	_, err = r.db.GetClient().PutItem(input)
 	if err != nil {
+		var fallida *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &fallida) {
+			return ErrOrdenModificada
+		}
 		r.log.Errorf("Error updating order: %v", err)
 		return err
 	}
@@ -112,24 +133,62 @@ func (r *orderRepository) Update(orden *models.OrdenCompra) error {
 	return nil
 }
 
-// Delete elimina una orden
-func (r *orderRepository) Delete(ordenID string) error {
-	input := &dynamodb.DeleteItemInput{
-		TableName: aws.String("orders"),
-		Key: map[string]*dynamodb.AttributeValue{
-			"orden_id": {
-				S: aws.String(ordenID),
+// Delete elimina una orden si no cambió desde que se leyó y, si hay movimiento, libera su
+// compromiso en el presupuesto en la misma transacción
+func (r *orderRepository) Delete(orden *models.OrdenCompra, movimiento *models.MovimientoPresupuesto) error {
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.Name("updated_at").Equal(expression.Value(orden.UpdatedAt))).
+		Build()
+	if err != nil {
+		return err
+	}
+
+	transaccion := []*dynamodb.TransactWriteItem{
+		{
+			Delete: &dynamodb.Delete{
+				TableName: aws.String("orders"),
+				Key: map[string]*dynamodb.AttributeValue{
+					"orden_id": {
+						S: aws.String(orden.OrdenID),
+					},
+				},
+				ConditionExpression:       expr.Condition(),
+				ExpressionAttributeNames:  expr.Names(),
+				ExpressionAttributeValues: expr.Values(),
 			},
 		},
 	}
+	if movimiento != nil {
+		presupuesto, err := actualizarPresupuesto(movimiento, time.Now())
+		if err != nil {
+			return err
+		}
+		transaccion = append(transaccion, presupuesto)
+	}
 
-	_, err := r.db.GetClient().DeleteItem(input)
+	_, err = r.db.GetClient().TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+		TransactItems: transaccion,
+	})
 	if err != nil {
-		r.log.Errorf("Error deleting order: %v", err)
+		var cancelada *dynamodb.TransactionCanceledException
+		if !errors.As(err, &cancelada) {
+			r.log.Errorf("Error deleting order: %v", err)
+			return err
+		}
+		for i, motivo := range cancelada.CancellationReasons {
+			if motivo == nil || aws.StringValue(motivo.Code) != "ConditionalCheckFailed" {
+				continue
+			}
+			if i == 0 {
+				return ErrOrdenModificada
+			}
+			return r.errorPresupuesto(movimiento)
+		}
+		r.log.Errorf("Order delete transaction cancelled: %v", err)
 		return err
 	}
 
-	r.log.Infof("Order deleted successfully: %s", ordenID)
+	r.log.Infof("Order deleted successfully: %s", orden.OrdenID)
 	return nil
 }
 
@@ -234,22 +293,23 @@ func (r *orderRepository) ListAll() ([]*models.OrdenCompra, error) {
 
 // GetByNumeroOrden obtiene una orden por su número de orden
 func (r *orderRepository) GetByNumeroOrden(numeroOrden string) (*models.OrdenCompra, error) {
-	filter := expression.Name("numero_orden").Equal(expression.Value(numeroOrden))
-	expr, err := expression.NewBuilder().WithFilter(filter).Build()
+	keyCondition := expression.Key("numero_orden").Equal(expression.Value(numeroOrden))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
 	if err != nil {
 		return nil, err
 	}
 
-	input := &dynamodb.ScanInput{
+	input := &dynamodb.QueryInput{
 		TableName:                 aws.String("orders"),
-		FilterExpression:          expr.Filter(),
+		IndexName:                 aws.String("numero-orden-index"),
+		KeyConditionExpression:    expr.KeyCondition(),
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
 	}
 
-	result, err := r.db.GetClient().Scan(input)
+	result, err := r.db.GetClient().Query(input)
 	if err != nil {
-		r.log.Errorf("Error scanning orders by numero: %v", err)
+		r.log.Errorf("Error querying orders by numero: %v", err)
 		return nil, err
 	}
 
@@ -5,6 +5,7 @@ import (
 	"mediplus/purchase-order-service/internal/models"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
@@ -19,7 +20,6 @@ type ProductRepository interface {
 	Delete(productoID string) error
 	ListAll() ([]*models.Producto, error)
 	GetLowStockProducts() ([]*models.Producto, error)
-	UpdateStock(productoID string, nuevaCantidad int) error
 }
 
 // productRepository implementa ProductRepository
@@ -36,19 +36,41 @@ func NewProductRepository(db *database.DynamoDBClient, log *logrus.Logger) Produ
 	}
 }
 
-// Create crea un nuevo producto
+// Create crea un nuevo producto. Si tiene stock inicial, el movimiento de apertura se
+// agrega al libro de inventario en la misma transacción.
 func (r *productRepository) Create(producto *models.Producto) error {
 	item, err := dynamodbattribute.MarshalMap(producto)
 	if err != nil {
 		return err
 	}
 
-	input := &dynamodb.PutItemInput{
-		TableName: aws.String("products"),
-		Item:      item,
+	transacciones := []*dynamodb.TransactWriteItem{
+		{
+			Put: &dynamodb.Put{
+				TableName:           aws.String("products"),
+				Item:                item,
+				ConditionExpression: aws.String("attribute_not_exists(producto_id)"),
+			},
+		},
+	}
+
+	if producto.StockActual > 0 {
+		apertura, err := models.NewMovimientoInventario(producto.ProductoID, models.MovimientoAjuste,
+			producto.StockActual, "Stock inicial", "system", "")
+		if err != nil {
+			return err
+		}
+
+		put, err := putMovimiento(apertura)
+		if err != nil {
+			return err
+		}
+		transacciones = append(transacciones, put)
 	}
 
-	_, err = r.db.GetClient().PutItem(input)
+	_, err = r.db.GetClient().TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+		TransactItems: transacciones,
+	})
 	if err != nil {
 		r.log.Errorf("Error creating product: %v", err)
 		return err
@@ -89,19 +111,48 @@ func (r *productRepository) GetByID(productoID string) (*models.Producto, error)
 	return &producto, nil
 }
 
-// Update actualiza un producto existente
+// Update actualiza los datos de catálogo de un producto existente. stock_actual no se
+// modifica aquí: es una proyección del libro de movimientos.
 func (r *productRepository) Update(producto *models.Producto) error {
-	item, err := dynamodbattribute.MarshalMap(producto)
+	update := expression.Set(expression.Name("nombre"), expression.Value(producto.Nombre)).
+		Set(expression.Name("punto_reorden"), expression.Value(producto.PuntoReorden)).
+		Set(expression.Name("stock_maximo"), expression.Value(producto.StockMaximo)).
+		Set(expression.Name("condiciones"), expression.Value(producto.Condiciones)).
+		Set(expression.Name("updated_at"), expression.Value(producto.UpdatedAt))
+	if producto.Categoria != "" {
+		update = update.Set(expression.Name("categoria"), expression.Value(producto.Categoria))
+	} else {
+		update = update.Remove(expression.Name("categoria"))
+	}
+	if producto.PoliticaReposicion != nil {
+		update = update.Set(expression.Name("politica_reposicion"), expression.Value(producto.PoliticaReposicion))
+	} else {
+		update = update.Remove(expression.Name("politica_reposicion"))
+	}
+	condicion := expression.AttributeExists(expression.Name("producto_id"))
+
+	expr, err := expression.NewBuilder().WithCondition(condicion).WithUpdate(update).Build()
 	if err != nil {
 		return err
 	}
 
-	input := &dynamodb.PutItemInput{
+	input := &dynamodb.UpdateItemInput{
 		TableName: aws.String("products"),
-		Item:      item,
+		Key: map[string]*dynamodb.AttributeValue{
+			"producto_id": {
+				S: aws.String(producto.ProductoID),
+			},
+		},
+		ConditionExpression:       expr.Condition(),
+		UpdateExpression:          expr.Update(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
 	}
 
-	_, err = r.db.GetClient().PutItem(input)
+	_, err = r.db.GetClient().UpdateItem(input)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return ErrProductoNoEncontrado
+	}
 	if err != nil {
 		r.log.Errorf("Error updating product: %v", err)
 		return err
@@ -194,22 +245,3 @@ func (r *productRepository) GetLowStockProducts() ([]*models.Producto, error) {
 
 	return productos, nil
 }
-
-// UpdateStock actualiza el stock de un producto
-func (r *productRepository) UpdateStock(productoID string, nuevaCantidad int) error {
-	// Obtener el producto actual
-	producto, err := r.GetByID(productoID)
-	if err != nil {
-		return err
-	}
-
-	if producto == nil {
-		return nil // Producto no encontrado
-	}
-
-	// Actualizar el stock
-	producto.StockActual = nuevaCantidad
-
-	// Guardar los cambios
-	return r.Update(producto)
-}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"mediplus/purchase-order-service/internal/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+var (
+	// ErrOrdenModificada se retorna cuando la orden cambió desde que fue leída
+	ErrOrdenModificada = errors.New("order was modified concurrently")
+	// ErrStockMaximoExcedido se retorna cuando un incremento dejaría el stock sobre su máximo
+	ErrStockMaximoExcedido = errors.New("stock increment would exceed maximum stock")
+	// ErrProductoNoEncontrado se retorna cuando un producto a incrementar no existe
+	ErrProductoNoEncontrado = errors.New("product not found")
+)
+
+// IncrementoStock describe el incremento de stock de un producto dentro de una recepción
+type IncrementoStock struct {
+	ProductoID string
+	Cantidad   int
+	// StockMaximo es el máximo leído del producto; se exige que no haya cambiado
+	StockMaximo int
+	// PermitirExcederMaximo omite la condición de stock máximo
+	PermitirExcederMaximo bool
+	// Movimiento es la entrada del libro de inventario que respalda el incremento
+	Movimiento *models.MovimientoInventario
+}
+
+// SaveReceipt guarda la orden con su nueva recepción, incrementa el stock y los lotes de los
+// productos, agrega los movimientos de recepción al libro de inventario y, si la orden tiene
+// presupuesto, pasa lo recibido de comprometido a ejecutado en una sola transacción.
+// La orden solo se escribe si su updated_at sigue siendo el leído, de modo que un reintento
+// sobre una orden ya actualizada no vuelve a sumar stock.
+func (r *orderRepository) SaveReceipt(orden *models.OrdenCompra, actualizadaEn time.Time, incrementos []IncrementoStock, movimiento *models.MovimientoPresupuesto) error {
+	item, err := dynamodbattribute.MarshalMap(orden)
+	if err != nil {
+		return err
+	}
+
+	anterior, err := dynamodbattribute.Marshal(actualizadaEn)
+	if err != nil {
+		return err
+	}
+
+	transacciones := []*dynamodb.TransactWriteItem{
+		{
+			Put: &dynamodb.Put{
+				TableName:           aws.String("orders"),
+				Item:                item,
+				ConditionExpression: aws.String("updated_at = :anterior"),
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":anterior": anterior,
+				},
+			},
+		},
+	}
+
+	// origen indica, por cada escritura, el incremento cuya condición de stock evalúa
+	// (-1 para la orden y para las escrituras sin condición relevante, origenLote para los
+	// lotes y origenPresupuesto para el presupuesto)
+	origen := []int{-1}
+
+	// lotes guarda, en orden, el número de lote de cada escritura marcada con origenLote
+	lotes := []string{}
+
+	ahora := time.Now()
+	for k, inc := range incrementos {
+		condicion := expression.AttributeExists(expression.Name("producto_id"))
+		if !inc.PermitirExcederMaximo {
+			// Las condiciones no admiten aritmética: stock_actual + cantidad <= máximo
+			// se expresa como stock_actual <= máximo - cantidad
+			condicion = condicion.
+				And(expression.Name("stock_maximo").Equal(expression.Value(inc.StockMaximo))).
+				And(expression.Name("stock_actual").LessThanEqual(expression.Value(inc.StockMaximo - inc.Cantidad)))
+		}
+
+		update, err := updateStock(inc.ProductoID, inc.Cantidad, condicion)
+		if err != nil {
+			return err
+		}
+
+		put, err := putMovimiento(inc.Movimiento)
+		if err != nil {
+			return err
+		}
+
+		transacciones = append(transacciones, update, put)
+		origen = append(origen, k, -1)
+
+		for _, ml := range inc.Movimiento.Lotes {
+			lote, err := updateLote(inc.ProductoID, ml, ahora)
+			if err != nil {
+				return err
+			}
+			transacciones = append(transacciones, lote)
+			origen = append(origen, origenLote)
+			lotes = append(lotes, ml.NumeroLote)
+		}
+	}
+
+	if movimiento != nil {
+		presupuesto, err := actualizarPresupuesto(movimiento, ahora)
+		if err != nil {
+			return err
+		}
+		transacciones = append(transacciones, presupuesto)
+		origen = append(origen, origenPresupuesto)
+	}
+
+	_, err = r.db.GetClient().TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+		TransactItems: transacciones,
+	})
+	if err != nil {
+		return r.errorTransaccionRecepcion(err, incrementos, movimiento, origen, lotes)
+	}
+
+	r.log.Infof("Order receipt saved successfully: %s (%d products updated)", orden.OrdenID, len(incrementos))
+	return nil
+}
+
+// Marcas del origen de una recepción para la escritura del presupuesto y las de los lotes
+const (
+	origenPresupuesto = -2
+	origenLote        = -3
+)
+
+// errorTransaccionRecepcion traduce la cancelación de la transacción al error de dominio correspondiente
+func (r *orderRepository) errorTransaccionRecepcion(err error, incrementos []IncrementoStock, movimiento *models.MovimientoPresupuesto, origen []int, lotes []string) error {
+	var cancelada *dynamodb.TransactionCanceledException
+	if !errors.As(err, &cancelada) {
+		r.log.Errorf("Error saving order receipt: %v", err)
+		return err
+	}
+
+	for i, motivo := range cancelada.CancellationReasons {
+		if motivo == nil || aws.StringValue(motivo.Code) != "ConditionalCheckFailed" {
+			continue
+		}
+		if i == 0 {
+			return ErrOrdenModificada
+		}
+		if i < len(origen) && origen[i] == origenPresupuesto {
+			return r.errorPresupuesto(movimiento)
+		}
+		if i < len(origen) && origen[i] == origenLote {
+			lote := 0
+			for _, o := range origen[:i] {
+				if o == origenLote {
+					lote++
+				}
+			}
+			return fmt.Errorf("%w: %s", ErrLoteNoActivo, lotes[lote])
+		}
+		if i >= len(origen) || origen[i] < 0 {
+			continue
+		}
+		inc := incrementos[origen[i]]
+		r.log.Warnf("Stock condition failed for product %s (+%d, max %d)", inc.ProductoID, inc.Cantidad, inc.StockMaximo)
+		if inc.PermitirExcederMaximo {
+			return ErrProductoNoEncontrado
+		}
+		return fmt.Errorf("%w: producto %s no admite %d unidades más (stock máximo %d)",
+			ErrStockMaximoExcedido, inc.ProductoID, inc.Cantidad, inc.StockMaximo)
+	}
+
+	r.log.Errorf("Order receipt transaction cancelled: %v", err)
+	return err
+}
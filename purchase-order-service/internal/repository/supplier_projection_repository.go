@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"errors"
+	"mediplus/purchase-order-service/internal/database"
+	"mediplus/purchase-order-service/internal/models"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrProyeccionModificada se retorna cuando otro evento actualizó la proyección después de leerla
+var ErrProyeccionModificada = errors.New("supplier projection was modified concurrently")
+
+// SupplierProjectionRepository define la interfaz para la proyección local de proveedores
+type SupplierProjectionRepository interface {
+	Get(proveedorID string) (*models.ProveedorProyectado, error)
+	ListAll() ([]*models.ProveedorProyectado, error)
+	Save(proveedor *models.ProveedorProyectado, fechaAnterior *time.Time) error
+	Delete(proveedor *models.ProveedorProyectado) error
+}
+
+// supplierProjectionRepository implementa SupplierProjectionRepository
+type supplierProjectionRepository struct {
+	db  *database.DynamoDBClient
+	log *logrus.Logger
+}
+
+// NewSupplierProjectionRepository crea una nueva instancia de SupplierProjectionRepository
+func NewSupplierProjectionRepository(db *database.DynamoDBClient, log *logrus.Logger) SupplierProjectionRepository {
+	return &supplierProjectionRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Get obtiene la proyección de un proveedor
+func (r *supplierProjectionRepository) Get(proveedorID string) (*models.ProveedorProyectado, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String("supplier_projection"),
+		Key:       claveProveedor(proveedorID),
+	}
+
+	result, err := r.db.GetClient().GetItem(input)
+	if err != nil {
+		r.log.Errorf("Error getting supplier projection: %v", err)
+		return nil, err
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var proveedor models.ProveedorProyectado
+	err = dynamodbattribute.UnmarshalMap(result.Item, &proveedor)
+	if err != nil {
+		r.log.Errorf("Error unmarshaling supplier projection: %v", err)
+		return nil, err
+	}
+
+	return &proveedor, nil
+}
+
+// ListAll lista la proyección de todos los proveedores
+func (r *supplierProjectionRepository) ListAll() ([]*models.ProveedorProyectado, error) {
+	input := &dynamodb.ScanInput{
+		TableName: aws.String("supplier_projection"),
+	}
+
+	proveedores := []*models.ProveedorProyectado{}
+	err := r.db.GetClient().ScanPages(input, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			var proveedor models.ProveedorProyectado
+			if err := dynamodbattribute.UnmarshalMap(item, &proveedor); err != nil {
+				r.log.Errorf("Error unmarshaling supplier projection: %v", err)
+				continue
+			}
+			proveedores = append(proveedores, &proveedor)
+		}
+		return true
+	})
+	if err != nil {
+		r.log.Errorf("Error scanning supplier projection: %v", err)
+		return nil, err
+	}
+
+	return proveedores, nil
+}
+
+// Save guarda la proyección solo si sigue como se leyó (sin proyección previa si fechaAnterior es
+// nil), de modo que dos eventos concurrentes del mismo proveedor no se pisen
+func (r *supplierProjectionRepository) Save(proveedor *models.ProveedorProyectado, fechaAnterior *time.Time) error {
+	item, err := dynamodbattribute.MarshalMap(proveedor)
+	if err != nil {
+		return err
+	}
+
+	condicion := expression.AttributeNotExists(expression.Name("proveedor_id"))
+	if fechaAnterior != nil {
+		condicion = expression.Name("fecha_ultimo_evento").Equal(expression.Value(*fechaAnterior))
+	}
+	expr, err := expression.NewBuilder().WithCondition(condicion).Build()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.GetClient().PutItem(&dynamodb.PutItemInput{
+		TableName:                 aws.String("supplier_projection"),
+		Item:                      item,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		var fallida *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &fallida) {
+			return ErrProyeccionModificada
+		}
+		r.log.Errorf("Error saving supplier projection: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// Delete elimina la proyección de un proveedor si no recibió eventos desde que se leyó
+func (r *supplierProjectionRepository) Delete(proveedor *models.ProveedorProyectado) error {
+	condicion := expression.Name("fecha_ultimo_evento").Equal(expression.Value(proveedor.FechaUltimoEvento))
+	expr, err := expression.NewBuilder().WithCondition(condicion).Build()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.GetClient().DeleteItem(&dynamodb.DeleteItemInput{
+		TableName:                 aws.String("supplier_projection"),
+		Key:                       claveProveedor(proveedor.ProveedorID),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		var fallida *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &fallida) {
+			return ErrProyeccionModificada
+		}
+		r.log.Errorf("Error deleting supplier projection: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// claveProveedor arma la clave de la proyección de un proveedor
+func claveProveedor(proveedorID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"proveedor_id": {
+			S: aws.String(proveedorID),
+		},
+	}
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"mediplus/purchase-order-service/internal/models"
+	"mediplus/purchase-order-service/internal/repository"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+)
+
+// BudgetService define la interfaz para la administración de presupuestos por centro de costo
+type BudgetService interface {
+	CreateBudget(presupuesto *models.Presupuesto) error
+	GetBudget(centroCostoID, periodo string) (*models.Presupuesto, error)
+	ListBudgets(centroCostoID string) ([]*models.Presupuesto, error)
+	UpdateBudgetAmount(centroCostoID, periodo string, montoAsignado models.Monto) (*models.Presupuesto, error)
+	GetBudgetConsumption(centroCostoID, periodo string) (*models.ConsumoPresupuesto, error)
+}
+
+// budgetService implementa BudgetService
+type budgetService struct {
+	budgetRepo   repository.BudgetRepository
+	orderRepo    repository.OrderRepository
+	periodicidad models.PeriodicidadPresupuesto
+	log          *logrus.Logger
+}
+
+// NewBudgetService crea una nueva instancia de BudgetService
+func NewBudgetService(
+	budgetRepo repository.BudgetRepository,
+	orderRepo repository.OrderRepository,
+	periodicidad models.PeriodicidadPresupuesto,
+	log *logrus.Logger,
+) BudgetService {
+	return &budgetService{
+		budgetRepo:   budgetRepo,
+		orderRepo:    orderRepo,
+		periodicidad: periodicidad,
+		log:          log,
+	}
+}
+
+// maxIntentosPresupuesto es la cantidad de veces que se reintenta un cambio de monto ante escrituras concurrentes
+const maxIntentosPresupuesto = 3
+
+// CreateBudget asigna el presupuesto de un centro de costo para un período
+func (s *budgetService) CreateBudget(presupuesto *models.Presupuesto) error {
+	if err := presupuesto.Validar(s.periodicidad); err != nil {
+		return err
+	}
+
+	return s.budgetRepo.Create(presupuesto)
+}
+
+// GetBudget obtiene el presupuesto de un centro de costo para un período
+func (s *budgetService) GetBudget(centroCostoID, periodo string) (*models.Presupuesto, error) {
+	return s.budgetRepo.Get(centroCostoID, periodo)
+}
+
+// ListBudgets lista los presupuestos de un centro de costo, o de todos si no se indica
+func (s *budgetService) ListBudgets(centroCostoID string) ([]*models.Presupuesto, error) {
+	if centroCostoID != "" {
+		return s.budgetRepo.ListByCentroCosto(centroCostoID)
+	}
+	return s.budgetRepo.ListAll()
+}
+
+// UpdateBudgetAmount cambia el monto asignado. No puede quedar por debajo de lo ya comprometido
+// y ejecutado.
+func (s *budgetService) UpdateBudgetAmount(centroCostoID, periodo string, montoAsignado models.Monto) (*models.Presupuesto, error) {
+	if montoAsignado < 0 {
+		return nil, fmt.Errorf("%w: el monto asignado no puede ser negativo", models.ErrPresupuestoInvalido)
+	}
+
+	for intento := 1; ; intento++ {
+		presupuesto, err := s.budgetRepo.Get(centroCostoID, periodo)
+		if err != nil {
+			return nil, err
+		}
+
+		if presupuesto == nil {
+			return nil, repository.ErrPresupuestoNoEncontrado
+		}
+
+		actualizado, err := s.budgetRepo.UpdateAsignado(presupuesto, montoAsignado)
+		if errors.Is(err, repository.ErrPresupuestoModificado) && intento < maxIntentosPresupuesto {
+			s.log.Warnf("Budget %s %s modified concurrently, retrying (attempt %d)", centroCostoID, periodo, intento)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		s.log.WithFields(logrus.Fields{
+			"centro_costo_id":  centroCostoID,
+			"periodo":          periodo,
+			"monto_anterior":   presupuesto.MontoAsignado,
+			"monto_asignado":   actualizado.MontoAsignado,
+			"monto_disponible": actualizado.MontoDisponible,
+		}).Info("Budget amount updated")
+
+		return actualizado, nil
+	}
+}
+
+// GetBudgetConsumption retorna el presupuesto con los porcentajes consumidos y lo que cada orden
+// tiene comprometido y ejecutado en él
+func (s *budgetService) GetBudgetConsumption(centroCostoID, periodo string) (*models.ConsumoPresupuesto, error) {
+	presupuesto, err := s.budgetRepo.Get(centroCostoID, periodo)
+	if err != nil {
+		return nil, err
+	}
+
+	if presupuesto == nil {
+		return nil, repository.ErrPresupuestoNoEncontrado
+	}
+
+	ordenes, err := s.orderRepo.ListByCentroCosto(centroCostoID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(ordenes, func(i, j int) bool {
+		return ordenes[i].FechaGeneracion.Before(ordenes[j].FechaGeneracion)
+	})
+
+	consumo := models.NewConsumoPresupuesto(presupuesto)
+	for _, orden := range ordenes {
+		if orden.Compromiso == nil || orden.Compromiso.Periodo != periodo {
+			continue
+		}
+		if orden.Compromiso.MontoComprometido == 0 && orden.Compromiso.MontoEjecutado == 0 {
+			continue
+		}
+		consumo.AgregarOrden(orden)
+	}
+
+	return consumo, nil
+}
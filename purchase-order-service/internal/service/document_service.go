@@ -0,0 +1,324 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mediplus/pkg/storage"
+	"mediplus/purchase-order-service/internal/clients"
+	"mediplus/purchase-order-service/internal/document"
+	"mediplus/purchase-order-service/internal/models"
+	"mediplus/purchase-order-service/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrDocumentNotIssued se retorna cuando la orden aún no se envió y no tiene un documento emitido
+var ErrDocumentNotIssued = errors.New("order document has not been issued")
+
+// DocumentConfig define los datos del comprador y los términos que se imprimen en el documento
+// de la orden y la URL base de la API con que se arma el enlace de verificación
+type DocumentConfig struct {
+	Comprador       document.Comprador
+	Terminos        []string
+	URLVerificacion string
+}
+
+// ArchivoDocumento es el documento de una orden en un formato
+type ArchivoDocumento struct {
+	Nombre      string
+	ContentType string
+	Contenido   []byte
+	Hash        string
+	Borrador    bool
+}
+
+// VerificacionDocumento es el resultado de verificar un hash contra el documento emitido de una
+// orden. Vigente indica que los datos de la orden siguen siendo los del documento.
+type VerificacionDocumento struct {
+	OrdenID      string    `json:"orden_id"`
+	NumeroOrden  string    `json:"numero_orden"`
+	ProveedorID  string    `json:"proveedor_id"`
+	FechaEmision time.Time `json:"fecha_emision"`
+	Hash         string    `json:"hash"`
+	Valido       bool      `json:"valido"`
+	Vigente      bool      `json:"vigente"`
+}
+
+// DocumentService define la interfaz para el servicio de documentos de órdenes
+type DocumentService interface {
+	GetOrderDocument(ordenID string, formato models.FormatoDocumento) (*ArchivoDocumento, error)
+	ArchiveOrderDocument(orden *models.OrdenCompra) (*models.DocumentoOrden, error)
+	DeleteOrderDocument(documento *models.DocumentoOrden) error
+	VerifyOrderDocument(ordenID, hash string) (*VerificacionDocumento, error)
+}
+
+// documentService implementa DocumentService
+type documentService struct {
+	orderRepo      repository.OrderRepository
+	productRepo    repository.ProductRepository
+	projectionRepo repository.SupplierProjectionRepository
+	supplierClient clients.SupplierClient
+	storage        storage.DocumentStorage
+	renderer       *document.Renderer
+	config         DocumentConfig
+	log            *logrus.Logger
+}
+
+// NewDocumentService crea una nueva instancia de DocumentService
+func NewDocumentService(
+	orderRepo repository.OrderRepository,
+	productRepo repository.ProductRepository,
+	projectionRepo repository.SupplierProjectionRepository,
+	supplierClient clients.SupplierClient,
+	storage storage.DocumentStorage,
+	renderer *document.Renderer,
+	config DocumentConfig,
+	log *logrus.Logger,
+) DocumentService {
+	return &documentService{
+		orderRepo:      orderRepo,
+		productRepo:    productRepo,
+		projectionRepo: projectionRepo,
+		supplierClient: supplierClient,
+		storage:        storage,
+		renderer:       renderer,
+		config:         config,
+		log:            log,
+	}
+}
+
+// GetOrderDocument retorna la copia archivada del documento que se envió al proveedor o, si la
+// orden aún no se envió, un borrador generado con sus datos actuales
+func (s *documentService) GetOrderDocument(ordenID string, formato models.FormatoDocumento) (*ArchivoDocumento, error) {
+	orden, err := s.orderRepo.GetByID(ordenID)
+	if err != nil {
+		return nil, err
+	}
+	if orden == nil {
+		return nil, ErrOrderNotFound
+	}
+
+	archivo := &ArchivoDocumento{
+		Nombre:      nombreDocumento(orden, formato),
+		ContentType: contentTypeDocumento(formato),
+	}
+
+	if orden.Documento != nil {
+		contenido, err := s.leerCopia(orden.Documento.Clave(formato))
+		if err != nil {
+			s.log.Errorf("Error reading archived document of order %s: %v", ordenID, err)
+			return nil, err
+		}
+		archivo.Contenido = contenido
+		archivo.Hash = orden.Documento.Hash
+		return archivo, nil
+	}
+
+	datos, err := s.datosDocumento(orden, time.Now(), true)
+	if err != nil {
+		return nil, err
+	}
+	contenido, err := s.render(datos, formato)
+	if err != nil {
+		return nil, err
+	}
+	archivo.Contenido = contenido
+	archivo.Hash = datos.Hash
+	archivo.Borrador = true
+	return archivo, nil
+}
+
+// ArchiveOrderDocument genera el documento de la orden en HTML y PDF y archiva ambas copias. Se
+// llama al enviar la orden; el documento retornado debe guardarse en la orden.
+func (s *documentService) ArchiveOrderDocument(orden *models.OrdenCompra) (*models.DocumentoOrden, error) {
+	fechaEmision := time.Now().UTC()
+	datos, err := s.datosDocumento(orden, fechaEmision, false)
+	if err != nil {
+		return nil, err
+	}
+
+	html, err := s.renderer.RenderHTML(datos)
+	if err != nil {
+		return nil, err
+	}
+	pdf, err := s.renderer.RenderPDF(datos)
+	if err != nil {
+		return nil, err
+	}
+
+	prefijo := fmt.Sprintf("orders/%s/%s-%s", orden.OrdenID, orden.NumeroOrden, fechaEmision.Format("20060102T150405Z"))
+	documento := &models.DocumentoOrden{
+		Hash:         datos.Hash,
+		ClaveHTML:    prefijo + ".html",
+		ClavePDF:     prefijo + ".pdf",
+		FechaEmision: fechaEmision,
+	}
+
+	if err := s.storage.Put(documento.ClaveHTML, html, contentTypeDocumento(models.FormatoDocumentoHTML)); err != nil {
+		s.log.Errorf("Error archiving HTML document of order %s: %v", orden.OrdenID, err)
+		return nil, err
+	}
+	if err := s.storage.Put(documento.ClavePDF, pdf, contentTypeDocumento(models.FormatoDocumentoPDF)); err != nil {
+		s.log.Errorf("Error archiving PDF document of order %s: %v", orden.OrdenID, err)
+		s.DeleteOrderDocument(documento)
+		return nil, err
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"orden_id":     orden.OrdenID,
+		"numero_orden": orden.NumeroOrden,
+		"hash":         documento.Hash,
+	}).Info("Order document archived")
+
+	return documento, nil
+}
+
+// DeleteOrderDocument borra las copias de un documento que no llegó a guardarse en su orden
+func (s *documentService) DeleteOrderDocument(documento *models.DocumentoOrden) error {
+	var errs []error
+	for _, clave := range []string{documento.ClaveHTML, documento.ClavePDF} {
+		if err := s.storage.Delete(clave); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			s.log.Errorf("Error deleting order document %s: %v", clave, err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// VerifyOrderDocument verifica si el hash corresponde al documento emitido de la orden y si los
+// datos de la orden siguen siendo los que se enviaron al proveedor
+func (s *documentService) VerifyOrderDocument(ordenID, hash string) (*VerificacionDocumento, error) {
+	orden, err := s.orderRepo.GetByID(ordenID)
+	if err != nil {
+		return nil, err
+	}
+	if orden == nil {
+		return nil, ErrOrderNotFound
+	}
+	if orden.Documento == nil {
+		return nil, ErrDocumentNotIssued
+	}
+
+	actual, err := document.CalcularHash(orden, orden.Documento.FechaEmision)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VerificacionDocumento{
+		OrdenID:      orden.OrdenID,
+		NumeroOrden:  orden.NumeroOrden,
+		ProveedorID:  orden.ProveedorID,
+		FechaEmision: orden.Documento.FechaEmision,
+		Hash:         orden.Documento.Hash,
+		Valido:       strings.EqualFold(strings.TrimSpace(hash), orden.Documento.Hash),
+		Vigente:      actual == orden.Documento.Hash,
+	}, nil
+}
+
+// datosDocumento reúne los datos que se imprimen en el documento: proveedor, productos con su
+// cadena de frío, totales, términos y hash de verificación
+func (s *documentService) datosDocumento(orden *models.OrdenCompra, fechaEmision time.Time, borrador bool) (*document.Datos, error) {
+	hash, err := document.CalcularHash(orden, fechaEmision)
+	if err != nil {
+		return nil, err
+	}
+
+	datos := &document.Datos{
+		Orden:        orden,
+		Comprador:    s.config.Comprador,
+		Proveedor:    s.proveedorDocumento(orden.ProveedorID),
+		Terminos:     s.config.Terminos,
+		FechaEmision: fechaEmision,
+		Hash:         hash,
+		Borrador:     borrador,
+	}
+	if s.config.URLVerificacion != "" {
+		datos.URLVerificacion = fmt.Sprintf("%s/orders/%s/document/verify?hash=%s",
+			strings.TrimRight(s.config.URLVerificacion, "/"), orden.OrdenID, hash)
+	}
+
+	for i, item := range orden.Items {
+		producto, err := s.productRepo.GetByID(item.ProductoID)
+		if err != nil {
+			return nil, err
+		}
+		linea := document.Linea{Numero: i + 1, Item: item, Producto: item.ProductoID}
+		if producto != nil {
+			linea.Producto = producto.Nombre
+			if c := producto.Condiciones; c != nil {
+				linea.CadenaFrio = c.CadenaFrioRequerida
+				linea.TempMinima = c.TemperaturaMinima
+				linea.TempMaxima = c.TemperaturaMaxima
+				linea.Condiciones = c.CondicionesRequeridas
+			}
+		}
+		datos.Lineas = append(datos.Lineas, linea)
+	}
+
+	return datos, nil
+}
+
+// proveedorDocumento obtiene los datos del proveedor de supplier-service. Si no responde, usa el
+// nombre de la proyección local para no bloquear el envío de la orden.
+func (s *documentService) proveedorDocumento(proveedorID string) document.Proveedor {
+	datos := document.Proveedor{ProveedorID: proveedorID, Nombre: proveedorID}
+
+	proveedor, err := s.supplierClient.GetSupplier(proveedorID)
+	if err == nil && proveedor != nil {
+		datos.Nombre = proveedor.NombreLegal
+		datos.RazonSocial = proveedor.RazonSocial
+		datos.IdentificacionFiscal = proveedor.IdentificacionFiscal
+		datos.Contacto = proveedor.ContactoPrincipal()
+		return datos
+	}
+	if err != nil {
+		s.log.Warnf("Error getting supplier %s for order document, using local projection: %v", proveedorID, err)
+	}
+
+	proyectado, err := s.projectionRepo.Get(proveedorID)
+	if err != nil {
+		s.log.Warnf("Error getting projected supplier %s: %v", proveedorID, err)
+	}
+	if proyectado != nil {
+		datos.Nombre = proyectado.NombreLegal
+	}
+	return datos
+}
+
+// render genera el documento en el formato indicado
+func (s *documentService) render(datos *document.Datos, formato models.FormatoDocumento) ([]byte, error) {
+	if formato == models.FormatoDocumentoPDF {
+		return s.renderer.RenderPDF(datos)
+	}
+	return s.renderer.RenderHTML(datos)
+}
+
+// leerCopia lee una copia archivada del documento
+func (s *documentService) leerCopia(clave string) ([]byte, error) {
+	reader, err := s.storage.Get(clave)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// nombreDocumento arma el nombre de archivo del documento de la orden
+func nombreDocumento(orden *models.OrdenCompra, formato models.FormatoDocumento) string {
+	nombre := orden.NumeroOrden
+	if nombre == "" {
+		nombre = orden.OrdenID
+	}
+	return nombre + "." + string(formato)
+}
+
+// contentTypeDocumento retorna el content type de un formato de documento
+func contentTypeDocumento(formato models.FormatoDocumento) string {
+	if formato == models.FormatoDocumentoPDF {
+		return "application/pdf"
+	}
+	return "text/html; charset=utf-8"
+}
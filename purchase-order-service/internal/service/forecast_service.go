@@ -0,0 +1,181 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"mediplus/purchase-order-service/internal/events"
+	"mediplus/purchase-order-service/internal/models"
+	"mediplus/purchase-order-service/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ForecastConfig define los parámetros de los métodos, cuántos días de consumo se usan y el
+// tiempo de entrega que se asume para los productos que no lo definen
+type ForecastConfig struct {
+	Parametros               models.ParametrosPronostico
+	DiasHistoria             int
+	TiempoEntregaDiasDefecto int
+}
+
+// ForecastService define la interfaz para el pronóstico de demanda
+type ForecastService interface {
+	ForecastDemand(productoID string, horizonteDias int, metodo models.MetodoPronostico) (*models.PronosticoDemanda, error)
+	CheckHighDemand() (int, error)
+}
+
+// forecastService implementa ForecastService con el consumo registrado en el libro de movimientos
+type forecastService struct {
+	productRepo  repository.ProductRepository
+	movementRepo repository.MovementRepository
+	eventBus     events.EventBus
+	config       ForecastConfig
+	log          *logrus.Logger
+}
+
+// NewForecastService crea una nueva instancia de ForecastService
+func NewForecastService(
+	productRepo repository.ProductRepository,
+	movementRepo repository.MovementRepository,
+	eventBus events.EventBus,
+	config ForecastConfig,
+	log *logrus.Logger,
+) ForecastService {
+	return &forecastService{
+		productRepo:  productRepo,
+		movementRepo: movementRepo,
+		eventBus:     eventBus,
+		config:       config,
+		log:          log,
+	}
+}
+
+// ForecastDemand pronostica la demanda diaria de un producto para los próximos días. Sin
+// horizonte se usa el tiempo de entrega del producto; sin método, el de menor error.
+func (s *forecastService) ForecastDemand(productoID string, horizonteDias int, metodo models.MetodoPronostico) (*models.PronosticoDemanda, error) {
+	producto, err := s.productRepo.GetByID(productoID)
+	if err != nil {
+		return nil, err
+	}
+
+	if producto == nil {
+		return nil, ErrProductNotFound
+	}
+
+	if horizonteDias <= 0 {
+		horizonteDias = s.tiempoEntrega(producto)
+	}
+
+	return s.pronosticar(producto, horizonteDias, metodo)
+}
+
+// CheckHighDemand pronostica la demanda de cada producto durante su tiempo de entrega y emite
+// PronosticoDemandaAltaEvent para los que no alcanzarían a cubrirla con el stock actual.
+// Retorna la cantidad de eventos emitidos.
+func (s *forecastService) CheckHighDemand() (int, error) {
+	productos, err := s.productRepo.ListAll()
+	if err != nil {
+		s.log.Errorf("Error listing products for demand forecast: %v", err)
+		return 0, err
+	}
+
+	emitidos := 0
+	for _, producto := range productos {
+		pronostico, err := s.pronosticar(producto, s.tiempoEntrega(producto), "")
+		if errors.Is(err, models.ErrHistorialInsuficiente) {
+			continue
+		}
+		if err != nil {
+			s.log.Errorf("Error forecasting demand for product %s: %v", producto.ProductoID, err)
+			continue
+		}
+
+		if pronostico.DemandaHorizonte <= float64(producto.StockActual) {
+			continue
+		}
+
+		s.publicarDemandaAlta(producto, pronostico)
+		emitidos++
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"productos": len(productos),
+		"alertas":   emitidos,
+	}).Info("Demand forecast check finished")
+
+	return emitidos, nil
+}
+
+// pronosticar arma la serie de consumo diario del producto y la proyecta
+func (s *forecastService) pronosticar(producto *models.Producto, horizonteDias int, metodo models.MetodoPronostico) (*models.PronosticoDemanda, error) {
+	movimientos, err := s.movementRepo.ListByProducto(producto.ProductoID)
+	if err != nil {
+		return nil, err
+	}
+
+	// La serie empieza con el primer movimiento del producto para no contar como consumo cero
+	// los días en que aún no existía, y termina ayer porque el día en curso está incompleto
+	ahora := time.Now()
+	desde := ahora.AddDate(0, 0, -s.config.DiasHistoria)
+	if len(movimientos) > 0 && movimientos[0].FechaMovimiento.After(desde) {
+		desde = movimientos[0].FechaMovimiento
+	}
+
+	serie := models.SerieConsumoDiario(movimientos, desde, ahora)
+	pronostico, err := models.PronosticarDemanda(serie, ahora, horizonteDias, metodo, s.config.Parametros)
+	if err != nil {
+		return nil, err
+	}
+
+	pronostico.ProductoID = producto.ProductoID
+	return pronostico, nil
+}
+
+// tiempoEntrega retorna los días de entrega del producto: los de su política de reposición, el
+// tiempo máximo de entrega de sus condiciones o, si no define ninguno, el valor por defecto
+func (s *forecastService) tiempoEntrega(producto *models.Producto) int {
+	if producto.PoliticaReposicion != nil && producto.PoliticaReposicion.TiempoEntregaDias > 0 {
+		return producto.PoliticaReposicion.TiempoEntregaDias
+	}
+	if producto.Condiciones != nil && producto.Condiciones.TiempoMaximoEntrega > 0 {
+		return producto.Condiciones.TiempoMaximoEntrega
+	}
+	return s.config.TiempoEntregaDiasDefecto
+}
+
+// publicarDemandaAlta emite el evento de alta demanda con la probabilidad de que la demanda
+// del tiempo de entrega supere el stock actual como confianza del pronóstico
+func (s *forecastService) publicarDemandaAlta(producto *models.Producto, pronostico *models.PronosticoDemanda) {
+	demanda := int(math.Ceil(pronostico.DemandaHorizonte))
+
+	event := &events.PronosticoDemandaAltaEvent{
+		EventID:    uuid.New().String(),
+		EventType:  events.EventTypePronosticoDemandaAlta,
+		ProductoID: producto.ProductoID,
+		Timestamp:  time.Now(),
+	}
+
+	event.Data.NombreProducto = producto.Nombre
+	event.Data.DemandaPronosticada = demanda
+	event.Data.StockActual = producto.StockActual
+	event.Data.CantidadRequerida = demanda - producto.StockActual
+	event.Data.ConfianzaPronostico = pronostico.ProbabilidadSuperar(float64(producto.StockActual))
+	event.Data.MetodoPronostico = string(pronostico.Metodo)
+	event.Data.HorizonteDias = pronostico.HorizonteDias
+	event.Data.LimiteInferior = pronostico.LimiteInferior
+	event.Data.LimiteSuperior = pronostico.LimiteSuperior
+
+	s.log.WithFields(logrus.Fields{
+		"producto_id":          producto.ProductoID,
+		"demanda_pronosticada": demanda,
+		"stock_actual":         producto.StockActual,
+		"metodo":               pronostico.Metodo,
+		"horizonte_dias":       pronostico.HorizonteDias,
+	}).Warn("Forecast demand over lead time exceeds stock")
+
+	if err := s.eventBus.Publish(events.TopicStockEvents, event); err != nil {
+		s.log.Errorf("Error publishing high demand forecast event: %v", err)
+	}
+}
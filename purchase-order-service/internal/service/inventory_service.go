@@ -0,0 +1,359 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"mediplus/purchase-order-service/internal/events"
+	"mediplus/purchase-order-service/internal/models"
+	"mediplus/purchase-order-service/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrProductNotFound se retorna cuando el producto indicado no existe
+var ErrProductNotFound = errors.New("product not found")
+
+// ErrLotNotFound se retorna cuando el lote indicado no existe para el producto
+var ErrLotNotFound = errors.New("lot not found")
+
+// InventoryService define la interfaz para el libro de movimientos de inventario
+type InventoryService interface {
+	RecordMovement(productoID string, tipo models.TipoMovimiento, cantidad int, motivo, usuarioID, documentoReferencia, numeroLote string, fechaVencimiento *time.Time) (*models.MovimientoInventario, error)
+	ListMovements(productoID string) ([]*models.MovimientoInventario, error)
+	ReconcileProduct(productoID string) (*models.ConciliacionStock, error)
+	ReconcileAll() ([]*models.ConciliacionStock, error)
+	ListLots(productoID string) ([]*models.Lote, error)
+	ListLotsNearExpiry(dias int) ([]*models.Lote, error)
+	ExpireLots() ([]*models.Lote, error)
+	DamageLot(productoID, numeroLote, motivo string) (*models.Lote, error)
+}
+
+// inventoryService implementa InventoryService
+type inventoryService struct {
+	productRepo  repository.ProductRepository
+	movementRepo repository.MovementRepository
+	lotRepo      repository.LotRepository
+	eventBus     events.EventBus
+	log          *logrus.Logger
+}
+
+// NewInventoryService crea una nueva instancia de InventoryService
+func NewInventoryService(
+	productRepo repository.ProductRepository,
+	movementRepo repository.MovementRepository,
+	lotRepo repository.LotRepository,
+	eventBus events.EventBus,
+	log *logrus.Logger,
+) InventoryService {
+	return &inventoryService{
+		productRepo:  productRepo,
+		movementRepo: movementRepo,
+		lotRepo:      lotRepo,
+		eventBus:     eventBus,
+		log:          log,
+	}
+}
+
+// RecordMovement registra un movimiento en el libro y lo aplica al stock del producto.
+// Las entradas con número de lote crean o suman al lote; las salidas descuentan del lote
+// indicado o, si no se indica, de los lotes que vencen primero (FEFO).
+func (s *inventoryService) RecordMovement(productoID string, tipo models.TipoMovimiento, cantidad int, motivo, usuarioID, documentoReferencia, numeroLote string, fechaVencimiento *time.Time) (*models.MovimientoInventario, error) {
+	producto, err := s.productRepo.GetByID(productoID)
+	if err != nil {
+		return nil, err
+	}
+
+	if producto == nil {
+		return nil, ErrProductNotFound
+	}
+
+	movimiento, err := models.NewMovimientoInventario(productoID, tipo, cantidad, motivo, usuarioID, documentoReferencia)
+	if err != nil {
+		return nil, err
+	}
+
+	movimiento.Lotes, err = s.asignarLotes(producto, movimiento, numeroLote, fechaVencimiento)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.movementRepo.Record(movimiento)
+	if errors.Is(err, repository.ErrProductoNoEncontrado) {
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"producto_id":   productoID,
+		"movimiento_id": movimiento.MovimientoID,
+		"tipo":          movimiento.Tipo,
+		"cantidad":      movimiento.Cantidad,
+		"usuario_id":    movimiento.UsuarioID,
+		"lotes":         len(movimiento.Lotes),
+	}).Info("Inventory movement recorded")
+
+	return movimiento, nil
+}
+
+// asignarLotes decide a qué lotes corresponde un movimiento. Una salida sin lote explícito
+// se reparte por FEFO y lo que no cubren los lotes debe salir del stock sin lote.
+func (s *inventoryService) asignarLotes(producto *models.Producto, movimiento *models.MovimientoInventario, numeroLote string, fechaVencimiento *time.Time) ([]models.MovimientoLote, error) {
+	if movimiento.Cantidad > 0 {
+		if numeroLote == "" {
+			return nil, nil
+		}
+		return []models.MovimientoLote{{
+			NumeroLote:       numeroLote,
+			Cantidad:         movimiento.Cantidad,
+			FechaVencimiento: fechaVencimiento,
+		}}, nil
+	}
+
+	ahora := time.Now()
+	if numeroLote != "" {
+		lote, err := s.lotRepo.GetByNumero(producto.ProductoID, numeroLote)
+		if err != nil {
+			return nil, err
+		}
+		if lote == nil {
+			return nil, ErrLotNotFound
+		}
+		if lote.Estado != models.EstadoLoteActivo {
+			return nil, fmt.Errorf("%w: el lote %s está %s", models.ErrMovimientoInvalido, numeroLote, lote.Estado)
+		}
+		if movimiento.Tipo == models.MovimientoConsumo && lote.Vencido(ahora) {
+			return nil, fmt.Errorf("%w: el lote %s está vencido", models.ErrMovimientoInvalido, numeroLote)
+		}
+		return []models.MovimientoLote{{
+			NumeroLote: numeroLote,
+			Cantidad:   movimiento.Cantidad,
+		}}, nil
+	}
+
+	lotes, err := s.lotRepo.ListByProducto(producto.ProductoID)
+	if err != nil {
+		return nil, err
+	}
+
+	asignaciones, restante := models.AsignarFEFO(lotes, -movimiento.Cantidad, ahora)
+	if restante > models.StockSinLote(producto, lotes) {
+		return nil, repository.ErrStockInsuficiente
+	}
+
+	return asignaciones, nil
+}
+
+// ListMovements lista el libro de movimientos de un producto
+func (s *inventoryService) ListMovements(productoID string) ([]*models.MovimientoInventario, error) {
+	producto, err := s.productRepo.GetByID(productoID)
+	if err != nil {
+		return nil, err
+	}
+
+	if producto == nil {
+		return nil, ErrProductNotFound
+	}
+
+	return s.movementRepo.ListByProducto(productoID)
+}
+
+// ReconcileProduct compara el stock proyectado de un producto con la suma de su libro
+func (s *inventoryService) ReconcileProduct(productoID string) (*models.ConciliacionStock, error) {
+	producto, err := s.productRepo.GetByID(productoID)
+	if err != nil {
+		return nil, err
+	}
+
+	if producto == nil {
+		return nil, ErrProductNotFound
+	}
+
+	return s.conciliar(producto)
+}
+
+// ReconcileAll concilia todos los productos y retorna solo los que presentan diferencias
+func (s *inventoryService) ReconcileAll() ([]*models.ConciliacionStock, error) {
+	productos, err := s.productRepo.ListAll()
+	if err != nil {
+		return nil, err
+	}
+
+	diferencias := []*models.ConciliacionStock{}
+	for _, producto := range productos {
+		conciliacion, err := s.conciliar(producto)
+		if err != nil {
+			s.log.Errorf("Error reconciling product %s: %v", producto.ProductoID, err)
+			continue
+		}
+		if !conciliacion.Conciliado {
+			diferencias = append(diferencias, conciliacion)
+		}
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"productos":   len(productos),
+		"diferencias": len(diferencias),
+	}).Info("Stock reconciliation completed")
+
+	return diferencias, nil
+}
+
+// conciliar calcula la conciliación de un producto y deja aviso si hay diferencia
+func (s *inventoryService) conciliar(producto *models.Producto) (*models.ConciliacionStock, error) {
+	movimientos, err := s.movementRepo.ListByProducto(producto.ProductoID)
+	if err != nil {
+		return nil, err
+	}
+
+	conciliacion := producto.Conciliar(movimientos)
+	if !conciliacion.Conciliado {
+		s.log.WithFields(logrus.Fields{
+			"producto_id":  producto.ProductoID,
+			"stock_actual": conciliacion.StockActual,
+			"stock_libro":  conciliacion.StockLibro,
+			"diferencia":   conciliacion.Diferencia,
+		}).Warn("Stock drift detected between projection and movement ledger")
+	}
+
+	return conciliacion, nil
+}
+
+// ListLots lista los lotes de un producto en orden FEFO
+func (s *inventoryService) ListLots(productoID string) ([]*models.Lote, error) {
+	producto, err := s.productRepo.GetByID(productoID)
+	if err != nil {
+		return nil, err
+	}
+
+	if producto == nil {
+		return nil, ErrProductNotFound
+	}
+
+	lotes, err := s.lotRepo.ListByProducto(productoID)
+	if err != nil {
+		return nil, err
+	}
+
+	models.OrdenarFEFO(lotes)
+	return lotes, nil
+}
+
+// ListLotsNearExpiry lista los lotes con existencias que vencen dentro de los próximos días
+func (s *inventoryService) ListLotsNearExpiry(dias int) ([]*models.Lote, error) {
+	lotes, err := s.lotRepo.ListWithStock()
+	if err != nil {
+		return nil, err
+	}
+
+	ahora := time.Now()
+	limite := ahora.AddDate(0, 0, dias)
+
+	porVencer := []*models.Lote{}
+	for _, lote := range lotes {
+		if lote.PorVencer(ahora, limite) {
+			porVencer = append(porVencer, lote)
+		}
+	}
+
+	models.OrdenarFEFO(porVencer)
+	return porVencer, nil
+}
+
+// ExpireLots da de baja los lotes vencidos que aún tienen existencias y emite un evento por
+// cada uno para que se evalúe su reposición
+func (s *inventoryService) ExpireLots() ([]*models.Lote, error) {
+	lotes, err := s.lotRepo.ListWithStock()
+	if err != nil {
+		return nil, err
+	}
+
+	ahora := time.Now()
+	vencidos := []*models.Lote{}
+	for _, lote := range lotes {
+		if !lote.Vencido(ahora) {
+			continue
+		}
+
+		if err := s.darDeBajaLote(lote, models.EstadoLoteVencido, models.MovimientoBajaVencimiento,
+			"Lote "+lote.NumeroLote+" vencido"); err != nil {
+			s.log.Errorf("Error expiring lot %s/%s: %v", lote.ProductoID, lote.NumeroLote, err)
+			continue
+		}
+
+		s.publicarLoteVencido(lote)
+		vencidos = append(vencidos, lote)
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"lotes_revisados": len(lotes),
+		"lotes_vencidos":  len(vencidos),
+	}).Info("Lot expiry check completed")
+
+	return vencidos, nil
+}
+
+// DamageLot marca un lote como dañado y da de baja sus existencias. Retorna el lote tal como
+// estaba antes de la baja, para informar la cantidad afectada.
+func (s *inventoryService) DamageLot(productoID, numeroLote, motivo string) (*models.Lote, error) {
+	lote, err := s.lotRepo.GetByNumero(productoID, numeroLote)
+	if err != nil {
+		return nil, err
+	}
+
+	if lote == nil {
+		return nil, ErrLotNotFound
+	}
+
+	if lote.Estado != models.EstadoLoteActivo || lote.CantidadDisponible == 0 {
+		s.log.Infof("Lot %s/%s has no active stock to write off (%s)", productoID, numeroLote, lote.Estado)
+		return lote, nil
+	}
+
+	if err := s.darDeBajaLote(lote, models.EstadoLoteDanado, models.MovimientoBajaDano, motivo); err != nil {
+		return nil, err
+	}
+
+	return lote, nil
+}
+
+// darDeBajaLote retira del stock todo lo disponible de un lote y lo deja en el estado indicado
+func (s *inventoryService) darDeBajaLote(lote *models.Lote, estado models.EstadoLote, tipo models.TipoMovimiento, motivo string) error {
+	movimiento, err := models.NewMovimientoInventario(lote.ProductoID, tipo, lote.CantidadDisponible,
+		motivo, "system", lote.NumeroLote)
+	if err != nil {
+		return err
+	}
+	movimiento.Lotes = []models.MovimientoLote{{
+		NumeroLote: lote.NumeroLote,
+		Cantidad:   movimiento.Cantidad,
+	}}
+
+	return s.movementRepo.WriteOffLot(movimiento, lote, estado)
+}
+
+// publicarLoteVencido emite el evento de lote vencido
+func (s *inventoryService) publicarLoteVencido(lote *models.Lote) {
+	event := &events.LoteVencidoEvent{
+		EventID:    uuid.New().String(),
+		EventType:  events.EventTypeLoteVencido,
+		ProductoID: lote.ProductoID,
+		Timestamp:  time.Now(),
+	}
+
+	if producto, err := s.productRepo.GetByID(lote.ProductoID); err == nil && producto != nil {
+		event.Data.NombreProducto = producto.Nombre
+	}
+	event.Data.NumeroLote = lote.NumeroLote
+	event.Data.FechaVencimiento = lote.FechaVencimiento
+	event.Data.CantidadVencida = lote.CantidadDisponible
+	event.Data.OrdenID = lote.OrdenID
+	event.Data.ProveedorID = lote.ProveedorID
+
+	if err := s.eventBus.Publish(events.TopicStockEvents, event); err != nil {
+		s.log.Errorf("Error publishing expired lot event: %v", err)
+	}
+}
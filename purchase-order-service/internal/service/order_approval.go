@@ -0,0 +1,312 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mediplus/purchase-order-service/internal/events"
+	"mediplus/purchase-order-service/internal/models"
+	"mediplus/purchase-order-service/internal/repository"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// LoadApprovalPolicy lee la política de aprobación de un archivo JSON. Sin archivo se usa la
+// política por defecto.
+func LoadApprovalPolicy(path string) (*models.PoliticaAprobacion, error) {
+	if path == "" {
+		return models.PoliticaAprobacionPorDefecto(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading approval policy: %w", err)
+	}
+
+	var politica models.PoliticaAprobacion
+	if err := json.Unmarshal(data, &politica); err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrPoliticaAprobacionInvalida, err)
+	}
+	if err := politica.Validar(); err != nil {
+		return nil, err
+	}
+
+	return &politica, nil
+}
+
+// GetApprovalPolicy retorna la política de aprobación vigente
+func (s *orderService) GetApprovalPolicy() *models.PoliticaAprobacion {
+	return s.politicaAprobacion
+}
+
+// ApproveOrder aprueba el nivel en curso de una orden. Cada aprobación vuelve a verificar el
+// presupuesto; al aprobarse el último nivel la orden queda GENERADA, reserva su monto y se
+// emite OrdenCompraGeneradaEvent.
+func (s *orderService) ApproveOrder(ordenID, usuarioID, rol, comentario string) (*models.OrdenCompra, error) {
+	orden, err := s.orderRepo.GetByID(ordenID)
+	if err != nil {
+		return nil, err
+	}
+
+	if orden == nil {
+		return nil, ErrOrderNotFound
+	}
+
+	actualizadaEn := orden.UpdatedAt
+	completa, err := orden.Aprobar(usuarioID, rol, comentario, s.politicaAprobacion, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.guardarOrden(orden, &actualizadaEn); err != nil {
+		s.log.Errorf("Error updating approved order: %v", err)
+		return nil, err
+	}
+
+	event := &events.OrdenAprobadaEvent{
+		EventID:   uuid.New().String(),
+		EventType: events.EventTypeOrdenAprobada,
+		OrdenID:   orden.OrdenID,
+		Timestamp: time.Now(),
+	}
+
+	event.Data.NumeroOrden = orden.NumeroOrden
+	event.Data.ProveedorID = orden.ProveedorID
+	event.Data.UsuarioID = usuarioID
+	event.Data.Rol = rol
+	event.Data.Comentario = comentario
+	event.Data.AprobacionCompleta = completa
+	if siguiente := orden.Aprobacion.NivelActual(); siguiente != nil {
+		event.Data.RolPendiente = siguiente.RolAsignado
+	}
+
+	if err := s.eventBus.Publish(events.TopicOrderEvents, event); err != nil {
+		s.log.Errorf("Error publishing order approved event: %v", err)
+	}
+
+	if completa {
+		s.publicarOrdenGenerada(orden)
+	}
+
+	return orden, nil
+}
+
+// RejectOrder rechaza una orden pendiente de aprobación; la orden queda RECHAZADA
+func (s *orderService) RejectOrder(ordenID, usuarioID, rol, comentario string) (*models.OrdenCompra, error) {
+	orden, err := s.orderRepo.GetByID(ordenID)
+	if err != nil {
+		return nil, err
+	}
+
+	if orden == nil {
+		return nil, ErrOrderNotFound
+	}
+
+	actualizadaEn := orden.UpdatedAt
+	if err := orden.Rechazar(usuarioID, rol, comentario, s.politicaAprobacion, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.guardarOrden(orden, &actualizadaEn); err != nil {
+		s.log.Errorf("Error updating rejected order: %v", err)
+		return nil, err
+	}
+
+	event := &events.OrdenRechazadaEvent{
+		EventID:   uuid.New().String(),
+		EventType: events.EventTypeOrdenRechazada,
+		OrdenID:   orden.OrdenID,
+		Timestamp: time.Now(),
+	}
+
+	event.Data.NumeroOrden = orden.NumeroOrden
+	event.Data.ProveedorID = orden.ProveedorID
+	event.Data.UsuarioID = usuarioID
+	event.Data.Rol = rol
+	event.Data.Comentario = comentario
+
+	if err := s.eventBus.Publish(events.TopicOrderEvents, event); err != nil {
+		s.log.Errorf("Error publishing order rejected event: %v", err)
+	}
+
+	return orden, nil
+}
+
+// ListPendingApprovals lista las órdenes pendientes de aprobación; con rol, solo aquellas cuyo
+// nivel en curso ese rol puede decidir
+func (s *orderService) ListPendingApprovals(rol string) ([]*models.OrdenCompra, error) {
+	ordenes, err := s.orderRepo.ListByEstado(models.EstadoPendienteAprobacion)
+	if err != nil {
+		return nil, err
+	}
+
+	if rol == "" {
+		return ordenes, nil
+	}
+
+	pendientes := []*models.OrdenCompra{}
+	for _, orden := range ordenes {
+		if orden.Aprobacion == nil {
+			continue
+		}
+		nivel := orden.Aprobacion.NivelActual()
+		if nivel != nil && s.politicaAprobacion.PuedeDecidir(rol, nivel.RolAsignado) {
+			pendientes = append(pendientes, orden)
+		}
+	}
+
+	return pendientes, nil
+}
+
+// EscalateOverdueApprovals escala los niveles de aprobación cuyo plazo venció y retorna
+// cuántos se escalaron
+func (s *orderService) EscalateOverdueApprovals() (int, error) {
+	ordenes, err := s.orderRepo.ListByEstado(models.EstadoPendienteAprobacion)
+	if err != nil {
+		s.log.Errorf("Error listing orders pending approval: %v", err)
+		return 0, err
+	}
+
+	escaladas := 0
+	ahora := time.Now()
+	for _, orden := range ordenes {
+		actualizadaEn := orden.UpdatedAt
+		nivel, rolAnterior := orden.EscalarAprobacion(s.politicaAprobacion, ahora)
+		if nivel == nil {
+			continue
+		}
+
+		orden.UpdatedAt = ahora
+		if err := s.orderRepo.Update(orden, actualizadaEn); err != nil {
+			// Si la orden cambió (por ejemplo, se aprobó) se vuelve a evaluar en la próxima revisión
+			if !errors.Is(err, repository.ErrOrdenModificada) {
+				s.log.Errorf("Error escalating approval for order %s: %v", orden.OrdenID, err)
+			}
+			continue
+		}
+
+		event := &events.OrdenAprobacionEscaladaEvent{
+			EventID:   uuid.New().String(),
+			EventType: events.EventTypeOrdenAprobacionEscalada,
+			OrdenID:   orden.OrdenID,
+			Timestamp: ahora,
+		}
+
+		event.Data.NumeroOrden = orden.NumeroOrden
+		event.Data.RolRequerido = nivel.RolRequerido
+		event.Data.RolAnterior = rolAnterior
+		event.Data.RolAsignado = nivel.RolAsignado
+		event.Data.Escalamientos = nivel.Escalamientos
+		event.Data.Vence = *nivel.Vence
+
+		s.log.WithFields(logrus.Fields{
+			"orden_id":     orden.OrdenID,
+			"rol_anterior": rolAnterior,
+			"rol_asignado": nivel.RolAsignado,
+		}).Warn("Order approval overdue, escalating")
+
+		if err := s.eventBus.Publish(events.TopicOrderEvents, event); err != nil {
+			s.log.Errorf("Error publishing approval escalated event: %v", err)
+		}
+		escaladas++
+	}
+
+	return escaladas, nil
+}
+
+// evaluarAprobacion aplica la política a la orden con la categoría de sus productos y el score
+// del proveedor en la proyección local
+func (s *orderService) evaluarAprobacion(orden *models.OrdenCompra) (*models.AprobacionOrden, error) {
+	contexto := models.ContextoAprobacion{
+		MontoTotal:            orden.ValorTotal(),
+		Prioridad:             orden.Prioridad,
+		PrecioFueraTolerancia: orden.PreciosFueraTolerancia(),
+	}
+
+	for _, item := range orden.Items {
+		producto, err := s.productRepo.GetByID(item.ProductoID)
+		if err != nil {
+			return nil, err
+		}
+		if producto != nil && producto.Categoria != "" {
+			contexto.Categorias = append(contexto.Categorias, producto.Categoria)
+		}
+	}
+
+	if orden.ProveedorID != "" {
+		proveedor, err := s.projectionRepo.Get(orden.ProveedorID)
+		if err != nil {
+			return nil, err
+		}
+		if proveedor != nil {
+			contexto.ScoreProveedor = &proveedor.ScoreGeneral
+		}
+	}
+
+	return s.politicaAprobacion.Evaluar(contexto, time.Now()), nil
+}
+
+// reevaluarAprobacion vuelve a aplicar la política a una orden editada antes de enviarse.
+// Retorna true si la orden volvió a aprobación o dejó de necesitarla.
+func (s *orderService) reevaluarAprobacion(orden *models.OrdenCompra) (bool, error) {
+	if orden.EstadoOrden != models.EstadoGenerada && orden.EstadoOrden != models.EstadoPendienteAprobacion {
+		return false, nil
+	}
+
+	nueva, err := s.evaluarAprobacion(orden)
+	if err != nil {
+		return false, err
+	}
+
+	if nueva == nil {
+		if orden.EstadoOrden != models.EstadoPendienteAprobacion {
+			return false, nil
+		}
+		return true, orden.RetirarAprobacion()
+	}
+
+	if !orden.RequiereNuevaAprobacion(nueva) {
+		return false, nil
+	}
+
+	return true, orden.SolicitarAprobacion(nueva, time.Now())
+}
+
+// publicarAprobacionSolicitada emite el evento de una orden que quedó pendiente de aprobación
+func (s *orderService) publicarAprobacionSolicitada(orden *models.OrdenCompra) {
+	event := &events.OrdenAprobacionSolicitadaEvent{
+		EventID:   uuid.New().String(),
+		EventType: events.EventTypeOrdenAprobacionSolicitada,
+		OrdenID:   orden.OrdenID,
+		Timestamp: time.Now(),
+	}
+
+	event.Data.NumeroOrden = orden.NumeroOrden
+	event.Data.ProveedorID = orden.ProveedorID
+	event.Data.Prioridad = string(orden.Prioridad)
+	event.Data.Moneda = orden.Moneda
+	event.Data.ValorTotal = orden.Aprobacion.MontoTotal.Float64()
+	event.Data.Reglas = orden.Aprobacion.Reglas
+	event.Data.Expedita = orden.Aprobacion.Expedita
+	for _, nivel := range orden.Aprobacion.Niveles {
+		event.Data.RolesRequeridos = append(event.Data.RolesRequeridos, nivel.RolRequerido)
+	}
+	if nivel := orden.Aprobacion.NivelActual(); nivel != nil {
+		event.Data.RolPendiente = nivel.RolAsignado
+		event.Data.Vence = *nivel.Vence
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"orden_id":  orden.OrdenID,
+		"reglas":    orden.Aprobacion.Reglas,
+		"expedita":  orden.Aprobacion.Expedita,
+		"prioridad": orden.Prioridad,
+	}).Info("Order requires approval")
+
+	if err := s.eventBus.Publish(events.TopicOrderEvents, event); err != nil {
+		s.log.Errorf("Error publishing approval requested event: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"mediplus/purchase-order-service/internal/models"
+	"mediplus/purchase-order-service/internal/repository"
+	"time"
+)
+
+// BudgetConfig define la periodicidad de los presupuestos y el centro de costo al que se cargan
+// las órdenes que no indican uno (por ejemplo, las de reposición automática)
+type BudgetConfig struct {
+	Periodicidad       models.PeriodicidadPresupuesto
+	CentroCostoDefecto string
+}
+
+// guardarOrden persiste la orden ajustando el compromiso de su presupuesto en la misma
+// escritura. actualizadaEn es el updated_at leído, o nil para una orden nueva, que además se
+// numera en esa escritura.
+func (s *orderService) guardarOrden(orden *models.OrdenCompra, actualizadaEn *time.Time) error {
+	movimiento, err := s.ajustarPresupuesto(orden)
+	if err != nil {
+		return err
+	}
+
+	if actualizadaEn == nil {
+		return s.crearOrden(orden, movimiento)
+	}
+
+	orden.UpdatedAt = time.Now()
+	if movimiento == nil {
+		return s.orderRepo.Update(orden, *actualizadaEn)
+	}
+
+	return s.orderRepo.SaveWithBudget(orden, actualizadaEn, movimiento)
+}
+
+// ajustarPresupuesto calcula el movimiento del presupuesto que corresponde al estado e items de
+// la orden. Una orden pendiente de aprobación no reserva, pero debe caber en el disponible.
+func (s *orderService) ajustarPresupuesto(orden *models.OrdenCompra) (*models.MovimientoPresupuesto, error) {
+	if orden.CentroCostoID == "" {
+		return nil, nil
+	}
+
+	periodo := s.periodoPresupuesto(orden)
+	if orden.EstadoOrden == models.EstadoPendienteAprobacion {
+		if err := s.verificarDisponible(orden, periodo); err != nil {
+			return nil, err
+		}
+	}
+
+	return orden.AjustarCompromiso(periodo), nil
+}
+
+// verificarDisponible comprueba que el presupuesto de la orden cubra su monto pendiente,
+// contando lo que la orden ya tiene reservado
+func (s *orderService) verificarDisponible(orden *models.OrdenCompra, periodo string) error {
+	presupuesto, err := s.budgetRepo.Get(orden.CentroCostoID, periodo)
+	if err != nil {
+		return err
+	}
+
+	if presupuesto == nil {
+		return fmt.Errorf("%w: centro de costo %s, período %s",
+			repository.ErrPresupuestoNoEncontrado, orden.CentroCostoID, periodo)
+	}
+
+	disponible := presupuesto.MontoDisponible
+	if orden.Compromiso != nil {
+		disponible += orden.Compromiso.MontoComprometido
+	}
+	if requerido := orden.MontoPendiente(); requerido > disponible {
+		return fmt.Errorf("%w: centro de costo %s, período %s: disponible %s, requerido %s",
+			repository.ErrPresupuestoInsuficiente, orden.CentroCostoID, periodo, disponible, requerido)
+	}
+
+	return nil
+}
+
+// periodoPresupuesto retorna el período al que se carga la orden: el de su compromiso o, si aún
+// no tiene, el de su fecha de generación
+func (s *orderService) periodoPresupuesto(orden *models.OrdenCompra) string {
+	if orden.Compromiso != nil {
+		return orden.Compromiso.Periodo
+	}
+	return s.budgetConfig.Periodicidad.Periodo(orden.FechaGeneracion)
+}
+
+// PresupuestoRechazado indica si el error corresponde a un presupuesto que no admite la orden
+func PresupuestoRechazado(err error) bool {
+	return errors.Is(err, repository.ErrPresupuestoNoEncontrado) ||
+		errors.Is(err, repository.ErrPresupuestoInsuficiente)
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"errors"
+	"mediplus/purchase-order-service/internal/models"
+	"mediplus/purchase-order-service/internal/repository"
+)
+
+// NumberingConfig define el prefijo de las series con que se numeran las órdenes
+type NumberingConfig struct {
+	Prefijo string
+}
+
+// maxIntentosNumeracion es la cantidad de veces que se reintenta numerar una orden cuando otra
+// toma el mismo número
+const maxIntentosNumeracion = 3
+
+// crearOrden asigna a la orden nueva el número siguiente de su serie (prefijo, origen y año de
+// generación) y la crea junto con el movimiento de su presupuesto. Si otra orden tomó ese número
+// entre la lectura del contador y la escritura, se reintenta con el siguiente.
+func (s *orderService) crearOrden(orden *models.OrdenCompra, movimiento *models.MovimientoPresupuesto) error {
+	prefijo := s.numberingConfig.Prefijo
+	if orden.OrigenNumeracion != "" {
+		prefijo += "-" + orden.OrigenNumeracion
+	}
+	serie := models.SerieNumeracion(prefijo, orden.FechaGeneracion)
+
+	for intento := 1; ; intento++ {
+		ultimo, err := s.numberRepo.UltimoNumero(serie)
+		if err != nil {
+			return err
+		}
+
+		numeracion := models.NuevaNumeracion(serie, ultimo)
+		orden.NumeroOrden = numeracion.Numero()
+
+		err = s.orderRepo.CreateNumbered(orden, numeracion, movimiento)
+		if errors.Is(err, repository.ErrNumeracionModificada) && intento < maxIntentosNumeracion {
+			s.log.Warnf("Order number sequence %s modified concurrently, retrying (attempt %d)", serie, intento)
+			continue
+		}
+		if err != nil {
+			orden.NumeroOrden = ""
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"fmt"
+	"mediplus/purchase-order-service/internal/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// resolverPrecios completa el precio de los items con el precio contratado o el precio base del
+// proveedor de la orden, tomado de la proyección local, y marca los precios manuales fuera de
+// tolerancia. Una orden ya enviada conserva los precios con que se envió.
+func (s *orderService) resolverPrecios(orden *models.OrdenCompra) error {
+	if orden.EstadoOrden != models.EstadoGenerada && orden.EstadoOrden != models.EstadoPendienteAprobacion {
+		return nil
+	}
+
+	precios := map[string]models.PrecioProveedor{}
+	if orden.ProveedorID != "" {
+		proveedor, err := s.projectionRepo.Get(orden.ProveedorID)
+		if err != nil {
+			return fmt.Errorf("error getting supplier prices: %w", err)
+		}
+		if proveedor != nil {
+			precios = proveedor.PreciosVigentes(orden.Moneda, time.Now())
+		}
+	}
+
+	if err := orden.ResolverPrecios(precios, s.totalsConfig.ToleranciaPrecio); err != nil {
+		return err
+	}
+
+	for _, item := range orden.Items {
+		if item.PrecioFueraTolerancia {
+			s.log.WithFields(logrus.Fields{
+				"orden_id":          orden.OrdenID,
+				"producto_id":       item.ProductoID,
+				"precio_unitario":   item.PrecioUnitario,
+				"precio_referencia": item.PrecioReferencia,
+				"desvio_precio":     item.DesvioPrecio,
+			}).Warn("Manual price outside tolerance")
+		}
+	}
+
+	return nil
+}
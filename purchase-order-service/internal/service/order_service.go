@@ -1,15 +1,34 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"mediplus/purchase-order-service/internal/clients"
 	"mediplus/purchase-order-service/internal/events"
 	"mediplus/purchase-order-service/internal/models"
 	"mediplus/purchase-order-service/internal/repository"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
+// ErrColdChainIncompatible se retorna cuando el proveedor no puede mantener la cadena de frío de un item
+var ErrColdChainIncompatible = errors.New("supplier cannot maintain the required cold chain")
+
+// ErrNoQualifiedSupplier se retorna cuando ningún proveedor activo y calificado ofrece los productos de la orden
+var ErrNoQualifiedSupplier = errors.New("no active qualified supplier offers the requested products")
+
+// ErrUnknownSupplier se retorna cuando el proveedor de la orden no está en la proyección local
+var ErrUnknownSupplier = errors.New("unknown supplier")
+
+// ErrSupplierNotActive se retorna cuando el proveedor de la orden está suspendido o inactivo
+var ErrSupplierNotActive = errors.New("supplier is not active")
+
+// ErrOrderNotFound se retorna cuando la orden a la que se aplica un cambio de estado no existe
+var ErrOrderNotFound = errors.New("order not found")
+
 // OrderService define la interfaz para el servicio de órdenes
 type OrderService interface {
 	CreateOrder(orden *models.OrdenCompra) error
@@ -17,50 +36,130 @@ type OrderService interface {
 	UpdateOrder(orden *models.OrdenCompra) error
 	DeleteOrder(ordenID string) error
 	ListOrders() ([]*models.OrdenCompra, error)
+	SendOrder(ordenID string) error
 	ConfirmOrder(ordenID string) error
 	ReceiveOrder(ordenID string) error
-	AutoGenerateOrder(trigger string) error
+	RegisterReceipt(ordenID string, recepcion *models.Recepcion) (*models.OrdenCompra, error)
+	CancelOrder(ordenID, motivo string) error
+	AutoGenerateOrder(trigger string) (*models.ResumenReposicion, error)
+	AssignSupplier(orden *models.OrdenCompra) error
 	GetOrderByNumero(numeroOrden string) (*models.OrdenCompra, error)
 	ProcessStockLowEvent(productoID string) error
-	ProcessLoteDanadoEvent(productoID, loteID string, cantidadDanada int, temperaturaRegistrada float64) error
+	ProcessLoteDanadoEvent(productoID, loteID string, cantidadDanada int, motivoDanio string) error
+	ProcessLoteVencidoEvent(productoID, numeroLote string, cantidadVencida int) error
 	ProcessPronosticoDemandaAltaEvent(productoID string, demandaPronosticada int) error
 	ListOrdersByEstado(estado models.EstadoOrden) ([]*models.OrdenCompra, error)
 	ListOrdersByProveedor(proveedorID string) ([]*models.OrdenCompra, error)
+	ApproveOrder(ordenID, usuarioID, rol, comentario string) (*models.OrdenCompra, error)
+	RejectOrder(ordenID, usuarioID, rol, comentario string) (*models.OrdenCompra, error)
+	ListPendingApprovals(rol string) ([]*models.OrdenCompra, error)
+	EscalateOverdueApprovals() (int, error)
+	GetApprovalPolicy() *models.PoliticaAprobacion
+	GetTaxPolicy() *models.PoliticaImpuestos
 }
 
 // orderService implementa OrderService
 type orderService struct {
-	orderRepo   repository.OrderRepository
-	productRepo repository.ProductRepository
-	eventBus    events.EventBus
-	log         *logrus.Logger
+	orderRepo      repository.OrderRepository
+	productRepo    repository.ProductRepository
+	projectionRepo repository.SupplierProjectionRepository
+	supplierClient clients.SupplierClient
+	eventBus       events.EventBus
+	log            *logrus.Logger
+
+	politicaAprobacion *models.PoliticaAprobacion
+
+	budgetRepo   repository.BudgetRepository
+	budgetConfig BudgetConfig
+
+	totalsConfig TotalsConfig
+
+	numberRepo      repository.OrderNumberRepository
+	numberingConfig NumberingConfig
+
+	documentService DocumentService
 }
 
 // NewOrderService crea una nueva instancia de OrderService
 func NewOrderService(
 	orderRepo repository.OrderRepository,
 	productRepo repository.ProductRepository,
+	projectionRepo repository.SupplierProjectionRepository,
+	supplierClient clients.SupplierClient,
 	eventBus events.EventBus,
+	politicaAprobacion *models.PoliticaAprobacion,
+	budgetRepo repository.BudgetRepository,
+	budgetConfig BudgetConfig,
+	totalsConfig TotalsConfig,
+	numberRepo repository.OrderNumberRepository,
+	numberingConfig NumberingConfig,
+	documentService DocumentService,
 	log *logrus.Logger,
 ) OrderService {
 	return &orderService{
-		orderRepo:   orderRepo,
-		productRepo: productRepo,
-		eventBus:    eventBus,
-		log:         log,
+		orderRepo:          orderRepo,
+		productRepo:        productRepo,
+		projectionRepo:     projectionRepo,
+		supplierClient:     supplierClient,
+		eventBus:           eventBus,
+		log:                log,
+		politicaAprobacion: politicaAprobacion,
+		budgetRepo:         budgetRepo,
+		budgetConfig:       budgetConfig,
+		totalsConfig:       totalsConfig,
+		numberRepo:         numberRepo,
+		numberingConfig:    numberingConfig,
+		documentService:    documentService,
 	}
 }
 
-// CreateOrder crea una nueva orden
+// CreateOrder crea una nueva orden. Si la política de aprobación la alcanza, la orden queda
+// PENDIENTE_APROBACION y se emite OrdenCompraGeneradaEvent recién al aprobarse. La orden se
+// carga al presupuesto de su centro de costo: reserva el monto al crearse o, si requiere
+// aprobación, al aprobarse.
 func (s *orderService) CreateOrder(orden *models.OrdenCompra) error {
-	// Crear la orden
-	err := s.orderRepo.Create(orden)
+	if orden.CentroCostoID == "" {
+		orden.CentroCostoID = s.budgetConfig.CentroCostoDefecto
+	}
+
+	// Verificar que el proveedor asignado esté activo y pueda mantener la cadena de frío de los items
+	if err := s.verificarProveedorAsignado(orden); err != nil {
+		return err
+	}
+
+	// Los totales se guardan con la orden y son los que usan la aprobación, el presupuesto y los eventos
+	if err := s.calcularTotales(orden); err != nil {
+		return err
+	}
+
+	aprobacion, err := s.evaluarAprobacion(orden)
+	if err != nil {
+		return err
+	}
+	if aprobacion != nil {
+		if err := orden.SolicitarAprobacion(aprobacion, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	// Crear la orden reservando su presupuesto
+	err = s.guardarOrden(orden, nil)
 	if err != nil {
 		s.log.Errorf("Error creating order: %v", err)
 		return err
 	}
 
-	// Emitir evento de orden generada
+	if aprobacion != nil {
+		s.publicarAprobacionSolicitada(orden)
+		return nil
+	}
+
+	s.publicarOrdenGenerada(orden)
+	return nil
+}
+
+// publicarOrdenGenerada emite el evento de una orden lista para enviarse al proveedor
+func (s *orderService) publicarOrdenGenerada(orden *models.OrdenCompra) {
 	event := &events.OrdenCompraGeneradaEvent{
 		EventID:   uuid.New().String(),
 		EventType: events.EventTypeOrdenCompraGenerada,
@@ -73,20 +172,15 @@ func (s *orderService) CreateOrder(orden *models.OrdenCompra) error {
 	event.Data.MotivoGeneracion = orden.MotivoGeneracion
 	event.Data.Prioridad = string(orden.Prioridad)
 	event.Data.TotalItems = len(orden.Items)
+	event.Data.Moneda = orden.Moneda
+	event.Data.Subtotal = orden.Totales.Subtotal.Float64()
+	event.Data.Descuento = orden.Totales.Descuento.Float64()
+	event.Data.Impuesto = orden.Totales.Impuesto.Float64()
+	event.Data.ValorTotal = orden.ValorTotal().Float64()
 
-	// Calcular valor total
-	var valorTotal float64
-	for _, item := range orden.Items {
-		valorTotal += item.PrecioUnitario * float64(item.CantidadSolicitada)
-	}
-	event.Data.ValorTotal = valorTotal
-
-	err = s.eventBus.Publish(events.TopicOrderEvents, event)
-	if err != nil {
+	if err := s.eventBus.Publish(events.TopicOrderEvents, event); err != nil {
 		s.log.Errorf("Error publishing order generated event: %v", err)
 	}
-
-	return nil
 }
 
 // GetOrder obtiene una orden por su ID
@@ -94,14 +188,63 @@ func (s *orderService) GetOrder(ordenID string) (*models.OrdenCompra, error) {
 	return s.orderRepo.GetByID(ordenID)
 }
 
-// UpdateOrder actualiza una orden
+// UpdateOrder actualiza una orden que aún no se envió
 func (s *orderService) UpdateOrder(orden *models.OrdenCompra) error {
-	return s.orderRepo.Update(orden)
+	actualizadaEn := orden.UpdatedAt
+
+	// Una orden ya enviada solo cambia con sus transiciones y recepciones
+	if err := orden.ValidarEdicion(); err != nil {
+		return err
+	}
+
+	// El proveedor o los items pudieron cambiar
+	if err := s.verificarProveedorAsignado(orden); err != nil {
+		return err
+	}
+
+	// Precios, cantidades o descuentos pudieron cambiar
+	if err := s.calcularTotales(orden); err != nil {
+		return err
+	}
+
+	// Un cambio de monto, prioridad, productos o proveedor puede exigir otra aprobación
+	cambioAprobacion, err := s.reevaluarAprobacion(orden)
+	if err != nil {
+		return err
+	}
+
+	// Un cambio de items ajusta lo reservado en el presupuesto
+	if err := s.guardarOrden(orden, &actualizadaEn); err != nil {
+		return err
+	}
+
+	if cambioAprobacion {
+		if orden.EstadoOrden == models.EstadoPendienteAprobacion {
+			s.publicarAprobacionSolicitada(orden)
+		} else {
+			s.publicarOrdenGenerada(orden)
+		}
+	}
+
+	return nil
 }
 
-// DeleteOrder elimina una orden
+// DeleteOrder elimina una orden que aún no se envió y devuelve a su presupuesto lo que tenía comprometido
 func (s *orderService) DeleteOrder(ordenID string) error {
-	return s.orderRepo.Delete(ordenID)
+	orden, err := s.orderRepo.GetByID(ordenID)
+	if err != nil {
+		return err
+	}
+
+	if orden == nil {
+		return ErrOrderNotFound
+	}
+
+	if err := orden.ValidarEdicion(); err != nil {
+		return err
+	}
+
+	return s.orderRepo.Delete(orden, orden.LiberarCompromiso())
 }
 
 // ListOrders lista todas las órdenes
@@ -109,6 +252,60 @@ func (s *orderService) ListOrders() ([]*models.OrdenCompra, error) {
 	return s.orderRepo.ListAll()
 }
 
+// SendOrder marca una orden como enviada al proveedor
+func (s *orderService) SendOrder(ordenID string) error {
+	// Obtener la orden
+	orden, err := s.orderRepo.GetByID(ordenID)
+	if err != nil {
+		return err
+	}
+
+	if orden == nil {
+		return ErrOrderNotFound
+	}
+
+	// Marcar como enviada
+	actualizadaEn := orden.UpdatedAt
+	if err := orden.SendOrder(); err != nil {
+		return err
+	}
+
+	// Archivar la copia del documento que recibe el proveedor
+	documento, err := s.documentService.ArchiveOrderDocument(orden)
+	if err != nil {
+		return fmt.Errorf("error archiving order document: %w", err)
+	}
+	orden.Documento = documento
+
+	// Actualizar en la base de datos
+	err = s.orderRepo.Update(orden, actualizadaEn)
+	if err != nil {
+		s.log.Errorf("Error updating sent order: %v", err)
+		s.documentService.DeleteOrderDocument(documento)
+		return err
+	}
+
+	// Emitir evento de orden enviada
+	event := &events.OrdenCompraEnviadaEvent{
+		EventID:   uuid.New().String(),
+		EventType: events.EventTypeOrdenCompraEnviada,
+		OrdenID:   ordenID,
+		Timestamp: time.Now(),
+	}
+
+	event.Data.NumeroOrden = orden.NumeroOrden
+	event.Data.ProveedorID = orden.ProveedorID
+	event.Data.FechaEnvio = time.Now()
+	event.Data.HashDocumento = documento.Hash
+
+	err = s.eventBus.Publish(events.TopicOrderEvents, event)
+	if err != nil {
+		s.log.Errorf("Error publishing order sent event: %v", err)
+	}
+
+	return nil
+}
+
 // ConfirmOrder confirma una orden
 func (s *orderService) ConfirmOrder(ordenID string) error {
 	// Obtener la orden
@@ -118,14 +315,22 @@ func (s *orderService) ConfirmOrder(ordenID string) error {
 	}
 
 	if orden == nil {
-		return nil // Orden no encontrada
+		return ErrOrderNotFound
 	}
 
 	// Confirmar la orden
-	orden.ConfirmOrder()
+	actualizadaEn := orden.UpdatedAt
+	if err := orden.ConfirmOrder(); err != nil {
+		return err
+	}
+
+	// El proveedor pudo ser suspendido después de crear la orden
+	if _, err := s.verificarProveedor(orden.ProveedorID); err != nil {
+		return err
+	}
 
 	// Actualizar en la base de datos
-	err = s.orderRepo.Update(orden)
+	err = s.orderRepo.Update(orden, actualizadaEn)
 	if err != nil {
 		s.log.Errorf("Error updating confirmed order: %v", err)
 		return err
@@ -151,7 +356,7 @@ func (s *orderService) ConfirmOrder(ordenID string) error {
 	return nil
 }
 
-// ReceiveOrder marca una orden como recibida
+// ReceiveOrder marca una orden como recibida, dando por recibido todo lo pendiente
 func (s *orderService) ReceiveOrder(ordenID string) error {
 	// Obtener la orden
 	orden, err := s.orderRepo.GetByID(ordenID)
@@ -160,24 +365,157 @@ func (s *orderService) ReceiveOrder(ordenID string) error {
 	}
 
 	if orden == nil {
-		return nil // Orden no encontrada
+		return ErrOrderNotFound
+	}
+
+	// Si hay saldo pendiente se registra como una recepción para que sume stock
+	if recepcion := orden.RecepcionPendiente(); len(recepcion.Lineas) > 0 {
+		_, err = s.RegisterReceipt(ordenID, recepcion)
+		return err
 	}
 
+	actualizadaEn := orden.UpdatedAt
+
 	// Marcar como recibida
-	orden.ReceiveOrder()
+	if err := orden.ReceiveOrder(); err != nil {
+		return err
+	}
 
-	// Actualizar en la base de datos
-	err = s.orderRepo.Update(orden)
+	// Actualizar en la base de datos, liberando lo que quedara comprometido
+	err = s.guardarOrden(orden, &actualizadaEn)
 	if err != nil {
 		s.log.Errorf("Error updating received order: %v", err)
 		return err
 	}
 
-	// Emitir evento de orden recibida
+	s.publicarOrdenRecibida(orden)
+
+	return nil
+}
+
+// RegisterReceipt registra una entrega (total o parcial) contra los items de una orden e
+// incrementa el stock de los productos en la misma transacción. Si la recepción ya fue
+// aplicada (reintento con el mismo recepcion_id) retorna la orden sin volver a sumar stock.
+func (s *orderService) RegisterReceipt(ordenID string, recepcion *models.Recepcion) (*models.OrdenCompra, error) {
+	if recepcion.RecepcionID == "" {
+		recepcion.RecepcionID = uuid.New().String()
+	}
+
+	for intento := 1; ; intento++ {
+		// Obtener la orden
+		orden, err := s.orderRepo.GetByID(ordenID)
+		if err != nil {
+			return nil, err
+		}
+
+		if orden == nil {
+			return nil, ErrOrderNotFound
+		}
+
+		if orden.TieneRecepcion(recepcion.RecepcionID) {
+			s.log.Infof("Receipt %s already applied to order %s", recepcion.RecepcionID, ordenID)
+			return orden, nil
+		}
+
+		err = s.aplicarRecepcion(orden, recepcion)
+		if errors.Is(err, repository.ErrOrdenModificada) && intento < maxIntentosRecepcion {
+			s.log.Warnf("Order %s modified concurrently, retrying receipt (attempt %d)", ordenID, intento)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return orden, nil
+	}
+}
+
+// maxIntentosRecepcion es la cantidad de veces que se reintenta una recepción ante escrituras concurrentes
+const maxIntentosRecepcion = 3
+
+// aplicarRecepcion aplica la recepción a la orden leída y la persiste junto con los incrementos de stock
+func (s *orderService) aplicarRecepcion(orden *models.OrdenCompra, recepcion *models.Recepcion) error {
+	actualizadaEn := orden.UpdatedAt
+
+	// Aplicar la recepción a los items
+	if err := orden.RegistrarRecepcion(recepcion); err != nil {
+		return err
+	}
+
+	// Lo recibido pasa de comprometido a ejecutado en el presupuesto
+	movimiento, err := s.ajustarPresupuesto(orden)
+	if err != nil {
+		return err
+	}
+
+	cantidades := orden.CantidadesPorProducto(recepcion)
+	lotes := orden.LotesPorProducto(recepcion)
+	productoIDs := make([]string, 0, len(cantidades))
+	for productoID := range cantidades {
+		productoIDs = append(productoIDs, productoID)
+	}
+	sort.Strings(productoIDs)
+
+	usuarioRecepcion := recepcion.RecibidoPor
+	if usuarioRecepcion == "" {
+		usuarioRecepcion = "system"
+	}
+
+	incrementos := make([]repository.IncrementoStock, 0, len(productoIDs))
+	for _, productoID := range productoIDs {
+		producto, err := s.productRepo.GetByID(productoID)
+		if err != nil {
+			return err
+		}
+
+		if producto == nil {
+			return fmt.Errorf("%w: %s", repository.ErrProductoNoEncontrado, productoID)
+		}
+
+		entrada, err := models.NewMovimientoInventario(productoID, models.MovimientoRecepcion, cantidades[productoID],
+			"Recepción de orden "+orden.NumeroOrden, usuarioRecepcion, recepcion.RecepcionID)
+		if err != nil {
+			return err
+		}
+		entrada.Lotes = lotes[productoID]
+
+		incrementos = append(incrementos, repository.IncrementoStock{
+			ProductoID:            productoID,
+			Cantidad:              cantidades[productoID],
+			StockMaximo:           producto.StockMaximo,
+			PermitirExcederMaximo: recepcion.PermitirExcederMaximo,
+			Movimiento:            entrada,
+		})
+	}
+
+	// Guardar la orden, el stock y el presupuesto en una sola transacción
+	err = s.orderRepo.SaveReceipt(orden, actualizadaEn, incrementos, movimiento)
+	if err != nil {
+		return err
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"orden_id":     orden.OrdenID,
+		"recepcion_id": recepcion.RecepcionID,
+		"lineas":       len(recepcion.Lineas),
+		"productos":    len(incrementos),
+		"estado_orden": orden.EstadoOrden,
+	}).Info("Order receipt registered")
+
+	// La orden se cierra automáticamente al completar todos sus items
+	if orden.EstadoOrden == models.EstadoRecibida {
+		s.publicarOrdenRecibida(orden)
+	}
+
+	return nil
+}
+
+// publicarOrdenRecibida emite el evento de orden recibida
+func (s *orderService) publicarOrdenRecibida(orden *models.OrdenCompra) {
 	event := &events.OrdenCompraRecibidaEvent{
 		EventID:   uuid.New().String(),
 		EventType: events.EventTypeOrdenCompraRecibida,
-		OrdenID:   ordenID,
+		OrdenID:   orden.OrdenID,
 		Timestamp: time.Now(),
 	}
 
@@ -185,59 +523,199 @@ func (s *orderService) ReceiveOrder(ordenID string) error {
 	event.Data.ProveedorID = orden.ProveedorID
 	event.Data.FechaRecepcion = time.Now()
 
-	err = s.eventBus.Publish(events.TopicOrderEvents, event)
+	err := s.eventBus.Publish(events.TopicOrderEvents, event)
 	if err != nil {
 		s.log.Errorf("Error publishing order received event: %v", err)
 	}
+}
+
+// CancelOrder cancela una orden que aún no ha sido recibida
+func (s *orderService) CancelOrder(ordenID, motivo string) error {
+	// Obtener la orden
+	orden, err := s.orderRepo.GetByID(ordenID)
+	if err != nil {
+		return err
+	}
+
+	if orden == nil {
+		return ErrOrderNotFound
+	}
+
+	estadoAnterior := orden.EstadoOrden
+	actualizadaEn := orden.UpdatedAt
+
+	// Cancelar la orden
+	if err := orden.CancelOrder(motivo); err != nil {
+		return err
+	}
+
+	// Actualizar en la base de datos, liberando lo comprometido que no se recibió
+	err = s.guardarOrden(orden, &actualizadaEn)
+	if err != nil {
+		s.log.Errorf("Error updating cancelled order: %v", err)
+		return err
+	}
+
+	// Emitir evento de orden cancelada
+	event := &events.OrdenCompraCanceladaEvent{
+		EventID:   uuid.New().String(),
+		EventType: events.EventTypeOrdenCompraCancelada,
+		OrdenID:   ordenID,
+		Timestamp: time.Now(),
+	}
+
+	event.Data.NumeroOrden = orden.NumeroOrden
+	event.Data.ProveedorID = orden.ProveedorID
+	event.Data.EstadoAnterior = string(estadoAnterior)
+	event.Data.MotivoCancelacion = motivo
+	event.Data.FechaCancelacion = time.Now()
+
+	err = s.eventBus.Publish(events.TopicOrderEvents, event)
+	if err != nil {
+		s.log.Errorf("Error publishing order cancelled event: %v", err)
+	}
 
 	return nil
 }
 
-// AutoGenerateOrder genera automáticamente una orden basada en el trigger
-func (s *orderService) AutoGenerateOrder(trigger string) error {
-	s.log.Infof("Auto-generating order for trigger: %s", trigger)
+// AutoGenerateOrder ejecuta una corrida de reposición: toma todos los productos bajo su punto de
+// reorden, asigna cada uno a su proveedor preferido y crea una orden por proveedor con un item por
+// producto. Los productos que no se pueden pedir se informan en el resumen con el motivo.
+func (s *orderService) AutoGenerateOrder(trigger string) (*models.ResumenReposicion, error) {
+	s.log.Infof("Running replenishment for trigger: %s", trigger)
+	resumen := models.NuevoResumenReposicion(trigger)
 
 	// Obtener productos con stock bajo
 	productos, err := s.productRepo.GetLowStockProducts()
 	if err != nil {
 		s.log.Errorf("Error getting low stock products: %v", err)
-		return err
+		return nil, err
 	}
 
 	if len(productos) == 0 {
 		s.log.Info("No products with low stock found")
-		return nil
+		return resumen, nil
 	}
 
-	// Por simplicidad, crear una orden para el primer producto
-	// En un escenario real, se implementaría lógica más compleja
-	producto := productos[0]
+	pendientes, err := s.productosConOrdenPendiente()
+	if err != nil {
+		return nil, err
+	}
 
-	// Calcular cantidad a solicitar
-	cantidadRequerida := producto.StockMaximo - producto.StockActual
+	proveedores, err := s.supplierClient.ListActiveSuppliers()
+	if err != nil {
+		return nil, fmt.Errorf("error listing suppliers: %w", err)
+	}
 
-	// Crear orden
-	orden := models.NewOrdenCompra("", trigger, models.PrioridadMedia)
-	orden.MotivoGeneracion = trigger
+	// Agrupar los productos por su proveedor preferido, conservando el orden de aparición
+	ahora := time.Now()
+	ordenes := map[string]*models.OrdenCompra{}
+	proveedoresOrdenados := []string{}
+	productosPorID := make(map[string]*models.Producto, len(productos))
+	for _, producto := range productos {
+		if ordenID, ok := pendientes[producto.ProductoID]; ok {
+			resumen.Omitir(producto, models.OmisionOrdenPendiente, "orden "+ordenID)
+			continue
+		}
 
-	// Agregar item a la orden
-	item := models.NewItemOrdenCompra(
-		producto.ProductoID,
-		cantidadRequerida,
-		100.0, // Precio unitario por defecto
-		producto.Condiciones.TemperaturaMinima,
-	)
-	orden.AddItem(item)
+		calculo, err := producto.CalcularReposicion()
+		if err != nil {
+			resumen.Omitir(producto, models.OmisionPoliticaIncompleta, err.Error())
+			continue
+		}
+		if calculo.Cantidad <= 0 {
+			resumen.Omitir(producto, models.OmisionSinCantidad, "la política "+string(calculo.Politica)+" no pide unidades")
+			continue
+		}
 
-	// Crear la orden
-	err = s.CreateOrder(orden)
-	if err != nil {
-		s.log.Errorf("Error creating auto-generated order: %v", err)
-		return err
+		proveedor := models.SeleccionarProveedor(proveedores, []*models.Producto{producto}, ahora)
+		if proveedor == nil {
+			resumen.Omitir(producto, models.OmisionSinProveedor, ErrNoQualifiedSupplier.Error())
+			continue
+		}
+
+		orden, ok := ordenes[proveedor.ProveedorID]
+		if !ok {
+			orden = models.NewOrdenCompra(proveedor.ProveedorID, trigger, models.PrioridadBaja)
+			orden.Evaluacion = proveedor.NuevaEvaluacion(ahora)
+			ordenes[proveedor.ProveedorID] = orden
+			proveedoresOrdenados = append(proveedoresOrdenados, proveedor.ProveedorID)
+		}
+
+		orden.Prioridad = models.MayorPrioridad(orden.Prioridad, calculo.Prioridad)
+		orden.AddItem(nuevoItemReposicion(producto, calculo.Cantidad))
+		productosPorID[producto.ProductoID] = producto
 	}
 
-	s.log.Infof("Auto-generated order created: %s", orden.OrdenID)
-	return nil
+	for _, proveedorID := range proveedoresOrdenados {
+		orden := ordenes[proveedorID]
+
+		if err := s.CreateOrder(orden); err != nil {
+			motivo := models.OmisionOrdenNoCreada
+			if errors.Is(err, ErrUnknownSupplier) || errors.Is(err, ErrSupplierNotActive) || errors.Is(err, ErrColdChainIncompatible) {
+				motivo = models.OmisionProveedorRechazado
+			} else if PresupuestoRechazado(err) {
+				motivo = models.OmisionSinPresupuesto
+			} else if errors.Is(err, models.ErrPrecioNoResuelto) {
+				motivo = models.OmisionSinPrecio
+			} else {
+				s.log.Errorf("Error creating replenishment order for supplier %s: %v", proveedorID, err)
+			}
+			for _, item := range orden.Items {
+				resumen.Omitir(productosPorID[item.ProductoID], motivo, err.Error())
+			}
+			continue
+		}
+
+		resumen.RegistrarOrden(orden)
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"trigger":            trigger,
+		"productos":          len(productos),
+		"ordenes_creadas":    len(resumen.OrdenesCreadas),
+		"productos_omitidos": len(resumen.ProductosOmitidos),
+	}).Info("Replenishment run finished")
+
+	return resumen, nil
+}
+
+// productosConOrdenPendiente retorna, por producto, la orden generada o pendiente de aprobación
+// que aún no se envió y ya lo incluye
+func (s *orderService) productosConOrdenPendiente() (map[string]string, error) {
+	pendientes := map[string]string{}
+	for _, estado := range []models.EstadoOrden{models.EstadoGenerada, models.EstadoPendienteAprobacion} {
+		ordenesPendientes, err := s.orderRepo.ListByEstado(estado)
+		if err != nil {
+			s.log.Errorf("Error getting pending orders: %v", err)
+			return nil, err
+		}
+
+		for _, orden := range ordenesPendientes {
+			for _, item := range orden.Items {
+				pendientes[item.ProductoID] = orden.OrdenID
+			}
+		}
+	}
+
+	return pendientes, nil
+}
+
+// nuevoItemReposicion arma el item de reposición de un producto. La temperatura requerida es la
+// mínima de sus condiciones, o 0 si el producto no tiene condiciones de almacenamiento.
+func nuevoItemReposicion(producto *models.Producto, cantidad int) models.ItemOrdenCompra {
+	var precioUnitario models.Monto // Sin precio: al crear la orden se toma el del proveedor
+	temperaturaRequerida := 0.0
+	if producto.Condiciones != nil {
+		temperaturaRequerida = producto.Condiciones.TemperaturaMinima
+	}
+
+	return models.NewItemOrdenCompra(
+		producto.ProductoID,
+		cantidad,
+		precioUnitario,
+		temperaturaRequerida,
+	)
 }
 
 // GetOrderByNumero obtiene una orden por su número
@@ -261,30 +739,30 @@ func (s *orderService) ProcessStockLowEvent(productoID string) error {
 		return nil
 	}
 
-	// Verificar si ya existe una orden pendiente para este producto
-	ordenesPendientes, err := s.orderRepo.ListByEstado(models.EstadoGenerada)
+	// Verificar si ya hay una orden pendiente para este producto
+	pendientes, err := s.productosConOrdenPendiente()
 	if err != nil {
-		s.log.Errorf("Error getting pending orders: %v", err)
 		return err
 	}
 
-	// Verificar si ya hay una orden pendiente para este producto
-	for _, orden := range ordenesPendientes {
-		for _, item := range orden.Items {
-			if item.ProductoID == productoID {
-				s.log.Infof("Order already exists for product %s: %s", productoID, orden.OrdenID)
-				return nil
-			}
-		}
+	if ordenID, ok := pendientes[productoID]; ok {
+		s.log.Infof("Order already exists for product %s: %s", productoID, ordenID)
+		return nil
 	}
 
 	// Crear orden automáticamente para el producto con stock bajo
 	return s.createOrderForLowStockProduct(producto)
 }
 
-// ProcessLoteDanadoEvent procesa un evento de lote dañado
-func (s *orderService) ProcessLoteDanadoEvent(productoID, loteID string, cantidadDanada int, temperaturaRegistrada float64) error {
-	s.log.Infof("Processing damaged batch event for product: %s, lot: %s", productoID, loteID)
+// ProcessLoteDanadoEvent procesa un evento de lote dañado. El evento ya trae el motivo medido
+// (por ejemplo, la excursión detectada por la telemetría), por lo que aquí solo se repone.
+func (s *orderService) ProcessLoteDanadoEvent(productoID, loteID string, cantidadDanada int, motivoDanio string) error {
+	s.log.WithFields(logrus.Fields{
+		"producto_id":     productoID,
+		"lote_id":         loteID,
+		"cantidad_danada": cantidadDanada,
+		"motivo_danio":    motivoDanio,
+	}).Info("Processing damaged batch event")
 
 	// Obtener el producto
 	producto, err := s.productRepo.GetByID(productoID)
@@ -298,33 +776,36 @@ func (s *orderService) ProcessLoteDanadoEvent(productoID, loteID string, cantida
 		return nil
 	}
 
-	// Emitir evento de lote dañado
-	event := &events.LoteDanadoEvent{
-		EventID:    uuid.New().String(),
-		EventType:  events.EventTypeLoteDanado,
-		ProductoID: productoID,
-		Timestamp:  time.Now(),
+	// Reponer lo que pide la política del producto más las unidades dañadas
+	return s.reponerProducto(producto, "Lote dañado por temperatura - Generación automática", cantidadDanada)
+}
+
+// ProcessLoteVencidoEvent procesa un evento de lote vencido. La baja ya se aplicó al stock,
+// de modo que solo se repone si el producto quedó bajo su punto de reorden.
+func (s *orderService) ProcessLoteVencidoEvent(productoID, numeroLote string, cantidadVencida int) error {
+	s.log.Infof("Processing expired lot event for product: %s, lot: %s", productoID, numeroLote)
+
+	producto, err := s.productRepo.GetByID(productoID)
+	if err != nil {
+		s.log.Errorf("Error getting product: %v", err)
+		return err
 	}
 
-	event.Data.NombreProducto = producto.Nombre
-	event.Data.LoteID = loteID
-	event.Data.CantidadDanada = cantidadDanada
-	event.Data.TemperaturaRegistrada = temperaturaRegistrada
-	if producto.Condiciones != nil {
-		event.Data.TemperaturaRequerida = producto.Condiciones.TemperaturaMinima
+	if producto == nil {
+		s.log.Warnf("Product not found: %s", productoID)
+		return nil
 	}
-	event.Data.MotivoDanio = "Temperatura fuera de rango"
 
-	err = s.eventBus.Publish(events.TopicStockEvents, event)
-	if err != nil {
-		s.log.Errorf("Error publishing damaged batch event: %v", err)
+	if !producto.IsLowStock() {
+		s.log.Infof("Product %s still above reorder point after expiring %d units", productoID, cantidadVencida)
+		return nil
 	}
 
-	// Auto-generar orden para reponer el stock dañado
-	return s.AutoGenerateOrder("Lote dañado por temperatura")
+	return s.ProcessStockLowEvent(productoID)
 }
 
-// ProcessPronosticoDemandaAltaEvent procesa un evento de pronóstico de alta demanda
+// ProcessPronosticoDemandaAltaEvent procesa un evento de pronóstico de alta demanda. El evento
+// ya fue emitido por el pronóstico, por lo que aquí solo se repone.
 func (s *orderService) ProcessPronosticoDemandaAltaEvent(productoID string, demandaPronosticada int) error {
 	s.log.Infof("Processing high demand forecast event for product: %s", productoID)
 
@@ -340,28 +821,10 @@ func (s *orderService) ProcessPronosticoDemandaAltaEvent(productoID string, dema
 		return nil
 	}
 
-	// Emitir evento de pronóstico de alta demanda
-	event := &events.PronosticoDemandaAltaEvent{
-		EventID:    uuid.New().String(),
-		EventType:  events.EventTypePronosticoDemandaAlta,
-		ProductoID: productoID,
-		Timestamp:  time.Now(),
-	}
-
-	event.Data.NombreProducto = producto.Nombre
-	event.Data.DemandaPronosticada = demandaPronosticada
-	event.Data.StockActual = producto.StockActual
-	event.Data.CantidadRequerida = demandaPronosticada - producto.StockActual
-	event.Data.ConfianzaPronostico = 0.85 // Valor por defecto
-
-	err = s.eventBus.Publish(events.TopicStockEvents, event)
-	if err != nil {
-		s.log.Errorf("Error publishing high demand forecast event: %v", err)
-	}
-
-	// Auto-generar orden si la demanda pronosticada excede el stock actual
+	// Reponer si la demanda pronosticada excede el stock actual: lo que pide la política del
+	// producto más la demanda pronosticada
 	if demandaPronosticada > producto.StockActual {
-		return s.AutoGenerateOrder("Pronóstico demanda alta")
+		return s.reponerProducto(producto, "Pronóstico demanda alta - Generación automática", demandaPronosticada)
 	}
 
 	return nil
@@ -379,60 +842,186 @@ func (s *orderService) ListOrdersByProveedor(proveedorID string) ([]*models.Orde
 
 // createOrderForLowStockProduct crea una orden automáticamente para un producto con stock bajo
 func (s *orderService) createOrderForLowStockProduct(producto *models.Producto) error {
-	s.log.Infof("Creating automatic order for low stock product: %s", producto.ProductoID)
+	return s.crearOrdenReposicion(producto, "Stock bajo punto reorden - Generación automática", 0)
+}
 
-	// Calcular cantidad a solicitar (hasta el stock máximo)
-	cantidadRequerida := producto.StockMaximo - producto.StockActual
+// reponerProducto crea la orden de reposición de un producto por un evento que se lleva unidades
+// adicionales, salvo que el producto ya figure en una orden que aún no se envió
+func (s *orderService) reponerProducto(producto *models.Producto, motivo string, adicional int) error {
+	pendientes, err := s.productosConOrdenPendiente()
+	if err != nil {
+		return err
+	}
 
-	if cantidadRequerida <= 0 {
-		s.log.Infof("No reorder needed for product %s (stock already at maximum)", producto.ProductoID)
+	if ordenID, ok := pendientes[producto.ProductoID]; ok {
+		s.log.Infof("Order already exists for product %s: %s", producto.ProductoID, ordenID)
 		return nil
 	}
 
-	// Determinar prioridad basada en el nivel de stock
-	var prioridad models.Prioridad
-	if producto.StockActual == 0 {
-		prioridad = models.PrioridadCritica
-	} else if producto.StockActual <= producto.PuntoReorden/2 {
-		prioridad = models.PrioridadAlta
+	return s.crearOrdenReposicion(producto, motivo, adicional)
+}
+
+// crearOrdenReposicion crea una orden automática para un producto con la cantidad que pide su
+// política de reposición más la cantidad adicional indicada
+func (s *orderService) crearOrdenReposicion(producto *models.Producto, motivo string, adicional int) error {
+	s.log.Infof("Creating automatic order for product: %s", producto.ProductoID)
+
+	// Calcular cantidad y prioridad según la política de reposición del producto
+	cantidadRequerida := adicional
+	prioridad := models.PrioridadMedia
+	calculo, err := producto.CalcularReposicion()
+	if err != nil {
+		s.log.Warnf("Cannot apply reorder policy to product %s: %v", producto.ProductoID, err)
 	} else {
-		prioridad = models.PrioridadMedia
+		cantidadRequerida += calculo.Cantidad
+		if calculo.Prioridad != "" {
+			prioridad = calculo.Prioridad
+		}
+	}
+
+	if cantidadRequerida <= 0 {
+		s.log.Infof("No reorder needed for product %s", producto.ProductoID)
+		return nil
 	}
 
 	// Crear orden
-	orden := models.NewOrdenCompra("", "Stock bajo punto reorden", prioridad)
-	orden.MotivoGeneracion = "Stock bajo punto reorden - Generación automática"
+	orden := models.NewOrdenCompra("", motivo, prioridad)
 
 	// Agregar item a la orden
-	precioUnitario := 100.0 // Precio por defecto - en un escenario real se obtendría del catálogo
-	temperaturaRequerida := 0.0
-	if producto.Condiciones != nil {
-		temperaturaRequerida = producto.Condiciones.TemperaturaMinima
-	}
+	orden.AddItem(nuevoItemReposicion(producto, cantidadRequerida))
 
-	item := models.NewItemOrdenCompra(
-		producto.ProductoID,
-		cantidadRequerida,
-		precioUnitario,
-		temperaturaRequerida,
-	)
-	orden.AddItem(item)
+	// Asignar el proveedor que abastecerá la orden
+	if err := s.AssignSupplier(orden); err != nil {
+		s.log.Errorf("Error assigning supplier to automatic order: %v", err)
+		return err
+	}
 
 	// Crear la orden
-	err := s.CreateOrder(orden)
+	err = s.CreateOrder(orden)
 	if err != nil {
-		s.log.Errorf("Error creating automatic order for low stock product: %v", err)
+		s.log.Errorf("Error creating automatic order for product %s: %v", producto.ProductoID, err)
 		return err
 	}
 
 	s.log.WithFields(logrus.Fields{
 		"orden_id":      orden.OrdenID,
+		"proveedor_id":  orden.ProveedorID,
 		"producto_id":   producto.ProductoID,
 		"cantidad":      cantidadRequerida,
+		"adicional":     adicional,
 		"prioridad":     prioridad,
 		"stock_actual":  producto.StockActual,
 		"punto_reorden": producto.PuntoReorden,
-	}).Info("Successfully created automatic order for low stock product")
+	}).Info("Successfully created automatic order for product")
+
+	return nil
+}
+
+// AssignSupplier elige en supplier-service un proveedor activo y calificado que ofrezca todos
+// los productos de la orden y cubra sus condiciones, y completa la evaluación de la orden
+func (s *orderService) AssignSupplier(orden *models.OrdenCompra) error {
+	productos := make([]*models.Producto, 0, len(orden.Items))
+	for _, item := range orden.Items {
+		producto, err := s.productRepo.GetByID(item.ProductoID)
+		if err != nil {
+			return err
+		}
+		if producto == nil {
+			return fmt.Errorf("%w: %s", ErrProductNotFound, item.ProductoID)
+		}
+		productos = append(productos, producto)
+	}
+
+	proveedores, err := s.supplierClient.ListActiveSuppliers()
+	if err != nil {
+		return fmt.Errorf("error listing suppliers: %w", err)
+	}
+
+	ahora := time.Now()
+	proveedor := models.SeleccionarProveedor(proveedores, productos, ahora)
+	if proveedor == nil {
+		s.log.WithFields(logrus.Fields{
+			"orden_id":    orden.OrdenID,
+			"items":       len(orden.Items),
+			"proveedores": len(proveedores),
+		}).Warn("No qualified supplier found for order")
+		return ErrNoQualifiedSupplier
+	}
+
+	orden.ProveedorID = proveedor.ProveedorID
+	orden.Evaluacion = proveedor.NuevaEvaluacion(ahora)
+
+	s.log.WithFields(logrus.Fields{
+		"orden_id":     orden.OrdenID,
+		"proveedor_id": proveedor.ProveedorID,
+		"proveedor":    proveedor.NombreLegal,
+	}).Info("Supplier assigned to order")
+
+	return nil
+}
+
+// verificarProveedor valida contra la proyección local, sin consultar a supplier-service, que el
+// proveedor exista y esté activo
+func (s *orderService) verificarProveedor(proveedorID string) (*models.ProveedorProyectado, error) {
+	if proveedorID == "" {
+		return nil, fmt.Errorf("%w: la orden no tiene proveedor asignado", ErrUnknownSupplier)
+	}
+
+	proveedor, err := s.projectionRepo.Get(proveedorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if proveedor == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSupplier, proveedorID)
+	}
+
+	if !proveedor.Activo() {
+		return nil, fmt.Errorf("%w: %s está %s", ErrSupplierNotActive, proveedor.NombreLegal, proveedor.EstadoProveedor)
+	}
+
+	return proveedor, nil
+}
+
+// verificarProveedorAsignado valida el proveedor de la orden y su cadena de frío. Las órdenes
+// sin proveedor no se verifican.
+func (s *orderService) verificarProveedorAsignado(orden *models.OrdenCompra) error {
+	if orden.ProveedorID == "" {
+		return nil
+	}
+
+	proveedor, err := s.verificarProveedor(orden.ProveedorID)
+	if err != nil {
+		return err
+	}
+
+	return s.verificarCadenaFrio(orden, proveedor)
+}
+
+// verificarCadenaFrio verifica con el rango validado de la proyección local que el proveedor
+// cubra el rango de temperatura de cada producto que requiere cadena de frío
+func (s *orderService) verificarCadenaFrio(orden *models.OrdenCompra, proveedor *models.ProveedorProyectado) error {
+	for _, item := range orden.Items {
+		producto, err := s.productRepo.GetByID(item.ProductoID)
+		if err != nil {
+			return err
+		}
+
+		if producto == nil || producto.Condiciones == nil || !producto.Condiciones.CadenaFrioRequerida {
+			continue
+		}
+
+		cubre, motivo := proveedor.CubreRangoTemperatura(producto.Condiciones.TemperaturaMinima, producto.Condiciones.TemperaturaMaxima)
+		if !cubre {
+			s.log.WithFields(logrus.Fields{
+				"orden_id":     orden.OrdenID,
+				"proveedor_id": orden.ProveedorID,
+				"producto_id":  item.ProductoID,
+				"motivo":       motivo,
+			}).Warn("Supplier rejected for cold chain item")
+			return fmt.Errorf("%w: producto %s: %s", ErrColdChainIncompatible, producto.Nombre, motivo)
+		}
+	}
 
 	return nil
 }
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"mediplus/purchase-order-service/internal/models"
+	"os"
+)
+
+// TotalsConfig define la moneda de las órdenes, la política de IVA con que se calculan sus totales
+// y cuánto puede desviarse un precio manual del precio del proveedor sin pedir aprobación
+type TotalsConfig struct {
+	Moneda           string
+	Impuestos        *models.PoliticaImpuestos
+	ToleranciaPrecio float64
+}
+
+// LoadTaxPolicy lee la política de impuestos de un archivo JSON. Sin archivo se usa la
+// política por defecto.
+func LoadTaxPolicy(path string) (*models.PoliticaImpuestos, error) {
+	if path == "" {
+		return models.PoliticaImpuestosPorDefecto(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading tax policy: %w", err)
+	}
+
+	var politica models.PoliticaImpuestos
+	if err := json.Unmarshal(data, &politica); err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrPoliticaImpuestosInvalida, err)
+	}
+	if err := politica.Validar(); err != nil {
+		return nil, err
+	}
+
+	return &politica, nil
+}
+
+// GetTaxPolicy retorna la política de impuestos vigente
+func (s *orderService) GetTaxPolicy() *models.PoliticaImpuestos {
+	return s.totalsConfig.Impuestos
+}
+
+// calcularTotales resuelve el precio de los items y calcula sus importes y los totales de la orden
+// con la tasa de IVA de la categoría de cada producto. Las órdenes sin moneda toman la configurada.
+func (s *orderService) calcularTotales(orden *models.OrdenCompra) error {
+	if orden.Moneda == "" {
+		orden.Moneda = s.totalsConfig.Moneda
+	}
+
+	if err := s.resolverPrecios(orden); err != nil {
+		return err
+	}
+
+	categorias := make(map[string]string, len(orden.Items))
+	for _, item := range orden.Items {
+		if _, ok := categorias[item.ProductoID]; ok {
+			continue
+		}
+		producto, err := s.productRepo.GetByID(item.ProductoID)
+		if err != nil {
+			return err
+		}
+		categorias[item.ProductoID] = ""
+		if producto != nil {
+			categorias[item.ProductoID] = producto.Categoria
+		}
+	}
+
+	return orden.CalcularTotales(s.totalsConfig.Impuestos, categorias)
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"mediplus/purchase-order-service/internal/events"
+	"mediplus/purchase-order-service/internal/models"
+	"mediplus/purchase-order-service/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ProductService define la interfaz para el servicio del catálogo de productos
+type ProductService interface {
+	CreateProduct(producto *models.Producto) error
+	GetProduct(productoID string) (*models.Producto, error)
+	UpdateProduct(producto *models.Producto) error
+	DeleteProduct(productoID string) error
+	ListProducts() ([]*models.Producto, error)
+	GetLowStockProducts() ([]*models.Producto, error)
+	PreviewReorder(productoID string) ([]*models.CalculoReposicion, error)
+}
+
+// productService implementa ProductService
+type productService struct {
+	productRepo repository.ProductRepository
+	eventBus    events.EventBus
+	log         *logrus.Logger
+}
+
+// NewProductService crea una nueva instancia de ProductService
+func NewProductService(
+	productRepo repository.ProductRepository,
+	eventBus events.EventBus,
+	log *logrus.Logger,
+) ProductService {
+	return &productService{
+		productRepo: productRepo,
+		eventBus:    eventBus,
+		log:         log,
+	}
+}
+
+// CreateProduct valida y crea un nuevo producto
+func (s *productService) CreateProduct(producto *models.Producto) error {
+	producto.AplicarPolitica()
+	if err := producto.Validar(); err != nil {
+		return err
+	}
+
+	err := s.productRepo.Create(producto)
+	if err != nil {
+		s.log.Errorf("Error creating product: %v", err)
+		return err
+	}
+
+	// Emitir evento de producto creado
+	event := &events.ProductoCreadoEvent{
+		EventID:    uuid.New().String(),
+		EventType:  events.EventTypeProductoCreado,
+		ProductoID: producto.ProductoID,
+		Timestamp:  time.Now(),
+	}
+
+	event.Data.NombreProducto = producto.Nombre
+	event.Data.StockActual = producto.StockActual
+	event.Data.PuntoReorden = producto.PuntoReorden
+	event.Data.StockMaximo = producto.StockMaximo
+	if producto.Condiciones != nil {
+		event.Data.CadenaFrioRequerida = producto.Condiciones.CadenaFrioRequerida
+		event.Data.TemperaturaMinima = producto.Condiciones.TemperaturaMinima
+		event.Data.TemperaturaMaxima = producto.Condiciones.TemperaturaMaxima
+	}
+
+	err = s.eventBus.Publish(events.TopicProductEvents, event)
+	if err != nil {
+		s.log.Errorf("Error publishing product created event: %v", err)
+	}
+
+	return nil
+}
+
+// GetProduct obtiene un producto por su ID
+func (s *productService) GetProduct(productoID string) (*models.Producto, error) {
+	return s.productRepo.GetByID(productoID)
+}
+
+// UpdateProduct valida y actualiza un producto
+func (s *productService) UpdateProduct(producto *models.Producto) error {
+	producto.AplicarPolitica()
+	if err := producto.Validar(); err != nil {
+		return err
+	}
+
+	producto.UpdatedAt = time.Now()
+
+	err := s.productRepo.Update(producto)
+	if err != nil {
+		s.log.Errorf("Error updating product: %v", err)
+		return err
+	}
+
+	// Emitir evento de producto actualizado
+	event := &events.ProductoActualizadoEvent{
+		EventID:    uuid.New().String(),
+		EventType:  events.EventTypeProductoActualizado,
+		ProductoID: producto.ProductoID,
+		Timestamp:  time.Now(),
+	}
+
+	event.Data.NombreProducto = producto.Nombre
+	event.Data.StockActual = producto.StockActual
+	event.Data.PuntoReorden = producto.PuntoReorden
+	event.Data.StockMaximo = producto.StockMaximo
+	if producto.Condiciones != nil {
+		event.Data.CadenaFrioRequerida = producto.Condiciones.CadenaFrioRequerida
+		event.Data.TemperaturaMinima = producto.Condiciones.TemperaturaMinima
+		event.Data.TemperaturaMaxima = producto.Condiciones.TemperaturaMaxima
+	}
+
+	err = s.eventBus.Publish(events.TopicProductEvents, event)
+	if err != nil {
+		s.log.Errorf("Error publishing product updated event: %v", err)
+	}
+
+	return nil
+}
+
+// DeleteProduct elimina un producto
+func (s *productService) DeleteProduct(productoID string) error {
+	// Obtener el producto antes de eliminarlo
+	producto, err := s.productRepo.GetByID(productoID)
+	if err != nil {
+		return err
+	}
+
+	if producto == nil {
+		return nil // Producto no encontrado
+	}
+
+	err = s.productRepo.Delete(productoID)
+	if err != nil {
+		s.log.Errorf("Error deleting product: %v", err)
+		return err
+	}
+
+	// Emitir evento de producto eliminado
+	event := &events.ProductoEliminadoEvent{
+		EventID:    uuid.New().String(),
+		EventType:  events.EventTypeProductoEliminado,
+		ProductoID: productoID,
+		Timestamp:  time.Now(),
+	}
+
+	event.Data.NombreProducto = producto.Nombre
+
+	err = s.eventBus.Publish(events.TopicProductEvents, event)
+	if err != nil {
+		s.log.Errorf("Error publishing product deleted event: %v", err)
+	}
+
+	return nil
+}
+
+// ListProducts lista todos los productos
+func (s *productService) ListProducts() ([]*models.Producto, error) {
+	return s.productRepo.ListAll()
+}
+
+// GetLowStockProducts obtiene los productos en o por debajo de su punto de reorden
+func (s *productService) GetLowStockProducts() ([]*models.Producto, error) {
+	return s.productRepo.GetLowStockProducts()
+}
+
+// PreviewReorder calcula lo que pediría hoy cada política de reposición para un producto
+func (s *productService) PreviewReorder(productoID string) ([]*models.CalculoReposicion, error) {
+	producto, err := s.productRepo.GetByID(productoID)
+	if err != nil {
+		return nil, err
+	}
+
+	if producto == nil {
+		return nil, ErrProductNotFound
+	}
+
+	return producto.VistaPreviaReposicion(), nil
+}
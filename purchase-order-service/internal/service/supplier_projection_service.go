@@ -0,0 +1,238 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"mediplus/purchase-order-service/internal/clients"
+	"mediplus/purchase-order-service/internal/models"
+	"mediplus/purchase-order-service/internal/repository"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrSupplierProjectionNotFound se retorna cuando el proveedor no está en la proyección local
+var ErrSupplierProjectionNotFound = errors.New("supplier not found in local projection")
+
+// SupplierProjectionService define la interfaz para mantener la proyección local de proveedores
+type SupplierProjectionService interface {
+	ApplySupplierActivated(eventoID string, fecha time.Time, proveedor *models.ProveedorProyectado) error
+	ApplySupplierUpdated(eventoID string, fecha time.Time, proveedor *models.ProveedorProyectado) error
+	ApplySupplierSuspended(proveedorID, eventoID string, fecha time.Time) error
+	ApplyEvaluationUpdated(proveedorID, eventoID string, fecha time.Time, scoreGeneral float64) error
+	GetSupplier(proveedorID string) (*models.ProveedorProyectado, error)
+	ListSuppliers() ([]*models.ProveedorProyectado, error)
+	Rebuild() (*models.ResultadoReconstruccion, error)
+}
+
+// supplierProjectionService implementa SupplierProjectionService
+type supplierProjectionService struct {
+	projectionRepo repository.SupplierProjectionRepository
+	supplierClient clients.SupplierClient
+	log            *logrus.Logger
+}
+
+// NewSupplierProjectionService crea una nueva instancia de SupplierProjectionService
+func NewSupplierProjectionService(
+	projectionRepo repository.SupplierProjectionRepository,
+	supplierClient clients.SupplierClient,
+	log *logrus.Logger,
+) SupplierProjectionService {
+	return &supplierProjectionService{
+		projectionRepo: projectionRepo,
+		supplierClient: supplierClient,
+		log:            log,
+	}
+}
+
+// maxIntentosProyeccion es la cantidad de veces que se reaplica un evento ante escrituras concurrentes
+const maxIntentosProyeccion = 3
+
+// ApplySupplierActivated registra un proveedor calificado o reactivado con los datos del evento
+func (s *supplierProjectionService) ApplySupplierActivated(eventoID string, fecha time.Time, proveedor *models.ProveedorProyectado) error {
+	return s.aplicar(proveedor.ProveedorID, eventoID, fecha, func(actual *models.ProveedorProyectado, nuevo bool) bool {
+		copiarDatosProveedor(actual, proveedor)
+		actual.EstadoProveedor = models.EstadoProveedorActivo
+		return true
+	})
+}
+
+// ApplySupplierUpdated actualiza los datos, el rango de cadena de frío y el catálogo de un
+// proveedor ya proyectado sin cambiar su estado
+func (s *supplierProjectionService) ApplySupplierUpdated(eventoID string, fecha time.Time, proveedor *models.ProveedorProyectado) error {
+	return s.aplicar(proveedor.ProveedorID, eventoID, fecha, func(actual *models.ProveedorProyectado, nuevo bool) bool {
+		if nuevo {
+			s.log.Warnf("Ignoring update for supplier %s missing from projection", proveedor.ProveedorID)
+			return false
+		}
+		copiarDatosProveedor(actual, proveedor)
+		return true
+	})
+}
+
+// copiarDatosProveedor copia a la proyección los datos que publica supplier-service
+func copiarDatosProveedor(actual, proveedor *models.ProveedorProyectado) {
+	actual.NombreLegal = proveedor.NombreLegal
+	actual.ScoreGeneral = proveedor.ScoreGeneral
+	actual.Certificaciones = proveedor.Certificaciones
+	actual.CapacidadCadenaFrio = proveedor.CapacidadCadenaFrio
+	actual.TemperaturaMinima = proveedor.TemperaturaMinima
+	actual.TemperaturaMaxima = proveedor.TemperaturaMaxima
+	actual.ProductosOfrecidos = proveedor.ProductosOfrecidos
+}
+
+// ApplySupplierSuspended marca al proveedor como suspendido. Si aún no estaba en la proyección
+// se registra igual, para que un evento de calificación atrasado no lo deje activo.
+func (s *supplierProjectionService) ApplySupplierSuspended(proveedorID, eventoID string, fecha time.Time) error {
+	return s.aplicar(proveedorID, eventoID, fecha, func(actual *models.ProveedorProyectado, nuevo bool) bool {
+		actual.EstadoProveedor = models.EstadoProveedorSuspendido
+		return true
+	})
+}
+
+// ApplyEvaluationUpdated actualiza el score general de un proveedor ya proyectado
+func (s *supplierProjectionService) ApplyEvaluationUpdated(proveedorID, eventoID string, fecha time.Time, scoreGeneral float64) error {
+	return s.aplicar(proveedorID, eventoID, fecha, func(actual *models.ProveedorProyectado, nuevo bool) bool {
+		if nuevo {
+			s.log.Warnf("Ignoring evaluation for supplier %s missing from projection", proveedorID)
+			return false
+		}
+		actual.ScoreGeneral = scoreGeneral
+		return true
+	})
+}
+
+// aplicar lee la proyección del proveedor, le aplica el cambio y la guarda, reintentando si otro
+// evento la modificó en el medio. Los eventos anteriores al último aplicado se descartan.
+func (s *supplierProjectionService) aplicar(proveedorID, eventoID string, fecha time.Time, cambio func(actual *models.ProveedorProyectado, nuevo bool) bool) error {
+	for intento := 1; ; intento++ {
+		err := s.intentarAplicar(proveedorID, eventoID, fecha, cambio)
+		if errors.Is(err, repository.ErrProyeccionModificada) && intento < maxIntentosProyeccion {
+			s.log.Warnf("Projection of supplier %s modified concurrently, reapplying event %s (attempt %d)", proveedorID, eventoID, intento)
+			continue
+		}
+		return err
+	}
+}
+
+// intentarAplicar ejecuta un único intento de aplicar un evento a la proyección
+func (s *supplierProjectionService) intentarAplicar(proveedorID, eventoID string, fecha time.Time, cambio func(actual *models.ProveedorProyectado, nuevo bool) bool) error {
+	actual, err := s.projectionRepo.Get(proveedorID)
+	if err != nil {
+		return err
+	}
+
+	var fechaAnterior *time.Time
+	nuevo := actual == nil
+	if nuevo {
+		actual = &models.ProveedorProyectado{ProveedorID: proveedorID}
+	} else {
+		if actual.Desactualizado(fecha) {
+			s.log.Debugf("Ignoring stale event %s for supplier %s", eventoID, proveedorID)
+			return nil
+		}
+		anterior := actual.FechaUltimoEvento
+		fechaAnterior = &anterior
+	}
+
+	if !cambio(actual, nuevo) {
+		return nil
+	}
+
+	actual.UltimoEventoID = eventoID
+	actual.FechaUltimoEvento = fecha
+	actual.UpdatedAt = time.Now()
+
+	return s.projectionRepo.Save(actual, fechaAnterior)
+}
+
+// GetSupplier obtiene la proyección local de un proveedor
+func (s *supplierProjectionService) GetSupplier(proveedorID string) (*models.ProveedorProyectado, error) {
+	proveedor, err := s.projectionRepo.Get(proveedorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if proveedor == nil {
+		return nil, ErrSupplierProjectionNotFound
+	}
+
+	return proveedor, nil
+}
+
+// ListSuppliers lista la proyección local de todos los proveedores
+func (s *supplierProjectionService) ListSuppliers() ([]*models.ProveedorProyectado, error) {
+	return s.projectionRepo.ListAll()
+}
+
+// Rebuild vuelve a cargar la proyección con el estado actual de supplier-service y elimina los
+// proveedores que ya no existen allí. Los proveedores que recibieron un evento después de iniciar
+// la reconstrucción se dejan como están, porque ese evento es más reciente que la lectura.
+func (s *supplierProjectionService) Rebuild() (*models.ResultadoReconstruccion, error) {
+	inicio := time.Now()
+
+	proveedores, err := s.supplierClient.ListSuppliers()
+	if err != nil {
+		return nil, fmt.Errorf("error listing suppliers: %w", err)
+	}
+
+	proyectados, err := s.projectionRepo.ListAll()
+	if err != nil {
+		return nil, err
+	}
+
+	anteriores := make(map[string]*models.ProveedorProyectado, len(proyectados))
+	for _, proyectado := range proyectados {
+		anteriores[proyectado.ProveedorID] = proyectado
+	}
+
+	resultado := &models.ResultadoReconstruccion{}
+	for _, proveedor := range proveedores {
+		var fechaAnterior *time.Time
+		if anterior, ok := anteriores[proveedor.ProveedorID]; ok {
+			delete(anteriores, proveedor.ProveedorID)
+			if anterior.FechaUltimoEvento.After(inicio) {
+				resultado.Omitidos++
+				continue
+			}
+			fecha := anterior.FechaUltimoEvento
+			fechaAnterior = &fecha
+		}
+
+		err := s.projectionRepo.Save(models.NuevaProyeccionProveedor(proveedor, inicio), fechaAnterior)
+		if errors.Is(err, repository.ErrProyeccionModificada) {
+			resultado.Omitidos++
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		resultado.Actualizados++
+	}
+
+	// Lo que queda en anteriores ya no existe en supplier-service
+	for _, anterior := range anteriores {
+		if anterior.FechaUltimoEvento.After(inicio) {
+			resultado.Omitidos++
+			continue
+		}
+
+		err := s.projectionRepo.Delete(anterior)
+		if errors.Is(err, repository.ErrProyeccionModificada) {
+			resultado.Omitidos++
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		resultado.Eliminados++
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"actualizados": resultado.Actualizados,
+		"eliminados":   resultado.Eliminados,
+		"omitidos":     resultado.Omitidos,
+	}).Info("Supplier projection rebuilt")
+
+	return resultado, nil
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"errors"
+	"mediplus/purchase-order-service/internal/events"
+	"mediplus/purchase-order-service/internal/models"
+	"mediplus/purchase-order-service/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// TelemetryService define la interfaz para la ingesta de telemetría de temperatura
+type TelemetryService interface {
+	IngestReading(lectura *models.LecturaTemperatura) (models.ResultadoLectura, error)
+	ListExcursions(productoID string) ([]*models.ExcursionTemperatura, error)
+}
+
+// telemetryService implementa TelemetryService
+type telemetryService struct {
+	productRepo      repository.ProductRepository
+	excursionRepo    repository.ExcursionRepository
+	inventoryService InventoryService
+	eventBus         events.EventBus
+	reglas           models.ReglasExcursion
+	log              *logrus.Logger
+}
+
+// NewTelemetryService crea una nueva instancia de TelemetryService
+func NewTelemetryService(
+	productRepo repository.ProductRepository,
+	excursionRepo repository.ExcursionRepository,
+	inventoryService InventoryService,
+	eventBus events.EventBus,
+	reglas models.ReglasExcursion,
+	log *logrus.Logger,
+) TelemetryService {
+	return &telemetryService{
+		productRepo:      productRepo,
+		excursionRepo:    excursionRepo,
+		inventoryService: inventoryService,
+		eventBus:         eventBus,
+		reglas:           reglas,
+		log:              log,
+	}
+}
+
+// maxIntentosLectura es la cantidad de veces que se reevalúa una lectura ante escrituras concurrentes
+const maxIntentosLectura = 3
+
+// IngestReading evalúa una lectura contra el rango de temperatura del producto. Cuando la
+// excursión compromete el lote, lo da de baja y emite LoteDanadoEvent con los datos medidos.
+func (s *telemetryService) IngestReading(lectura *models.LecturaTemperatura) (models.ResultadoLectura, error) {
+	if lectura.FechaLectura.IsZero() {
+		lectura.FechaLectura = time.Now()
+	}
+	if err := lectura.Validar(); err != nil {
+		return "", err
+	}
+
+	producto, err := s.productRepo.GetByID(lectura.ProductoID)
+	if err != nil {
+		return "", err
+	}
+
+	if producto == nil {
+		s.log.Warnf("Temperature reading for unknown product: %s", lectura.ProductoID)
+		return models.LecturaProductoDesconocido, nil
+	}
+
+	if producto.Condiciones == nil {
+		return models.LecturaSinCondiciones, nil
+	}
+
+	for intento := 1; ; intento++ {
+		resultado, excursion, err := s.evaluar(producto, lectura)
+		if errors.Is(err, repository.ErrExcursionModificada) && intento < maxIntentosLectura {
+			s.log.Warnf("Excursion for lot %s modified concurrently, re-evaluating reading (attempt %d)", lectura.NumeroLote, intento)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if resultado == models.LecturaLoteComprometido {
+			if err := s.comprometerLote(producto, excursion); err != nil {
+				return "", err
+			}
+		}
+
+		return resultado, nil
+	}
+}
+
+// evaluar aplica la lectura a la excursión guardada y persiste el resultado
+func (s *telemetryService) evaluar(producto *models.Producto, lectura *models.LecturaTemperatura) (models.ResultadoLectura, *models.ExcursionTemperatura, error) {
+	anterior, err := s.excursionRepo.Get(lectura.ProductoID, lectura.UnidadAlmacenamiento, lectura.NumeroLote)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var ultimaLectura *time.Time
+	if anterior != nil {
+		fecha := anterior.UltimaLectura
+		ultimaLectura = &fecha
+	}
+	// Una excursión ya comprometida cuyo lote no se pudo dar de baja solo reintenta la baja
+	reintentoBaja := anterior != nil && anterior.Estado == models.EstadoExcursionComprometida
+
+	excursion, resultado := models.EvaluarLectura(producto.Condiciones, anterior, lectura, s.reglas)
+	switch resultado {
+	case models.LecturaEnRango:
+		if anterior != nil {
+			err = s.excursionRepo.Close(anterior)
+		}
+	case models.LecturaExcursionAbierta, models.LecturaLoteComprometido:
+		if !reintentoBaja {
+			err = s.excursionRepo.Save(excursion, ultimaLectura)
+		}
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	return resultado, excursion, nil
+}
+
+// comprometerLote da de baja el lote afectado y emite el evento de lote dañado. Si el lote no
+// tiene seguimiento en inventario el evento se emite igual, sin cantidad. Si la baja falla la
+// excursión queda con la baja pendiente y la próxima lectura del lote la reintenta.
+func (s *telemetryService) comprometerLote(producto *models.Producto, excursion *models.ExcursionTemperatura) error {
+	motivo := excursion.Motivo()
+
+	s.log.WithFields(logrus.Fields{
+		"producto_id":           producto.ProductoID,
+		"numero_lote":           excursion.NumeroLote,
+		"unidad_almacenamiento": excursion.UnidadAlmacenamiento,
+		"temperatura_extrema":   excursion.TemperaturaExtrema,
+		"duracion":              excursion.Duracion().String(),
+	}).Warn("Cold-chain excursion compromised lot")
+
+	cantidadDanada := 0
+	lote, err := s.inventoryService.DamageLot(producto.ProductoID, excursion.NumeroLote, motivo)
+	switch {
+	case errors.Is(err, ErrLotNotFound):
+		s.log.Warnf("Compromised lot %s/%s is not tracked in inventory", producto.ProductoID, excursion.NumeroLote)
+	case err != nil:
+		s.log.Errorf("Error writing off compromised lot %s/%s: %v", producto.ProductoID, excursion.NumeroLote, err)
+		return err
+	case lote.Estado == models.EstadoLoteActivo:
+		cantidadDanada = lote.CantidadDisponible
+	}
+
+	// Solo quien registra la baja emite el evento
+	if err := s.excursionRepo.RegistrarBaja(excursion); err != nil {
+		if errors.Is(err, repository.ErrExcursionModificada) {
+			return nil
+		}
+		return err
+	}
+
+	event := &events.LoteDanadoEvent{
+		EventID:    uuid.New().String(),
+		EventType:  events.EventTypeLoteDanado,
+		ProductoID: producto.ProductoID,
+		Timestamp:  time.Now(),
+	}
+
+	inicio := excursion.Inicio
+	event.Data.NombreProducto = producto.Nombre
+	event.Data.LoteID = excursion.NumeroLote
+	event.Data.CantidadDanada = cantidadDanada
+	event.Data.TemperaturaRegistrada = excursion.TemperaturaExtrema
+	event.Data.TemperaturaRequerida = excursion.LimiteExcedido()
+	event.Data.MotivoDanio = motivo
+	event.Data.UnidadAlmacenamiento = excursion.UnidadAlmacenamiento
+	event.Data.TemperaturaMinima = excursion.TemperaturaMinima
+	event.Data.TemperaturaMaxima = excursion.TemperaturaMaxima
+	event.Data.InicioExcursion = &inicio
+	event.Data.DuracionExcursion = excursion.Duracion().String()
+	event.Data.LecturasFueraRango = excursion.Lecturas
+
+	if err := s.eventBus.Publish(events.TopicStockEvents, event); err != nil {
+		s.log.Errorf("Error publishing damaged batch event: %v", err)
+	}
+
+	return nil
+}
+
+// ListExcursions lista las excursiones abiertas y comprometidas de un producto
+func (s *telemetryService) ListExcursions(productoID string) ([]*models.ExcursionTemperatura, error) {
+	producto, err := s.productRepo.GetByID(productoID)
+	if err != nil {
+		return nil, err
+	}
+
+	if producto == nil {
+		return nil, ErrProductNotFound
+	}
+
+	return s.excursionRepo.ListByProducto(productoID)
+}
@@ -8,10 +8,15 @@ import (
 	"syscall"
 	"time"
 
+	"mediplus/pkg/idempotency"
+	"mediplus/pkg/storage"
+	"mediplus/purchase-order-service/internal/clients"
 	"mediplus/purchase-order-service/internal/config"
 	"mediplus/purchase-order-service/internal/database"
+	"mediplus/purchase-order-service/internal/document"
 	"mediplus/purchase-order-service/internal/events"
 	"mediplus/purchase-order-service/internal/handlers"
+	"mediplus/purchase-order-service/internal/models"
 	"mediplus/purchase-order-service/internal/repository"
 	"mediplus/purchase-order-service/internal/service"
 
@@ -43,13 +48,118 @@ func main() {
 	// Inicializar repositorios
 	orderRepo := repository.NewOrderRepository(db, logger)
 	productRepo := repository.NewProductRepository(db, logger)
+	movementRepo := repository.NewMovementRepository(db, logger)
+	lotRepo := repository.NewLotRepository(db, logger)
+	excursionRepo := repository.NewExcursionRepository(db, logger)
+	supplierProjectionRepo := repository.NewSupplierProjectionRepository(db, logger)
+	processedEventRepo := idempotency.NewProcessedEventRepository(db.GetClient(), logger)
+	budgetRepo := repository.NewBudgetRepository(db, logger)
+	orderNumberRepo := repository.NewOrderNumberRepository(db, logger)
+
+	// Inicializar clientes de otros servicios
+	supplierClient := clients.NewSupplierClient(cfg.SupplierServiceURL, clients.SupplierClientConfig{
+		Timeout:          cfg.SupplierServiceTimeout,
+		MaxRetries:       cfg.SupplierServiceMaxRetries,
+		RetryBackoff:     cfg.SupplierServiceRetryBackoff,
+		BreakerThreshold: cfg.SupplierServiceBreakerThreshold,
+		BreakerCooldown:  cfg.SupplierServiceBreakerCooldown,
+	}, logger)
+
+	// Cargar la política de aprobación de órdenes
+	politicaAprobacion, err := service.LoadApprovalPolicy(cfg.ApprovalPolicyPath)
+	if err != nil {
+		logger.Fatalf("Error loading approval policy: %v", err)
+	}
+
+	periodicidadPresupuesto, err := models.ParsePeriodicidadPresupuesto(cfg.BudgetPeriodicity)
+	if err != nil {
+		logger.Fatalf("Error loading budget configuration: %v", err)
+	}
+
+	// Cargar la política de impuestos con que se calculan los totales de las órdenes
+	politicaImpuestos, err := service.LoadTaxPolicy(cfg.TaxPolicyPath)
+	if err != nil {
+		logger.Fatalf("Error loading tax policy: %v", err)
+	}
+
+	// Inicializar el almacenamiento de las copias de los documentos de órdenes
+	var documentStorage storage.DocumentStorage
+	switch cfg.DocumentStorage {
+	case "s3":
+		documentStorage, err = storage.NewS3Storage(cfg.AWSRegion, cfg.DocumentS3Endpoint, cfg.DocumentS3Bucket)
+	default:
+		documentStorage, err = storage.NewLocalStorage(cfg.DocumentStoragePath)
+	}
+	if err != nil {
+		logger.Fatalf("Error initializing document storage: %v", err)
+	}
+
+	// Cargar las plantillas y los términos del documento de la orden
+	documentRenderer, err := document.NewRenderer(cfg.DocumentTemplatePath, cfg.DocumentPDFTemplatePath)
+	if err != nil {
+		logger.Fatalf("Error loading document templates: %v", err)
+	}
+	terminosDocumento, err := document.LoadTerms(cfg.DocumentTermsPath)
+	if err != nil {
+		logger.Fatalf("Error loading document terms: %v", err)
+	}
 
 	// Inicializar servicios
-	orderService := service.NewOrderService(orderRepo, productRepo, eventBus, logger)
+	documentService := service.NewDocumentService(orderRepo, productRepo, supplierProjectionRepo, supplierClient, documentStorage, documentRenderer,
+		service.DocumentConfig{
+			Comprador: document.Comprador{
+				Nombre:               cfg.BuyerName,
+				IdentificacionFiscal: cfg.BuyerTaxID,
+				Direccion:            cfg.BuyerAddress,
+				Email:                cfg.BuyerEmail,
+				Telefono:             cfg.BuyerPhone,
+			},
+			Terminos:        terminosDocumento,
+			URLVerificacion: cfg.DocumentVerificationURL,
+		}, logger)
+	orderService := service.NewOrderService(orderRepo, productRepo, supplierProjectionRepo, supplierClient, eventBus, politicaAprobacion,
+		budgetRepo, service.BudgetConfig{
+			Periodicidad:       periodicidadPresupuesto,
+			CentroCostoDefecto: cfg.BudgetDefaultCostCenter,
+		}, service.TotalsConfig{
+			Moneda:           cfg.OrderCurrency,
+			Impuestos:        politicaImpuestos,
+			ToleranciaPrecio: cfg.PriceOverrideTolerance,
+		}, orderNumberRepo, service.NumberingConfig{
+			Prefijo: cfg.OrderNumberPrefix,
+		}, documentService, logger)
+	productService := service.NewProductService(productRepo, eventBus, logger)
+	inventoryService := service.NewInventoryService(productRepo, movementRepo, lotRepo, eventBus, logger)
+	telemetryService := service.NewTelemetryService(productRepo, excursionRepo, inventoryService, eventBus,
+		models.ReglasExcursion{
+			DuracionMaxima:    cfg.ExcursionMaxDuration,
+			DesviacionCritica: cfg.ExcursionCriticalDeviation,
+		}, logger)
+	supplierProjectionService := service.NewSupplierProjectionService(supplierProjectionRepo, supplierClient, logger)
+	forecastService := service.NewForecastService(productRepo, movementRepo, eventBus, service.ForecastConfig{
+		Parametros: models.ParametrosPronostico{
+			Ventana:        cfg.ForecastMovingAverageWindow,
+			Alfa:           cfg.ForecastSmoothingAlpha,
+			Temporada:      cfg.ForecastSeasonLength,
+			NivelConfianza: cfg.ForecastConfidenceLevel,
+		},
+		DiasHistoria:             cfg.ForecastHistoryDays,
+		TiempoEntregaDiasDefecto: cfg.ForecastDefaultLeadTimeDays,
+	}, logger)
+	budgetService := service.NewBudgetService(budgetRepo, orderRepo, periodicidadPresupuesto, logger)
 
 	// Inicializar handlers
 	orderHandler := handlers.NewOrderHandler(orderService, logger)
+	productHandler := handlers.NewProductHandler(productService, logger)
+	inventoryHandler := handlers.NewInventoryHandler(inventoryService, logger)
+	telemetryHandler := handlers.NewTelemetryHandler(telemetryService, logger)
+	supplierHandler := handlers.NewSupplierHandler(supplierProjectionService, logger)
+	forecastHandler := handlers.NewForecastHandler(forecastService, logger)
+	budgetHandler := handlers.NewBudgetHandler(budgetService, logger)
+	documentHandler := handlers.NewDocumentHandler(documentService, logger)
 	eventHandler := handlers.NewEventHandler(orderService, logger)
+	externalEventHandler := handlers.NewExternalEventHandler(orderService, logger)
+	externalSimulatorHandler := handlers.NewExternalSimulatorHandler(eventBus, logger)
 
 	// Configurar rutas
 	router := gin.Default()
@@ -66,9 +176,61 @@ func main() {
 			orders.PUT("/:id", orderHandler.UpdateOrder)
 			orders.DELETE("/:id", orderHandler.DeleteOrder)
 			orders.GET("", orderHandler.ListOrders)
+			orders.POST("/:id/send", orderHandler.SendOrder)
+			orders.GET("/:id/document", documentHandler.GetOrderDocument)
+			orders.GET("/:id/document/verify", documentHandler.VerifyOrderDocument)
 			orders.POST("/:id/confirm", orderHandler.ConfirmOrder)
 			orders.POST("/:id/receive", orderHandler.ReceiveOrder)
+			orders.POST("/:id/receipts", orderHandler.RegisterReceipt)
+			orders.POST("/:id/cancel", orderHandler.CancelOrder)
 			orders.POST("/auto-generate", orderHandler.AutoGenerateOrder)
+			orders.GET("/pending-approval", orderHandler.ListPendingApprovals)
+			orders.GET("/approval-policy", orderHandler.GetApprovalPolicy)
+			orders.GET("/tax-policy", orderHandler.GetTaxPolicy)
+			orders.GET("/by-number/:numero", orderHandler.GetOrderByNumero)
+			orders.POST("/:id/approve", orderHandler.ApproveOrder)
+			orders.POST("/:id/reject", orderHandler.RejectOrder)
+		}
+
+		products := v1.Group("/products")
+		{
+			products.POST("", productHandler.CreateProduct)
+			products.GET("", productHandler.ListProducts)
+			products.GET("/low-stock", productHandler.GetLowStockProducts)
+			products.GET("/reconciliation", inventoryHandler.ReconcileAll)
+			products.GET("/lots/near-expiry", inventoryHandler.ListLotsNearExpiry)
+			products.GET("/:id", productHandler.GetProduct)
+			products.PUT("/:id", productHandler.UpdateProduct)
+			products.DELETE("/:id", productHandler.DeleteProduct)
+			products.POST("/:id/movements", inventoryHandler.RecordMovement)
+			products.GET("/:id/movements", inventoryHandler.ListMovements)
+			products.GET("/:id/reconciliation", inventoryHandler.ReconcileProduct)
+			products.GET("/:id/lots", inventoryHandler.ListLots)
+			products.GET("/:id/excursions", telemetryHandler.ListExcursions)
+			products.GET("/:id/reorder-preview", productHandler.PreviewReorder)
+			products.GET("/:id/forecast", forecastHandler.GetForecast)
+		}
+
+		// Proyección local de proveedores
+		suppliers := v1.Group("/suppliers")
+		{
+			suppliers.GET("", supplierHandler.ListSuppliers)
+			suppliers.POST("/rebuild", supplierHandler.RebuildProjection)
+			suppliers.GET("/:id", supplierHandler.GetSupplier)
+		}
+
+		telemetry := v1.Group("/telemetry")
+		{
+			telemetry.POST("/readings", telemetryHandler.IngestReadings)
+		}
+
+		budgets := v1.Group("/budgets")
+		{
+			budgets.POST("", budgetHandler.CreateBudget)
+			budgets.GET("", budgetHandler.ListBudgets)
+			budgets.GET("/:centro/:periodo", budgetHandler.GetBudget)
+			budgets.PUT("/:centro/:periodo", budgetHandler.UpdateBudget)
+			budgets.GET("/:centro/:periodo/consumption", budgetHandler.GetBudgetConsumption)
 		}
 
 		// Rutas para simulación de eventos externos
@@ -96,11 +258,14 @@ func main() {
 		Handler: router,
 	}
 
+	// Las entregas repetidas de un evento se descartan por event_id en cada cola
+	idempotentConsumer := idempotency.NewIdempotentConsumer(processedEventRepo, cfg.ProcessedEventLease, cfg.ProcessedEventRetention, logger)
+
 	// Suscribirse a eventos de stock
 	logger.Info("Subscribing to stock events...")
 
 	// Suscribirse a eventos de stock bajo
-	err = eventBus.Subscribe(events.TopicStockEvents, "purchase-order-stock-bajo", eventHandler.HandleStockBajoEvent)
+	err = eventBus.Subscribe(events.TopicStockEvents, "purchase-order-stock-bajo", idempotentConsumer.Wrap("purchase-order-stock-bajo", eventHandler.HandleStockBajoEvent))
 	if err != nil {
 		logger.Errorf("Error subscribing to stock low events: %v", err)
 	} else {
@@ -108,21 +273,112 @@ func main() {
 	}
 
 	// Suscribirse a eventos de lote dañado
-	err = eventBus.Subscribe(events.TopicStockEvents, "purchase-order-lote-danado", eventHandler.HandleLoteDanadoEvent)
+	err = eventBus.Subscribe(events.TopicStockEvents, "purchase-order-lote-danado", idempotentConsumer.Wrap("purchase-order-lote-danado", eventHandler.HandleLoteDanadoEvent))
 	if err != nil {
 		logger.Errorf("Error subscribing to damaged batch events: %v", err)
 	} else {
 		logger.Info("Successfully subscribed to damaged batch events")
 	}
 
+	// Suscribirse a eventos de lote vencido
+	err = eventBus.Subscribe(events.TopicStockEvents, "purchase-order-lote-vencido", idempotentConsumer.Wrap("purchase-order-lote-vencido", eventHandler.HandleLoteVencidoEvent))
+	if err != nil {
+		logger.Errorf("Error subscribing to expired lot events: %v", err)
+	} else {
+		logger.Info("Successfully subscribed to expired lot events")
+	}
+
 	// Suscribirse a eventos de pronóstico de alta demanda
-	err = eventBus.Subscribe(events.TopicStockEvents, "purchase-order-demanda-alta", eventHandler.HandlePronosticoDemandaAltaEvent)
+	err = eventBus.Subscribe(events.TopicStockEvents, "purchase-order-demanda-alta", idempotentConsumer.Wrap("purchase-order-demanda-alta", eventHandler.HandlePronosticoDemandaAltaEvent))
 	if err != nil {
 		logger.Errorf("Error subscribing to high demand forecast events: %v", err)
 	} else {
 		logger.Info("Successfully subscribed to high demand forecast events")
 	}
 
+	// Suscribirse a eventos de sistemas externos de inventario y pronóstico
+	err = eventBus.Subscribe(events.TopicExternalEvents, "purchase-order-external-events", idempotentConsumer.Wrap("purchase-order-external-events", externalEventHandler.HandleExternalEvent))
+	if err != nil {
+		logger.Errorf("Error subscribing to external events: %v", err)
+	} else {
+		logger.Info("Successfully subscribed to external events")
+	}
+
+	// Suscribirse a lecturas de temperatura de los sensores de almacenamiento
+	err = eventBus.Subscribe(events.TopicTelemetryEvents, "purchase-order-telemetria", idempotentConsumer.Wrap("purchase-order-telemetria", telemetryHandler.HandleLecturaTemperaturaEvent))
+	if err != nil {
+		logger.Errorf("Error subscribing to temperature telemetry: %v", err)
+	} else {
+		logger.Info("Successfully subscribed to temperature telemetry")
+	}
+
+	// Suscribirse a eventos de proveedores para mantener la proyección local
+	err = eventBus.Subscribe(events.TopicProveedorEvents, "purchase-order-proveedores", idempotentConsumer.Wrap("purchase-order-proveedores", supplierHandler.HandleProveedorEvent))
+	if err != nil {
+		logger.Errorf("Error subscribing to supplier events: %v", err)
+	} else {
+		logger.Info("Successfully subscribed to supplier events")
+	}
+
+	// Cargar la proyección de proveedores con el estado actual de supplier-service
+	go func() {
+		if _, err := supplierProjectionService.Rebuild(); err != nil {
+			logger.Errorf("Error rebuilding supplier projection: %v", err)
+		}
+	}()
+
+	// Conciliación periódica del stock contra el libro de movimientos
+	if cfg.InventoryReconciliationInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(cfg.InventoryReconciliationInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				if _, err := inventoryService.ReconcileAll(); err != nil {
+					logger.Errorf("Error running stock reconciliation: %v", err)
+				}
+			}
+		}()
+	}
+
+	// Baja periódica de lotes vencidos
+	if cfg.LotExpiryCheckInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(cfg.LotExpiryCheckInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				if _, err := inventoryService.ExpireLots(); err != nil {
+					logger.Errorf("Error expiring lots: %v", err)
+				}
+			}
+		}()
+	}
+
+	// Revisión periódica de productos cuya demanda pronosticada supera el stock
+	if cfg.ForecastCheckInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(cfg.ForecastCheckInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				if _, err := forecastService.CheckHighDemand(); err != nil {
+					logger.Errorf("Error checking forecast demand: %v", err)
+				}
+			}
+		}()
+	}
+
+	// Escalamiento periódico de aprobaciones vencidas
+	if cfg.ApprovalEscalationCheckInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(cfg.ApprovalEscalationCheckInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				if _, err := orderService.EscalateOverdueApprovals(); err != nil {
+					logger.Errorf("Error escalating overdue approvals: %v", err)
+				}
+			}
+		}()
+	}
+
 	// Iniciar servidor en goroutine
 	go func() {
 		logger.Infof("Starting purchase order service on port %s", cfg.Port)
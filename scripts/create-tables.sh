@@ -43,15 +43,26 @@ aws dynamodb create-table \
     AttributeName=estado_orden,AttributeType=S \
     AttributeName=proveedor_id,AttributeType=S \
     AttributeName=fecha_generacion,AttributeType=S \
+    AttributeName=numero_orden,AttributeType=S \
   --key-schema \
     AttributeName=orden_id,KeyType=HASH \
   --global-secondary-indexes \
     IndexName=estado-index,KeySchema='[{AttributeName=estado_orden,KeyType=HASH}]',Projection='{ProjectionType=ALL}',ProvisionedThroughput='{ReadCapacityUnits=5,WriteCapacityUnits=5}' \
     IndexName=proveedor-fecha-index,KeySchema='[{AttributeName=proveedor_id,KeyType=HASH},{AttributeName=fecha_generacion,KeyType=RANGE}]',Projection='{ProjectionType=ALL}',ProvisionedThroughput='{ReadCapacityUnits=5,WriteCapacityUnits=5}' \
+    IndexName=numero-orden-index,KeySchema='[{AttributeName=numero_orden,KeyType=HASH}]',Projection='{ProjectionType=ALL}',ProvisionedThroughput='{ReadCapacityUnits=5,WriteCapacityUnits=5}' \
   --provisioned-throughput ReadCapacityUnits=5,WriteCapacityUnits=5 \
   --endpoint-url http://localhost:8000 \
   --region us-east-1 || echo "Table orders already exists"
 
+# Añadir numero-orden-index a una tabla orders creada antes del índice
+aws dynamodb update-table \
+  --table-name orders \
+  --attribute-definitions AttributeName=numero_orden,AttributeType=S \
+  --global-secondary-index-updates \
+    "Create={IndexName=numero-orden-index,KeySchema=[{AttributeName=numero_orden,KeyType=HASH}],Projection={ProjectionType=ALL},ProvisionedThroughput={ReadCapacityUnits=5,WriteCapacityUnits=5}}" \
+  --endpoint-url http://localhost:8000 \
+  --region us-east-1 || echo "Index numero-orden-index already exists"
+
 # Crear tabla products
 aws dynamodb create-table \
   --table-name products \
@@ -66,4 +77,141 @@ aws dynamodb create-table \
   --endpoint-url http://localhost:8000 \
   --region us-east-1 || echo "Table products already exists"
 
+# Crear tabla inventory_movements (libro de movimientos de inventario)
+aws dynamodb create-table \
+  --table-name inventory_movements \
+  --attribute-definitions \
+    AttributeName=producto_id,AttributeType=S \
+    AttributeName=movimiento_id,AttributeType=S \
+  --key-schema \
+    AttributeName=producto_id,KeyType=HASH \
+    AttributeName=movimiento_id,KeyType=RANGE \
+  --provisioned-throughput ReadCapacityUnits=5,WriteCapacityUnits=5 \
+  --endpoint-url http://localhost:8000 \
+  --region us-east-1 || echo "Table inventory_movements already exists"
+
+# Crear tabla product_lots (lotes de productos con vencimiento)
+aws dynamodb create-table \
+  --table-name product_lots \
+  --attribute-definitions \
+    AttributeName=producto_id,AttributeType=S \
+    AttributeName=numero_lote,AttributeType=S \
+  --key-schema \
+    AttributeName=producto_id,KeyType=HASH \
+    AttributeName=numero_lote,KeyType=RANGE \
+  --provisioned-throughput ReadCapacityUnits=5,WriteCapacityUnits=5 \
+  --endpoint-url http://localhost:8000 \
+  --region us-east-1 || echo "Table product_lots already exists"
+
+# Crear tabla temperature_excursions (excursiones de temperatura por lote y unidad)
+aws dynamodb create-table \
+  --table-name temperature_excursions \
+  --attribute-definitions \
+    AttributeName=producto_id,AttributeType=S \
+    AttributeName=unidad_lote,AttributeType=S \
+  --key-schema \
+    AttributeName=producto_id,KeyType=HASH \
+    AttributeName=unidad_lote,KeyType=RANGE \
+  --provisioned-throughput ReadCapacityUnits=5,WriteCapacityUnits=5 \
+  --endpoint-url http://localhost:8000 \
+  --region us-east-1 || echo "Table temperature_excursions already exists"
+
+# Crear tabla supplier_projection (proyección local de proveedores en purchase-order-service)
+aws dynamodb create-table \
+  --table-name supplier_projection \
+  --attribute-definitions \
+    AttributeName=proveedor_id,AttributeType=S \
+  --key-schema \
+    AttributeName=proveedor_id,KeyType=HASH \
+  --provisioned-throughput ReadCapacityUnits=5,WriteCapacityUnits=5 \
+  --endpoint-url http://localhost:8000 \
+  --region us-east-1 || echo "Table supplier_projection already exists"
+
+# Crear tabla supplier_stats (proyección de estadísticas de proveedores)
+aws dynamodb create-table \
+  --table-name supplier_stats \
+  --attribute-definitions \
+    AttributeName=proveedor_id,AttributeType=S \
+  --key-schema \
+    AttributeName=proveedor_id,KeyType=HASH \
+  --provisioned-throughput ReadCapacityUnits=5,WriteCapacityUnits=5 \
+  --endpoint-url http://localhost:8000 \
+  --region us-east-1 || echo "Table supplier_stats already exists"
+
+# Crear tabla supplier_order_tracking (fechas de órdenes para estadísticas)
+aws dynamodb create-table \
+  --table-name supplier_order_tracking \
+  --attribute-definitions \
+    AttributeName=orden_id,AttributeType=S \
+  --key-schema \
+    AttributeName=orden_id,KeyType=HASH \
+  --provisioned-throughput ReadCapacityUnits=5,WriteCapacityUnits=5 \
+  --endpoint-url http://localhost:8000 \
+  --region us-east-1 || echo "Table supplier_order_tracking already exists"
+
+# Crear tabla review_tasks (tareas de revisión manual, p. ej. screening de sanciones)
+aws dynamodb create-table \
+  --table-name review_tasks \
+  --attribute-definitions \
+    AttributeName=tarea_id,AttributeType=S \
+  --key-schema \
+    AttributeName=tarea_id,KeyType=HASH \
+  --provisioned-throughput ReadCapacityUnits=5,WriteCapacityUnits=5 \
+  --endpoint-url http://localhost:8000 \
+  --region us-east-1 || echo "Table review_tasks already exists"
+
+# Crear tabla supplier_documents (metadatos y versiones de documentos)
+aws dynamodb create-table \
+  --table-name supplier_documents \
+  --attribute-definitions \
+    AttributeName=documento_id,AttributeType=S \
+  --key-schema \
+    AttributeName=documento_id,KeyType=HASH \
+  --provisioned-throughput ReadCapacityUnits=5,WriteCapacityUnits=5 \
+  --endpoint-url http://localhost:8000 \
+  --region us-east-1 || echo "Table supplier_documents already exists"
+
+# Crear tabla processed_events (consumo idempotente de eventos en ambos servicios, con TTL)
+aws dynamodb create-table \
+  --table-name processed_events \
+  --attribute-definitions \
+    AttributeName=consumidor,AttributeType=S \
+    AttributeName=event_id,AttributeType=S \
+  --key-schema \
+    AttributeName=consumidor,KeyType=HASH \
+    AttributeName=event_id,KeyType=RANGE \
+  --provisioned-throughput ReadCapacityUnits=5,WriteCapacityUnits=5 \
+  --endpoint-url http://localhost:8000 \
+  --region us-east-1 || echo "Table processed_events already exists"
+
+aws dynamodb update-time-to-live \
+  --table-name processed_events \
+  --time-to-live-specification "Enabled=true,AttributeName=expira_en" \
+  --endpoint-url http://localhost:8000 \
+  --region us-east-1 || echo "TTL already enabled on processed_events"
+
+# Crear tabla budgets (presupuestos por centro de costo y período)
+aws dynamodb create-table \
+  --table-name budgets \
+  --attribute-definitions \
+    AttributeName=centro_costo_id,AttributeType=S \
+    AttributeName=periodo,AttributeType=S \
+  --key-schema \
+    AttributeName=centro_costo_id,KeyType=HASH \
+    AttributeName=periodo,KeyType=RANGE \
+  --provisioned-throughput ReadCapacityUnits=5,WriteCapacityUnits=5 \
+  --endpoint-url http://localhost:8000 \
+  --region us-east-1 || echo "Table budgets already exists"
+
+# Crear tabla order_numbers (contador por serie y números de orden asignados)
+aws dynamodb create-table \
+  --table-name order_numbers \
+  --attribute-definitions \
+    AttributeName=clave,AttributeType=S \
+  --key-schema \
+    AttributeName=clave,KeyType=HASH \
+  --provisioned-throughput ReadCapacityUnits=5,WriteCapacityUnits=5 \
+  --endpoint-url http://localhost:8000 \
+  --region us-east-1 || echo "Table order_numbers already exists"
+
 echo "All tables created successfully!"
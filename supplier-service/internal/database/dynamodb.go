@@ -1,6 +1,8 @@
 package database
 
 import (
+	"mediplus/pkg/idempotency"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -54,6 +56,29 @@ func (d *DynamoDBClient) CreateTables() error {
 		return err
 	}
 
+	// Crear tablas de la proyección de estadísticas
+	if err := d.createSimpleTable("supplier_stats", "proveedor_id"); err != nil {
+		return err
+	}
+	if err := d.createSimpleTable("supplier_order_tracking", "orden_id"); err != nil {
+		return err
+	}
+
+	// Crear tabla de tareas de revisión manual
+	if err := d.createSimpleTable("review_tasks", "tarea_id"); err != nil {
+		return err
+	}
+
+	// Crear tabla de metadatos de documentos
+	if err := d.createSimpleTable("supplier_documents", "documento_id"); err != nil {
+		return err
+	}
+
+	// Crear tabla de eventos procesados (consumo idempotente)
+	if err := idempotency.CreateTable(d.client, d.log); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -174,3 +199,36 @@ func (d *DynamoDBClient) createAuditTable() error {
 
 	return nil
 }
+
+// createSimpleTable crea una tabla con una única clave de partición de tipo String
+func (d *DynamoDBClient) createSimpleTable(tableName, hashKey string) error {
+	input := &dynamodb.CreateTableInput{
+		TableName: aws.String(tableName),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String(hashKey),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String(hashKey),
+				KeyType:       aws.String("HASH"),
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(5),
+			WriteCapacityUnits: aws.Int64(5),
+		},
+	}
+
+	_, err := d.client.CreateTable(input)
+	if err != nil {
+		// Si la tabla ya existe, no es un error
+		if _, ok := err.(*dynamodb.ResourceInUseException); !ok {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -24,21 +24,35 @@ const (
 
 // Eventos del dominio
 
-// ProveedorCalificadoEvent se emite cuando un proveedor es calificado
+// ProveedorCalificadoEvent se emite cuando un proveedor es calificado. Con el mismo contenido se
+// emiten proveedor.activado y proveedor.actualizado.
 type ProveedorCalificadoEvent struct {
 	EventID     string    `json:"event_id"`
 	EventType   string    `json:"event_type"`
 	ProveedorID string    `json:"proveedor_id"`
 	Timestamp   time.Time `json:"timestamp"`
 	Data        struct {
-		NombreLegal         string   `json:"nombre_legal"`
-		RazonSocial         string   `json:"razon_social"`
-		ScoreGeneral        float64  `json:"score_general"`
-		Certificaciones     []string `json:"certificaciones"`
-		CapacidadCadenaFrio bool     `json:"capacidad_cadena_frio"`
+		NombreLegal         string            `json:"nombre_legal"`
+		RazonSocial         string            `json:"razon_social"`
+		ScoreGeneral        float64           `json:"score_general"`
+		Certificaciones     []string          `json:"certificaciones"`
+		CapacidadCadenaFrio bool              `json:"capacidad_cadena_frio"`
+		TemperaturaMinima   float64           `json:"temperatura_minima"`
+		TemperaturaMaxima   float64           `json:"temperatura_maxima"`
+		ProductosOfrecidos  []OfertaProveedor `json:"productos_ofrecidos"`
 	} `json:"data"`
 }
 
+// OfertaProveedor es un producto del catálogo del proveedor con sus precios
+type OfertaProveedor struct {
+	ProductoID           string     `json:"producto_id"`
+	PrecioBase           float64    `json:"precio_base"`
+	Moneda               string     `json:"moneda"`
+	EstadoDisponibilidad string     `json:"estado_disponibilidad"`
+	PrecioContratado     float64    `json:"precio_contratado,omitempty"`
+	ContratoVigenteHasta *time.Time `json:"contrato_vigente_hasta,omitempty"`
+}
+
 // ProveedorSuspendidoEvent se emite cuando un proveedor es suspendido
 type ProveedorSuspendidoEvent struct {
 	EventID     string    `json:"event_id"`
@@ -92,6 +106,10 @@ type OrdenCompraGeneradaEvent struct {
 		MotivoGeneracion string  `json:"motivo_generacion"`
 		Prioridad        string  `json:"prioridad"`
 		TotalItems       int     `json:"total_items"`
+		Moneda           string  `json:"moneda"`
+		Subtotal         float64 `json:"subtotal"`
+		Descuento        float64 `json:"descuento"`
+		Impuesto         float64 `json:"impuesto"`
 		ValorTotal       float64 `json:"valor_total"`
 	} `json:"data"`
 }
@@ -200,6 +218,7 @@ const (
 	EventTypeProveedorCalificado    = "proveedor.calificado"
 	EventTypeProveedorSuspendido    = "proveedor.suspendido"
 	EventTypeProveedorActivado      = "proveedor.activado"
+	EventTypeProveedorActualizado   = "proveedor.actualizado"
 	EventTypeCertificacionPorVencer = "certificacion.por_vencer"
 	EventTypeEvaluacionActualizada  = "evaluacion.actualizada"
 	EventTypeCertificacionVencida   = "certificacion.vencida"
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mediplus/supplier-service/internal/repository"
+	"mediplus/supplier-service/internal/service"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// DocumentHandler maneja las peticiones HTTP para documentos de proveedores
+type DocumentHandler struct {
+	service       service.DocumentService
+	maxUploadSize int64
+	log           *logrus.Logger
+}
+
+// NewDocumentHandler crea una nueva instancia de DocumentHandler
+func NewDocumentHandler(service service.DocumentService, maxUploadSize int64, log *logrus.Logger) *DocumentHandler {
+	return &DocumentHandler{
+		service:       service,
+		maxUploadSize: maxUploadSize,
+		log:           log,
+	}
+}
+
+// UploadDocument sube un documento nuevo (multipart: archivo, numero_certificado, tipo_documento, nombre, subido_por)
+func (h *DocumentHandler) UploadDocument(c *gin.Context) {
+	h.upload(c, "")
+}
+
+// UploadDocumentVersion sube una nueva versión de un documento existente
+func (h *DocumentHandler) UploadDocumentVersion(c *gin.Context) {
+	h.upload(c, c.Param("documentoId"))
+}
+
+// ListDocuments lista los documentos de un proveedor (?numero_certificado= para filtrar por certificación)
+func (h *DocumentHandler) ListDocuments(c *gin.Context) {
+	documentos, err := h.service.ListDocuments(c.Param("id"), c.Query("numero_certificado"))
+	if err != nil {
+		h.log.Errorf("Error listing documents: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing documents"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": documentos})
+}
+
+// GetDocument obtiene los metadatos y el historial de versiones de un documento
+func (h *DocumentHandler) GetDocument(c *gin.Context) {
+	documento, err := h.service.GetDocument(c.Param("id"), c.Param("documentoId"))
+	if err != nil {
+		h.responderError(c, err, "Error getting document")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": documento})
+}
+
+// DownloadDocument descarga el contenido de un documento (?version=N, por defecto la actual)
+func (h *DocumentHandler) DownloadDocument(c *gin.Context) {
+	version := 0
+	if v := c.Query("version"); v != "" {
+		var err error
+		version, err = strconv.Atoi(v)
+		if err != nil || version < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "version must be a positive integer"})
+			return
+		}
+	}
+
+	v, contenido, err := h.service.DownloadDocument(c.Param("id"), c.Param("documentoId"), version)
+	if err != nil {
+		h.responderError(c, err, "Error downloading document")
+		return
+	}
+
+	nombreArchivo := v.NombreArchivo
+	if nombreArchivo == "" {
+		nombreArchivo = c.Param("documentoId")
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": nombreArchivo}))
+	c.Header("X-Checksum-Sha256", v.Checksum)
+	c.Header("X-Document-Version", strconv.Itoa(v.Version))
+	c.Data(http.StatusOK, v.ContentType, contenido)
+}
+
+// DeleteDocument elimina un documento que ya cumplió su periodo de retención
+func (h *DocumentHandler) DeleteDocument(c *gin.Context) {
+	err := h.service.DeleteDocument(c.Param("id"), c.Param("documentoId"), c.Query("usuario_id"))
+	if err != nil {
+		h.responderError(c, err, "Error deleting document")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Document deleted successfully"})
+}
+
+// upload procesa la subida multipart de un documento o de una nueva versión
+func (h *DocumentHandler) upload(c *gin.Context, documentoID string) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize)
+
+	archivo, header, err := c.Request.FormFile("archivo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "archivo is required: " + err.Error()})
+		return
+	}
+	defer archivo.Close()
+
+	contenido, err := io.ReadAll(archivo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Error reading file: " + err.Error()})
+		return
+	}
+
+	if len(contenido) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "archivo is empty"})
+		return
+	}
+
+	documento, err := h.service.UploadDocument(&service.UploadDocumentRequest{
+		ProveedorID:       c.Param("id"),
+		DocumentoID:       documentoID,
+		NumeroCertificado: c.PostForm("numero_certificado"),
+		TipoDocumento:     c.PostForm("tipo_documento"),
+		Nombre:            c.PostForm("nombre"),
+		NombreArchivo:     header.Filename,
+		ContentType:       header.Header.Get("Content-Type"),
+		SubidoPor:         c.PostForm("subido_por"),
+		Contenido:         contenido,
+	})
+	if err != nil {
+		h.responderError(c, err, "Error uploading document")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Document uploaded successfully",
+		"data":    documento,
+	})
+}
+
+// responderError traduce los errores del servicio de documentos a respuestas HTTP
+func (h *DocumentHandler) responderError(c *gin.Context, err error, mensaje string) {
+	switch {
+	case errors.Is(err, service.ErrSupplierNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Supplier not found"})
+	case errors.Is(err, service.ErrDocumentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+	case errors.Is(err, service.ErrCertificationNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Certification not found for supplier"})
+	case errors.Is(err, service.ErrDocumentRetained), errors.Is(err, repository.ErrDocumentoModificado):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		h.log.Errorf("%s: %v", mensaje, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": mensaje})
+	}
+}
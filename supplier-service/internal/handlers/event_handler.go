@@ -32,6 +32,12 @@ func (h *EventHandler) HandleOrdenCompraGeneradaEvent(eventData []byte) error {
 		return err
 	}
 
+	// Todas las colas de órdenes reciben cada evento del exchange; ignorar los de otro tipo
+	if orderEvent.EventType != events.EventTypeOrdenCompraGenerada {
+		h.log.Debugf("Ignoring %s event on OrdenCompraGenerada handler", orderEvent.EventType)
+		return nil
+	}
+
 	h.log.WithFields(logrus.Fields{
 		"event_id":     orderEvent.EventID,
 		"orden_id":     orderEvent.OrdenID,
@@ -66,6 +72,12 @@ func (h *EventHandler) HandleOrdenCompraConfirmadaEvent(eventData []byte) error
 		return err
 	}
 
+	// Todas las colas de órdenes reciben cada evento del exchange; ignorar los de otro tipo
+	if orderEvent.EventType != events.EventTypeOrdenCompraConfirmada {
+		h.log.Debugf("Ignoring %s event on OrdenCompraConfirmada handler", orderEvent.EventType)
+		return nil
+	}
+
 	h.log.WithFields(logrus.Fields{
 		"event_id":           orderEvent.EventID,
 		"orden_id":           orderEvent.OrdenID,
@@ -99,6 +111,12 @@ func (h *EventHandler) HandleOrdenCompraRecibidaEvent(eventData []byte) error {
 		return err
 	}
 
+	// Todas las colas de órdenes reciben cada evento del exchange; ignorar los de otro tipo
+	if orderEvent.EventType != events.EventTypeOrdenCompraRecibida {
+		h.log.Debugf("Ignoring %s event on OrdenCompraRecibida handler", orderEvent.EventType)
+		return nil
+	}
+
 	h.log.WithFields(logrus.Fields{
 		"event_id":        orderEvent.EventID,
 		"orden_id":        orderEvent.OrdenID,
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"mediplus/supplier-service/internal/models"
 	"mediplus/supplier-service/internal/service"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -68,6 +70,14 @@ func (h *SupplierHandler) CreateSupplier(c *gin.Context) {
 		return
 	}
 
+	// Validar las zonas de cobertura estructuradas
+	if req.CapacidadLogistica != nil {
+		if err := req.CapacidadLogistica.NormalizarZonas(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	// Crear el proveedor
 	proveedor := models.NewProveedor(req.NombreLegal, req.RazonSocial, req.IdentificacionFiscal)
 	proveedor.Contactos = req.Contactos
@@ -159,6 +169,10 @@ func (h *SupplierHandler) UpdateSupplier(c *gin.Context) {
 		proveedor.Certificaciones = req.Certificaciones
 	}
 	if req.CapacidadLogistica != nil {
+		if err := req.CapacidadLogistica.NormalizarZonas(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		proveedor.CapacidadLogistica = req.CapacidadLogistica
 	}
 
@@ -199,11 +213,21 @@ func (h *SupplierHandler) ListSuppliers(c *gin.Context) {
 	estado := c.Query("estado")
 	certificacion := c.Query("certificacion")
 	cadenaFrio := c.Query("cadena_frio")
+	tempMin, tempMax := c.Query("temp_min"), c.Query("temp_max")
 
 	var proveedores []*models.Proveedor
 	var err error
 
-	if estado != "" {
+	if tempMin != "" || tempMax != "" {
+		// Listar proveedores cuyo rango de temperatura cubre el requerido, filtrados también por
+		// estado y certificación si se indican
+		minima, maxima, errRango := parseRangoTemperatura(tempMin, tempMax)
+		if errRango != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errRango.Error()})
+			return
+		}
+		proveedores, err = h.service.GetSuppliersForTemperatureRange(minima, maxima, models.EstadoProveedor(estado), certificacion)
+	} else if estado != "" {
 		// Listar por estado
 		estadoProveedor := models.EstadoProveedor(estado)
 		proveedores, err = h.service.ListSuppliersByEstado(estadoProveedor)
@@ -306,3 +330,235 @@ func (h *SupplierHandler) ActivateSupplier(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Supplier activated successfully"})
 }
+
+// GetSupplierStats obtiene las estadísticas de actividad de un proveedor
+func (h *SupplierHandler) GetSupplierStats(c *gin.Context) {
+	proveedorID := c.Param("id")
+	if proveedorID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Supplier ID is required"})
+		return
+	}
+
+	// Rango opcional de periodos (formato YYYY-MM)
+	desde := c.Query("desde")
+	hasta := c.Query("hasta")
+
+	proveedor, err := h.service.GetSupplier(proveedorID)
+	if err != nil {
+		h.log.Errorf("Error getting supplier: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error getting supplier"})
+		return
+	}
+
+	if proveedor == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Supplier not found"})
+		return
+	}
+
+	estadisticas, err := h.service.GetSupplierStats(proveedorID, desde, hasta)
+	if err != nil {
+		h.log.Errorf("Error getting supplier stats: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error getting supplier stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": estadisticas})
+}
+
+// GetFleetStats obtiene las estadísticas de actividad agregadas de todos los proveedores
+func (h *SupplierHandler) GetFleetStats(c *gin.Context) {
+	// Rango opcional de periodos (formato YYYY-MM)
+	desde := c.Query("desde")
+	hasta := c.Query("hasta")
+
+	total, proveedores, err := h.service.GetFleetStats(desde, hasta)
+	if err != nil {
+		h.log.Errorf("Error getting fleet stats: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error getting fleet stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"total":       total,
+			"proveedores": proveedores,
+		},
+	})
+}
+
+// FindSuppliersByLocation busca proveedores que cubren una ubicación de entrega
+func (h *SupplierHandler) FindSuppliersByLocation(c *gin.Context) {
+	ubicacion := models.UbicacionEntrega{
+		CodigoPais:         c.Query("pais"),
+		CodigoDepartamento: c.Query("departamento"),
+		CodigoCiudad:       c.Query("ciudad"),
+	}
+
+	// Coordenadas opcionales para zonas definidas por polígono
+	lat, lng := c.Query("lat"), c.Query("lng")
+	if lat != "" || lng != "" {
+		latitud, errLat := strconv.ParseFloat(lat, 64)
+		longitud, errLng := strconv.ParseFloat(lng, 64)
+		if errLat != nil || errLng != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "lat and lng must be valid numbers"})
+			return
+		}
+		ubicacion.Coordenada = &models.Coordenada{Latitud: latitud, Longitud: longitud}
+	}
+
+	if ubicacion.CodigoPais == "" && ubicacion.Coordenada == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "pais or lat/lng is required"})
+		return
+	}
+
+	proveedores, err := h.service.FindSuppliersByLocation(ubicacion)
+	if err != nil {
+		h.log.Errorf("Error finding suppliers by location: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error finding suppliers by location"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": proveedores})
+}
+
+// CheckColdChainCompatibility verifica si el proveedor cubre el rango de temperatura requerido
+func (h *SupplierHandler) CheckColdChainCompatibility(c *gin.Context) {
+	proveedorID := c.Param("id")
+	if proveedorID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Supplier ID is required"})
+		return
+	}
+
+	tempMin, tempMax, err := parseRangoTemperatura(c.Query("temp_min"), c.Query("temp_max"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resultado, err := h.service.CheckColdChainCompatibility(proveedorID, tempMin, tempMax)
+	if err != nil {
+		h.log.Errorf("Error checking cold chain compatibility: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error checking cold chain compatibility"})
+		return
+	}
+
+	if resultado == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Supplier not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": resultado})
+}
+
+// parseRangoTemperatura valida el rango de temperatura recibido en la consulta
+func parseRangoTemperatura(tempMin, tempMax string) (float64, float64, error) {
+	minima, errMin := strconv.ParseFloat(tempMin, 64)
+	maxima, errMax := strconv.ParseFloat(tempMax, 64)
+	if errMin != nil || errMax != nil {
+		return 0, 0, errors.New("temp_min and temp_max must be valid numbers")
+	}
+	if minima > maxima {
+		return 0, 0, errors.New("temp_min must be less than or equal to temp_max")
+	}
+	return minima, maxima, nil
+}
+
+// ResolveReviewTaskRequest representa la petición para resolver una tarea de revisión
+type ResolveReviewTaskRequest struct {
+	Resolucion    string `json:"resolucion" binding:"required"`
+	UsuarioID     string `json:"usuario_id"`
+	FalsoPositivo bool   `json:"falso_positivo"`
+}
+
+// ScreenSupplier verifica un proveedor contra la lista de sanciones
+func (h *SupplierHandler) ScreenSupplier(c *gin.Context) {
+	proveedorID := c.Param("id")
+	if proveedorID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Supplier ID is required"})
+		return
+	}
+
+	resultado, err := h.service.ScreenSupplier(proveedorID)
+	if err != nil {
+		h.log.Errorf("Error screening supplier: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error screening supplier"})
+		return
+	}
+
+	if resultado == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Supplier not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": resultado})
+}
+
+// ReloadSanctionsList recarga la lista de sanciones desde su archivo
+func (h *SupplierHandler) ReloadSanctionsList(c *gin.Context) {
+	entradas, err := h.service.ReloadSanctionsList()
+	if errors.Is(err, service.ErrSanctionsListNotConfigured) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.log.Errorf("Error reloading sanctions list: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error reloading sanctions list"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Sanctions list reloaded successfully",
+		"data":    gin.H{"entradas": entradas},
+	})
+}
+
+// ListReviewTasks lista las tareas de revisión (por defecto las pendientes)
+func (h *SupplierHandler) ListReviewTasks(c *gin.Context) {
+	estado := models.EstadoTareaRevision(c.Query("estado"))
+
+	tareas, err := h.service.ListReviewTasks(estado)
+	if err != nil {
+		h.log.Errorf("Error listing review tasks: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error listing review tasks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tareas})
+}
+
+// ResolveReviewTask resuelve una tarea de revisión
+func (h *SupplierHandler) ResolveReviewTask(c *gin.Context) {
+	tareaID := c.Param("id")
+	if tareaID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Task ID is required"})
+		return
+	}
+
+	var req ResolveReviewTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Errorf("Error binding request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tarea, err := h.service.ResolveReviewTask(tareaID, req.Resolucion, req.UsuarioID, req.FalsoPositivo)
+	if err != nil {
+		if errors.Is(err, service.ErrReviewTaskResolved) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		h.log.Errorf("Error resolving review task: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error resolving review task"})
+		return
+	}
+
+	if tarea == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review task not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Review task resolved successfully",
+		"data":    tarea,
+	})
+}
@@ -0,0 +1,50 @@
+package models
+
+import "fmt"
+
+// CompatibilidadCadenaFrio representa el resultado de comparar el rango de temperatura
+// validado de un proveedor con el rango requerido por un producto
+type CompatibilidadCadenaFrio struct {
+	ProveedorID                string  `json:"proveedor_id"`
+	Compatible                 bool    `json:"compatible"`
+	Motivo                     string  `json:"motivo,omitempty"`
+	TemperaturaMinimaProveedor float64 `json:"temperatura_minima_proveedor"`
+	TemperaturaMaximaProveedor float64 `json:"temperatura_maxima_proveedor"`
+	TemperaturaMinimaRequerida float64 `json:"temperatura_minima_requerida"`
+	TemperaturaMaximaRequerida float64 `json:"temperatura_maxima_requerida"`
+}
+
+// CubreRangoTemperatura indica si el rango validado del proveedor contiene por completo
+// el rango requerido [tempMin, tempMax]. Si no lo cubre, retorna el motivo.
+func (c *CapacidadLogistica) CubreRangoTemperatura(tempMin, tempMax float64) (bool, string) {
+	if c == nil || !c.CapacidadCadenaFrio {
+		return false, "el proveedor no tiene capacidad de cadena de frío"
+	}
+	if c.TemperaturaMinima > c.TemperaturaMaxima {
+		return false, "el rango de temperatura del proveedor es inválido"
+	}
+	if tempMin < c.TemperaturaMinima {
+		return false, fmt.Sprintf("la temperatura mínima requerida (%.1f°C) es inferior a la mínima del proveedor (%.1f°C)", tempMin, c.TemperaturaMinima)
+	}
+	if tempMax > c.TemperaturaMaxima {
+		return false, fmt.Sprintf("la temperatura máxima requerida (%.1f°C) es superior a la máxima del proveedor (%.1f°C)", tempMax, c.TemperaturaMaxima)
+	}
+	return true, ""
+}
+
+// EvaluarCadenaFrio compara la capacidad logística del proveedor con el rango requerido
+func (p *Proveedor) EvaluarCadenaFrio(tempMin, tempMax float64) *CompatibilidadCadenaFrio {
+	resultado := &CompatibilidadCadenaFrio{
+		ProveedorID:                p.ProveedorID,
+		TemperaturaMinimaRequerida: tempMin,
+		TemperaturaMaximaRequerida: tempMax,
+	}
+
+	if p.CapacidadLogistica != nil {
+		resultado.TemperaturaMinimaProveedor = p.CapacidadLogistica.TemperaturaMinima
+		resultado.TemperaturaMaximaProveedor = p.CapacidadLogistica.TemperaturaMaxima
+	}
+
+	resultado.Compatible, resultado.Motivo = p.CapacidadLogistica.CubreRangoTemperatura(tempMin, tempMax)
+	return resultado
+}
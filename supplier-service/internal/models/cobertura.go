@@ -0,0 +1,194 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// TipoZona representa el nivel geográfico de una zona de cobertura
+type TipoZona string
+
+const (
+	TipoZonaPais         TipoZona = "PAIS"
+	TipoZonaDepartamento TipoZona = "DEPARTAMENTO"
+	TipoZonaCiudad       TipoZona = "CIUDAD"
+	TipoZonaPoligono     TipoZona = "POLIGONO"
+)
+
+// Coordenada representa un punto geográfico
+type Coordenada struct {
+	Latitud  float64 `json:"latitud" dynamodbav:"latitud"`
+	Longitud float64 `json:"longitud" dynamodbav:"longitud"`
+}
+
+// ZonaCobertura representa una región en la que el proveedor puede entregar
+type ZonaCobertura struct {
+	ZonaID             string       `json:"zona_id" dynamodbav:"zona_id"`
+	Nombre             string       `json:"nombre" dynamodbav:"nombre"`
+	Tipo               TipoZona     `json:"tipo" dynamodbav:"tipo"`
+	CodigoPais         string       `json:"codigo_pais" dynamodbav:"codigo_pais"`
+	CodigoDepartamento string       `json:"codigo_departamento,omitempty" dynamodbav:"codigo_departamento"`
+	CodigoCiudad       string       `json:"codigo_ciudad,omitempty" dynamodbav:"codigo_ciudad"`
+	Poligono           []Coordenada `json:"poligono,omitempty" dynamodbav:"poligono"`
+	Centroide          *Coordenada  `json:"centroide,omitempty" dynamodbav:"centroide"`
+	TiempoEntregaDias  int          `json:"tiempo_entrega_dias" dynamodbav:"tiempo_entrega_dias"`
+}
+
+// UbicacionEntrega representa el lugar al que se debe entregar (por ejemplo, un hospital)
+type UbicacionEntrega struct {
+	CodigoPais         string      `json:"codigo_pais"`
+	CodigoDepartamento string      `json:"codigo_departamento"`
+	CodigoCiudad       string      `json:"codigo_ciudad"`
+	Coordenada         *Coordenada `json:"coordenada"`
+}
+
+// ProveedorCobertura representa un proveedor que cubre una ubicación de entrega
+type ProveedorCobertura struct {
+	ProveedorID           string        `json:"proveedor_id"`
+	NombreLegal           string        `json:"nombre_legal"`
+	Zona                  ZonaCobertura `json:"zona"`
+	TiempoEntregaEstimado int           `json:"tiempo_entrega_estimado_dias"`
+	DistanciaCentroideKm  *float64      `json:"distancia_centroide_km,omitempty"`
+	CapacidadCadenaFrio   bool          `json:"capacidad_cadena_frio"`
+	ScoreGeneral          float64       `json:"score_general"`
+}
+
+// ErrZonaInvalida se retorna cuando una zona de cobertura está mal definida
+var ErrZonaInvalida = errors.New("zona de cobertura inválida")
+
+// radioTierraKm es el radio medio de la Tierra usado para calcular distancias
+const radioTierraKm = 6371.0
+
+// Normalizar valida la zona, completa su ID y calcula el centroide de los polígonos
+func (z *ZonaCobertura) Normalizar() error {
+	if z.ZonaID == "" {
+		z.ZonaID = uuid.New().String()
+	}
+	z.CodigoPais = strings.ToUpper(strings.TrimSpace(z.CodigoPais))
+	z.CodigoDepartamento = strings.ToUpper(strings.TrimSpace(z.CodigoDepartamento))
+	z.CodigoCiudad = strings.ToUpper(strings.TrimSpace(z.CodigoCiudad))
+
+	if z.TiempoEntregaDias < 0 {
+		return errors.New("tiempo_entrega_dias no puede ser negativo")
+	}
+
+	switch z.Tipo {
+	case TipoZonaPais:
+		if z.CodigoPais == "" {
+			return errors.New("una zona PAIS requiere codigo_pais")
+		}
+	case TipoZonaDepartamento:
+		if z.CodigoPais == "" || z.CodigoDepartamento == "" {
+			return errors.New("una zona DEPARTAMENTO requiere codigo_pais y codigo_departamento")
+		}
+	case TipoZonaCiudad:
+		if z.CodigoPais == "" || z.CodigoDepartamento == "" || z.CodigoCiudad == "" {
+			return errors.New("una zona CIUDAD requiere codigo_pais, codigo_departamento y codigo_ciudad")
+		}
+	case TipoZonaPoligono:
+		if len(z.Poligono) < 3 {
+			return errors.New("una zona POLIGONO requiere al menos 3 vértices")
+		}
+		if z.Centroide == nil {
+			z.Centroide = calcularCentroide(z.Poligono)
+		}
+	default:
+		return ErrZonaInvalida
+	}
+
+	return nil
+}
+
+// Cubre indica si la zona incluye la ubicación de entrega
+func (z *ZonaCobertura) Cubre(ubicacion UbicacionEntrega) bool {
+	pais := strings.ToUpper(ubicacion.CodigoPais)
+	departamento := strings.ToUpper(ubicacion.CodigoDepartamento)
+	ciudad := strings.ToUpper(ubicacion.CodigoCiudad)
+
+	switch z.Tipo {
+	case TipoZonaPais:
+		return pais != "" && z.CodigoPais == pais
+	case TipoZonaDepartamento:
+		return pais != "" && z.CodigoPais == pais && z.CodigoDepartamento == departamento
+	case TipoZonaCiudad:
+		return pais != "" && z.CodigoPais == pais && z.CodigoDepartamento == departamento && z.CodigoCiudad == ciudad
+	case TipoZonaPoligono:
+		return ubicacion.Coordenada != nil && contienePunto(z.Poligono, *ubicacion.Coordenada)
+	default:
+		return false
+	}
+}
+
+// ZonasQueCubren retorna las zonas estructuradas de la capacidad logística que cubren la ubicación
+func (c *CapacidadLogistica) ZonasQueCubren(ubicacion UbicacionEntrega) []ZonaCobertura {
+	var zonas []ZonaCobertura
+	for _, zona := range c.Zonas {
+		if zona.Cubre(ubicacion) {
+			zonas = append(zonas, zona)
+		}
+	}
+	return zonas
+}
+
+// NormalizarZonas valida y completa todas las zonas estructuradas
+func (c *CapacidadLogistica) NormalizarZonas() error {
+	for i := range c.Zonas {
+		if err := c.Zonas[i].Normalizar(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TiempoEntregaZona retorna el tiempo de entrega de la zona o, si no está definido, el promedio general
+func (c *CapacidadLogistica) TiempoEntregaZona(zona ZonaCobertura) int {
+	if zona.TiempoEntregaDias > 0 {
+		return zona.TiempoEntregaDias
+	}
+	return c.TiempoEntregaPromedio
+}
+
+// DistanciaKm calcula la distancia del círculo máximo entre dos coordenadas (fórmula de haversine)
+func DistanciaKm(a, b Coordenada) float64 {
+	lat1 := a.Latitud * math.Pi / 180
+	lat2 := b.Latitud * math.Pi / 180
+	dLat := (b.Latitud - a.Latitud) * math.Pi / 180
+	dLon := (b.Longitud - a.Longitud) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * radioTierraKm * math.Asin(math.Sqrt(h))
+}
+
+// contienePunto determina si un punto está dentro del polígono (algoritmo de ray casting)
+func contienePunto(poligono []Coordenada, punto Coordenada) bool {
+	dentro := false
+	j := len(poligono) - 1
+	for i := 0; i < len(poligono); i++ {
+		pi, pj := poligono[i], poligono[j]
+		if (pi.Latitud > punto.Latitud) != (pj.Latitud > punto.Latitud) {
+			cruce := (pj.Longitud-pi.Longitud)*(punto.Latitud-pi.Latitud)/(pj.Latitud-pi.Latitud) + pi.Longitud
+			if punto.Longitud < cruce {
+				dentro = !dentro
+			}
+		}
+		j = i
+	}
+	return dentro
+}
+
+// calcularCentroide calcula el promedio de los vértices del polígono
+func calcularCentroide(poligono []Coordenada) *Coordenada {
+	var centroide Coordenada
+	for _, vertice := range poligono {
+		centroide.Latitud += vertice.Latitud
+		centroide.Longitud += vertice.Longitud
+	}
+	centroide.Latitud /= float64(len(poligono))
+	centroide.Longitud /= float64(len(poligono))
+	return &centroide
+}
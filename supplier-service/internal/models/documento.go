@@ -0,0 +1,112 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Documento representa un archivo adjunto a un proveedor o a una de sus certificaciones.
+// El contenido vive en el almacenamiento de documentos; aquí solo se guardan los metadatos.
+type Documento struct {
+	DocumentoID       string             `json:"documento_id" dynamodbav:"documento_id"`
+	ProveedorID       string             `json:"proveedor_id" dynamodbav:"proveedor_id"`
+	NumeroCertificado string             `json:"numero_certificado,omitempty" dynamodbav:"numero_certificado"`
+	TipoDocumento     string             `json:"tipo_documento" dynamodbav:"tipo_documento"`
+	Nombre            string             `json:"nombre" dynamodbav:"nombre"`
+	VersionActual     int                `json:"version_actual" dynamodbav:"version_actual"`
+	Versiones         []VersionDocumento `json:"versiones" dynamodbav:"versiones"`
+	RetenerHasta      time.Time          `json:"retener_hasta" dynamodbav:"retener_hasta"`
+	ArchivadoEn       *time.Time         `json:"archivado_en,omitempty" dynamodbav:"archivado_en,omitempty"`
+	CreatedAt         time.Time          `json:"created_at" dynamodbav:"created_at"`
+	UpdatedAt         time.Time          `json:"updated_at" dynamodbav:"updated_at"`
+}
+
+// VersionDocumento representa una versión subida de un documento
+type VersionDocumento struct {
+	Version       int       `json:"version" dynamodbav:"version"`
+	NombreArchivo string    `json:"nombre_archivo" dynamodbav:"nombre_archivo"`
+	StorageKey    string    `json:"-" dynamodbav:"storage_key"`
+	Checksum      string    `json:"checksum_sha256" dynamodbav:"checksum_sha256"`
+	ContentType   string    `json:"content_type" dynamodbav:"content_type"`
+	Tamano        int64     `json:"tamano_bytes" dynamodbav:"tamano_bytes"`
+	SubidoPor     string    `json:"subido_por" dynamodbav:"subido_por"`
+	FechaSubida   time.Time `json:"fecha_subida" dynamodbav:"fecha_subida"`
+}
+
+// NewDocumento crea un nuevo documento sin versiones
+func NewDocumento(proveedorID, numeroCertificado, tipoDocumento, nombre string) *Documento {
+	now := time.Now()
+	return &Documento{
+		DocumentoID:       uuid.New().String(),
+		ProveedorID:       proveedorID,
+		NumeroCertificado: numeroCertificado,
+		TipoDocumento:     tipoDocumento,
+		Nombre:            nombre,
+		Versiones:         []VersionDocumento{},
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+}
+
+// AgregarVersion registra una nueva versión y la deja como actual. Un documento archivado
+// por la depuración vuelve a estar vigente.
+func (d *Documento) AgregarVersion(version VersionDocumento) {
+	d.VersionActual++
+	version.Version = d.VersionActual
+	d.Versiones = append(d.Versiones, version)
+	d.ArchivadoEn = nil
+	d.UpdatedAt = time.Now()
+}
+
+// ObtenerVersion retorna la versión indicada, o la actual si version es 0
+func (d *Documento) ObtenerVersion(version int) *VersionDocumento {
+	if version == 0 {
+		version = d.VersionActual
+	}
+	for i := range d.Versiones {
+		if d.Versiones[i].Version == version {
+			return &d.Versiones[i]
+		}
+	}
+	return nil
+}
+
+// CalcularRetencion actualiza la fecha hasta la que el documento debe conservarse:
+// el periodo de retención se cuenta desde el vencimiento de la certificación asociada
+// o, si es posterior, desde la última versión subida
+func (d *Documento) CalcularRetencion(vencimientoCertificacion time.Time, retencion time.Duration) {
+	base := d.UpdatedAt
+	if vencimientoCertificacion.After(base) {
+		base = vencimientoCertificacion
+	}
+	if hasta := base.Add(retencion); hasta.After(d.RetenerHasta) {
+		d.RetenerHasta = hasta
+	}
+}
+
+// Retenido indica si el documento aún está dentro de su periodo de retención
+func (d *Documento) Retenido(ahora time.Time) bool {
+	return ahora.Before(d.RetenerHasta)
+}
+
+// Archivar marca el documento como pendiente de eliminación; su contenido se conserva
+func (d *Documento) Archivar(ahora time.Time) {
+	d.ArchivadoEn = &ahora
+	d.UpdatedAt = ahora
+}
+
+// PuedeEliminarse indica si el documento lleva archivado al menos el periodo de gracia indicado
+func (d *Documento) PuedeEliminarse(ahora time.Time, gracia time.Duration) bool {
+	return d.ArchivadoEn != nil && !ahora.Before(d.ArchivadoEn.Add(gracia))
+}
+
+// BuscarCertificacion retorna la certificación del proveedor con el número indicado
+func (p *Proveedor) BuscarCertificacion(numeroCertificado string) *Certificacion {
+	for i := range p.Certificaciones {
+		if p.Certificaciones[i].NumeroCertificado == numeroCertificado {
+			return &p.Certificaciones[i]
+		}
+	}
+	return nil
+}
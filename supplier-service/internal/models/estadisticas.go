@@ -0,0 +1,113 @@
+package models
+
+import (
+	"time"
+)
+
+// EstadisticasProveedor representa la proyección de actividad de un proveedor,
+// mantenida de forma incremental a partir de los eventos de órdenes. Los contadores,
+// los promedios y la tasa de recepción son históricos (cuentan órdenes, no cantidades);
+// solo OrdenesRecibidasPorPeriodo se desglosa por mes.
+type EstadisticasProveedor struct {
+	ProveedorID                  string         `json:"proveedor_id" dynamodbav:"proveedor_id"`
+	OrdenesGeneradas             int            `json:"ordenes_generadas" dynamodbav:"ordenes_generadas"`
+	OrdenesConfirmadas           int            `json:"ordenes_confirmadas" dynamodbav:"ordenes_confirmadas"`
+	OrdenesRecibidas             int            `json:"ordenes_recibidas" dynamodbav:"ordenes_recibidas"`
+	OrdenesRecibidasPorPeriodo   map[string]int `json:"ordenes_recibidas_por_periodo" dynamodbav:"ordenes_recibidas_por_periodo"`
+	HorasConfirmacionAcumuladas  float64        `json:"-" dynamodbav:"horas_confirmacion_acumuladas"`
+	ConfirmacionesMedidas        int            `json:"-" dynamodbav:"confirmaciones_medidas"`
+	HorasEntregaAcumuladas       float64        `json:"-" dynamodbav:"horas_entrega_acumuladas"`
+	EntregasMedidas              int            `json:"-" dynamodbav:"entregas_medidas"`
+	Suspensiones                 int            `json:"suspensiones" dynamodbav:"suspensiones"`
+	TiempoConfirmacionPromedioHr float64        `json:"tiempo_confirmacion_promedio_horas" dynamodbav:"-"`
+	TiempoEntregaPromedioHr      float64        `json:"tiempo_entrega_promedio_horas" dynamodbav:"-"`
+	TasaRecepcionOrdenes         float64        `json:"tasa_recepcion_ordenes" dynamodbav:"-"`
+	UltimaActividad              time.Time      `json:"ultima_actividad" dynamodbav:"ultima_actividad"`
+}
+
+// SeguimientoOrden guarda las fechas de una orden necesarias para medir tiempos
+// de confirmación y entrega sin recorrer las trazas de auditoría
+type SeguimientoOrden struct {
+	OrdenID           string    `json:"orden_id" dynamodbav:"orden_id"`
+	ProveedorID       string    `json:"proveedor_id" dynamodbav:"proveedor_id"`
+	FechaGeneracion   time.Time `json:"fecha_generacion" dynamodbav:"fecha_generacion"`
+	FechaConfirmacion time.Time `json:"fecha_confirmacion" dynamodbav:"fecha_confirmacion"`
+}
+
+// FormatoPeriodo es el formato de las claves de OrdenesRecibidasPorPeriodo (año-mes)
+const FormatoPeriodo = "2006-01"
+
+// NewEstadisticasProveedor crea una proyección vacía para un proveedor
+func NewEstadisticasProveedor(proveedorID string) *EstadisticasProveedor {
+	return &EstadisticasProveedor{
+		ProveedorID:                proveedorID,
+		OrdenesRecibidasPorPeriodo: map[string]int{},
+	}
+}
+
+// CalcularIndicadores calcula los promedios y la tasa de recepción a partir de los acumulados
+func (e *EstadisticasProveedor) CalcularIndicadores() {
+	e.TiempoConfirmacionPromedioHr = 0
+	if e.ConfirmacionesMedidas > 0 {
+		e.TiempoConfirmacionPromedioHr = e.HorasConfirmacionAcumuladas / float64(e.ConfirmacionesMedidas)
+	}
+
+	e.TiempoEntregaPromedioHr = 0
+	if e.EntregasMedidas > 0 {
+		e.TiempoEntregaPromedioHr = e.HorasEntregaAcumuladas / float64(e.EntregasMedidas)
+	}
+
+	// La tasa de recepción es la fracción de órdenes confirmadas que fueron recibidas; no mide
+	// las cantidades entregadas de cada item
+	e.TasaRecepcionOrdenes = 0
+	if e.OrdenesConfirmadas > 0 {
+		e.TasaRecepcionOrdenes = float64(e.OrdenesRecibidas) / float64(e.OrdenesConfirmadas)
+		if e.TasaRecepcionOrdenes > 1 {
+			e.TasaRecepcionOrdenes = 1
+		}
+	}
+}
+
+// FiltrarPeriodos conserva solo los periodos de OrdenesRecibidasPorPeriodo dentro del rango
+// [desde, hasta] (formato año-mes); el resto de los indicadores no se filtra.
+// Un límite vacío no restringe el rango.
+func (e *EstadisticasProveedor) FiltrarPeriodos(desde, hasta string) {
+	if desde == "" && hasta == "" {
+		return
+	}
+
+	filtrados := map[string]int{}
+	for periodo, cantidad := range e.OrdenesRecibidasPorPeriodo {
+		if desde != "" && periodo < desde {
+			continue
+		}
+		if hasta != "" && periodo > hasta {
+			continue
+		}
+		filtrados[periodo] = cantidad
+	}
+	e.OrdenesRecibidasPorPeriodo = filtrados
+}
+
+// Acumular suma a la proyección los contadores de otra proyección
+func (e *EstadisticasProveedor) Acumular(otra *EstadisticasProveedor) {
+	e.OrdenesGeneradas += otra.OrdenesGeneradas
+	e.OrdenesConfirmadas += otra.OrdenesConfirmadas
+	e.OrdenesRecibidas += otra.OrdenesRecibidas
+	e.HorasConfirmacionAcumuladas += otra.HorasConfirmacionAcumuladas
+	e.ConfirmacionesMedidas += otra.ConfirmacionesMedidas
+	e.HorasEntregaAcumuladas += otra.HorasEntregaAcumuladas
+	e.EntregasMedidas += otra.EntregasMedidas
+	e.Suspensiones += otra.Suspensiones
+
+	if e.OrdenesRecibidasPorPeriodo == nil {
+		e.OrdenesRecibidasPorPeriodo = map[string]int{}
+	}
+	for periodo, cantidad := range otra.OrdenesRecibidasPorPeriodo {
+		e.OrdenesRecibidasPorPeriodo[periodo] += cantidad
+	}
+
+	if otra.UltimaActividad.After(e.UltimaActividad) {
+		e.UltimaActividad = otra.UltimaActividad
+	}
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EntradaSancion representa una entrada de la lista de sanciones o inhabilidades
+type EntradaSancion struct {
+	Nombre               string `json:"nombre" dynamodbav:"nombre"`
+	IdentificacionFiscal string `json:"identificacion_fiscal" dynamodbav:"identificacion_fiscal"`
+	Fuente               string `json:"fuente" dynamodbav:"fuente"`
+	Motivo               string `json:"motivo" dynamodbav:"motivo"`
+}
+
+// TipoCoincidencia representa cómo se detectó una coincidencia con la lista de sanciones
+type TipoCoincidencia string
+
+const (
+	CoincidenciaExacta         TipoCoincidencia = "EXACTA"
+	CoincidenciaAproximada     TipoCoincidencia = "APROXIMADA"
+	CoincidenciaIdentificacion TipoCoincidencia = "IDENTIFICACION_FISCAL"
+)
+
+// CoincidenciaSancion representa una coincidencia entre un proveedor y una entrada de la lista
+type CoincidenciaSancion struct {
+	Campo     string           `json:"campo" dynamodbav:"campo"`
+	Valor     string           `json:"valor" dynamodbav:"valor"`
+	Tipo      TipoCoincidencia `json:"tipo" dynamodbav:"tipo"`
+	Similitud float64          `json:"similitud" dynamodbav:"similitud"`
+	Entrada   EntradaSancion   `json:"entrada" dynamodbav:"entrada"`
+}
+
+// ResultadoScreening representa el resultado de verificar un proveedor contra la lista de sanciones
+type ResultadoScreening struct {
+	ProveedorID    string                `json:"proveedor_id"`
+	FechaScreening time.Time             `json:"fecha_screening"`
+	Coincidencias  []CoincidenciaSancion `json:"coincidencias"`
+	Suspendido     bool                  `json:"suspendido"`
+	TareaID        string                `json:"tarea_id,omitempty"`
+}
+
+// EstadoTareaRevision representa el estado de una tarea de revisión
+type EstadoTareaRevision string
+
+const (
+	EstadoTareaPendiente EstadoTareaRevision = "PENDIENTE"
+	EstadoTareaResuelta  EstadoTareaRevision = "RESUELTA"
+)
+
+// TipoTareaSanciones identifica las tareas creadas por el screening de sanciones
+const TipoTareaSanciones = "SCREENING_SANCIONES"
+
+// TareaRevision representa una tarea de revisión manual sobre un proveedor
+type TareaRevision struct {
+	TareaID         string                `json:"tarea_id" dynamodbav:"tarea_id"`
+	ProveedorID     string                `json:"proveedor_id" dynamodbav:"proveedor_id"`
+	Tipo            string                `json:"tipo" dynamodbav:"tipo"`
+	Descripcion     string                `json:"descripcion" dynamodbav:"descripcion"`
+	Coincidencias   []CoincidenciaSancion `json:"coincidencias" dynamodbav:"coincidencias"`
+	Estado          EstadoTareaRevision   `json:"estado" dynamodbav:"estado"`
+	Resolucion      string                `json:"resolucion" dynamodbav:"resolucion"`
+	FalsoPositivo   bool                  `json:"falso_positivo" dynamodbav:"falso_positivo"`
+	ResueltaPor     string                `json:"resuelta_por" dynamodbav:"resuelta_por"`
+	FechaCreacion   time.Time             `json:"fecha_creacion" dynamodbav:"fecha_creacion"`
+	FechaResolucion time.Time             `json:"fecha_resolucion" dynamodbav:"fecha_resolucion"`
+}
+
+// NewTareaRevision crea una nueva tarea de revisión pendiente
+func NewTareaRevision(proveedorID, tipo, descripcion string, coincidencias []CoincidenciaSancion) *TareaRevision {
+	return &TareaRevision{
+		TareaID:       uuid.New().String(),
+		ProveedorID:   proveedorID,
+		Tipo:          tipo,
+		Descripcion:   descripcion,
+		Coincidencias: coincidencias,
+		Estado:        EstadoTareaPendiente,
+		FechaCreacion: time.Now(),
+	}
+}
+
+// Resolver marca la tarea como resuelta. Si es un falso positivo, sus coincidencias
+// se descartan en los siguientes screenings del proveedor.
+func (t *TareaRevision) Resolver(resolucion, usuarioID string, falsoPositivo bool) {
+	t.Estado = EstadoTareaResuelta
+	t.Resolucion = resolucion
+	t.FalsoPositivo = falsoPositivo
+	t.ResueltaPor = usuarioID
+	t.FechaResolucion = time.Now()
+}
+
+// Descarta indica si la coincidencia ya fue revisada y descartada como falso positivo en esta tarea
+func (t *TareaRevision) Descarta(coincidencia CoincidenciaSancion) bool {
+	if !t.FalsoPositivo {
+		return false
+	}
+	for _, c := range t.Coincidencias {
+		if c.Campo == coincidencia.Campo && c.Valor == coincidencia.Valor && c.Entrada == coincidencia.Entrada {
+			return true
+		}
+	}
+	return false
+}
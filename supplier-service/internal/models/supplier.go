@@ -51,6 +51,10 @@ type ProductoOfrecido struct {
 	PrecioBase           float64              `json:"precio_base" dynamodbav:"precio_base"`
 	Moneda               string               `json:"moneda" dynamodbav:"moneda"`
 	EstadoDisponibilidad EstadoDisponibilidad `json:"estado_disponibilidad" dynamodbav:"estado_disponibilidad"`
+
+	// Precio pactado por contrato; reemplaza al precio base mientras el contrato esté vigente
+	PrecioContratado     float64    `json:"precio_contratado,omitempty" dynamodbav:"precio_contratado,omitempty"`
+	ContratoVigenteHasta *time.Time `json:"contrato_vigente_hasta,omitempty" dynamodbav:"contrato_vigente_hasta,omitempty"`
 }
 
 // EstadoDisponibilidad representa el estado de disponibilidad del producto
@@ -97,7 +101,9 @@ type CapacidadLogistica struct {
 	TemperaturaMaxima       float64 `json:"temperatura_maxima" dynamodbav:"temperatura_maxima"`
 	CapacidadAlmacenamiento int     `json:"capacidad_almacenamiento" dynamodbav:"capacidad_almacenamiento"`
 	TiempoEntregaPromedio   int     `json:"tiempo_entrega_promedio" dynamodbav:"tiempo_entrega_promedio"`
-	ZonasCobertura          string  `json:"zonas_cobertura" dynamodbav:"zonas_cobertura"`
+	// ZonasCobertura es la descripción libre heredada; las búsquedas geográficas usan Zonas
+	ZonasCobertura string          `json:"zonas_cobertura" dynamodbav:"zonas_cobertura"`
+	Zonas          []ZonaCobertura `json:"zonas" dynamodbav:"zonas"`
 }
 
 // AuditoriaTraza representa una entrada de auditoría
@@ -162,3 +168,13 @@ func NewCertificacion(tipo, numero, autoridad string, fechaEmision, fechaVencimi
 		Estado:            estado,
 	}
 }
+
+// TieneCertificacion indica si el proveedor tiene una certificación del tipo indicado
+func (p *Proveedor) TieneCertificacion(tipoCertificacion string) bool {
+	for _, certificacion := range p.Certificaciones {
+		if certificacion.TipoCertificacion == tipoCertificacion {
+			return true
+		}
+	}
+	return false
+}
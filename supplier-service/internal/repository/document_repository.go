@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"errors"
+	"mediplus/supplier-service/internal/database"
+	"mediplus/supplier-service/internal/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrDocumentoModificado se retorna cuando otra escritura cambió la versión actual del documento
+// desde que se leyó
+var ErrDocumentoModificado = errors.New("document was modified concurrently")
+
+// DocumentRepository define la interfaz para el repositorio de metadatos de documentos
+type DocumentRepository interface {
+	Save(documento *models.Documento, versionAnterior int) error
+	GetByID(documentoID string) (*models.Documento, error)
+	Delete(documentoID string) error
+	ListByProveedor(proveedorID string) ([]*models.Documento, error)
+	ListAll() ([]*models.Documento, error)
+}
+
+// documentRepository implementa DocumentRepository
+type documentRepository struct {
+	db  *database.DynamoDBClient
+	log *logrus.Logger
+}
+
+// NewDocumentRepository crea una nueva instancia de DocumentRepository
+func NewDocumentRepository(db *database.DynamoDBClient, log *logrus.Logger) DocumentRepository {
+	return &documentRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Save crea o reemplaza los metadatos de un documento solo si su versión actual sigue siendo la
+// leída (versionAnterior 0 para un documento nuevo)
+func (r *documentRepository) Save(documento *models.Documento, versionAnterior int) error {
+	item, err := dynamodbattribute.MarshalMap(documento)
+	if err != nil {
+		return err
+	}
+
+	condicion := expression.Name("version_actual").Equal(expression.Value(versionAnterior))
+	if versionAnterior == 0 {
+		condicion = expression.AttributeNotExists(expression.Name("documento_id"))
+	}
+	expr, err := expression.NewBuilder().WithCondition(condicion).Build()
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:                 aws.String("supplier_documents"),
+		Item:                      item,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}
+
+	_, err = r.db.GetClient().PutItem(input)
+	if err != nil {
+		var fallida *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &fallida) {
+			return ErrDocumentoModificado
+		}
+		r.log.Errorf("Error saving document: %v", err)
+		return err
+	}
+
+	r.log.Infof("Document saved successfully: %s (version %d)", documento.DocumentoID, documento.VersionActual)
+	return nil
+}
+
+// GetByID obtiene los metadatos de un documento por su ID
+func (r *documentRepository) GetByID(documentoID string) (*models.Documento, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String("supplier_documents"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"documento_id": {
+				S: aws.String(documentoID),
+			},
+		},
+	}
+
+	result, err := r.db.GetClient().GetItem(input)
+	if err != nil {
+		r.log.Errorf("Error getting document: %v", err)
+		return nil, err
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var documento models.Documento
+	err = dynamodbattribute.UnmarshalMap(result.Item, &documento)
+	if err != nil {
+		r.log.Errorf("Error unmarshaling document: %v", err)
+		return nil, err
+	}
+
+	return &documento, nil
+}
+
+// Delete elimina los metadatos de un documento
+func (r *documentRepository) Delete(documentoID string) error {
+	input := &dynamodb.DeleteItemInput{
+		TableName: aws.String("supplier_documents"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"documento_id": {
+				S: aws.String(documentoID),
+			},
+		},
+	}
+
+	_, err := r.db.GetClient().DeleteItem(input)
+	if err != nil {
+		r.log.Errorf("Error deleting document: %v", err)
+		return err
+	}
+
+	r.log.Infof("Document deleted successfully: %s", documentoID)
+	return nil
+}
+
+// ListByProveedor lista los documentos de un proveedor
+func (r *documentRepository) ListByProveedor(proveedorID string) ([]*models.Documento, error) {
+	filter := expression.Name("proveedor_id").Equal(expression.Value(proveedorID))
+	expr, err := expression.NewBuilder().WithFilter(filter).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.scan(&dynamodb.ScanInput{
+		TableName:                 aws.String("supplier_documents"),
+		FilterExpression:          expr.Filter(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+}
+
+// ListAll lista todos los documentos
+func (r *documentRepository) ListAll() ([]*models.Documento, error) {
+	return r.scan(&dynamodb.ScanInput{
+		TableName: aws.String("supplier_documents"),
+	})
+}
+
+// scan recorre la tabla de documentos con la entrada indicada
+func (r *documentRepository) scan(input *dynamodb.ScanInput) ([]*models.Documento, error) {
+	var documentos []*models.Documento
+	err := r.db.GetClient().ScanPages(input, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			var documento models.Documento
+			if err := dynamodbattribute.UnmarshalMap(item, &documento); err != nil {
+				r.log.Errorf("Error unmarshaling document: %v", err)
+				continue
+			}
+			documentos = append(documentos, &documento)
+		}
+		return true
+	})
+	if err != nil {
+		r.log.Errorf("Error scanning documents: %v", err)
+		return nil, err
+	}
+
+	return documentos, nil
+}
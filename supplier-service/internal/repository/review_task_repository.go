@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"mediplus/supplier-service/internal/database"
+	"mediplus/supplier-service/internal/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/sirupsen/logrus"
+)
+
+// ReviewTaskRepository define la interfaz para el repositorio de tareas de revisión
+type ReviewTaskRepository interface {
+	Create(tarea *models.TareaRevision) error
+	GetByID(tareaID string) (*models.TareaRevision, error)
+	Update(tarea *models.TareaRevision) error
+	ListByEstado(estado models.EstadoTareaRevision) ([]*models.TareaRevision, error)
+	ListByProveedor(proveedorID, tipo string) ([]*models.TareaRevision, error)
+}
+
+// reviewTaskRepository implementa ReviewTaskRepository
+type reviewTaskRepository struct {
+	db  *database.DynamoDBClient
+	log *logrus.Logger
+}
+
+// NewReviewTaskRepository crea una nueva instancia de ReviewTaskRepository
+func NewReviewTaskRepository(db *database.DynamoDBClient, log *logrus.Logger) ReviewTaskRepository {
+	return &reviewTaskRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Create crea una nueva tarea de revisión
+func (r *reviewTaskRepository) Create(tarea *models.TareaRevision) error {
+	item, err := dynamodbattribute.MarshalMap(tarea)
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String("review_tasks"),
+		Item:      item,
+	}
+
+	_, err = r.db.GetClient().PutItem(input)
+	if err != nil {
+		r.log.Errorf("Error creating review task: %v", err)
+		return err
+	}
+
+	r.log.Infof("Review task created successfully: %s", tarea.TareaID)
+	return nil
+}
+
+// GetByID obtiene una tarea de revisión por su ID
+func (r *reviewTaskRepository) GetByID(tareaID string) (*models.TareaRevision, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String("review_tasks"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"tarea_id": {
+				S: aws.String(tareaID),
+			},
+		},
+	}
+
+	result, err := r.db.GetClient().GetItem(input)
+	if err != nil {
+		r.log.Errorf("Error getting review task: %v", err)
+		return nil, err
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var tarea models.TareaRevision
+	err = dynamodbattribute.UnmarshalMap(result.Item, &tarea)
+	if err != nil {
+		r.log.Errorf("Error unmarshaling review task: %v", err)
+		return nil, err
+	}
+
+	return &tarea, nil
+}
+
+// Update actualiza una tarea de revisión existente
+func (r *reviewTaskRepository) Update(tarea *models.TareaRevision) error {
+	item, err := dynamodbattribute.MarshalMap(tarea)
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String("review_tasks"),
+		Item:      item,
+	}
+
+	_, err = r.db.GetClient().PutItem(input)
+	if err != nil {
+		r.log.Errorf("Error updating review task: %v", err)
+		return err
+	}
+
+	r.log.Infof("Review task updated successfully: %s", tarea.TareaID)
+	return nil
+}
+
+// ListByEstado lista tareas de revisión por estado
+func (r *reviewTaskRepository) ListByEstado(estado models.EstadoTareaRevision) ([]*models.TareaRevision, error) {
+	filter := expression.Name("estado").Equal(expression.Value(string(estado)))
+	return r.scan(filter)
+}
+
+// ListByProveedor lista las tareas de un tipo para un proveedor
+func (r *reviewTaskRepository) ListByProveedor(proveedorID, tipo string) ([]*models.TareaRevision, error) {
+	filter := expression.Name("proveedor_id").Equal(expression.Value(proveedorID)).
+		And(expression.Name("tipo").Equal(expression.Value(tipo)))
+	return r.scan(filter)
+}
+
+// scan recorre la tabla de tareas aplicando el filtro indicado
+func (r *reviewTaskRepository) scan(filter expression.ConditionBuilder) ([]*models.TareaRevision, error) {
+	expr, err := expression.NewBuilder().WithFilter(filter).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	input := &dynamodb.ScanInput{
+		TableName:                 aws.String("review_tasks"),
+		FilterExpression:          expr.Filter(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}
+
+	var tareas []*models.TareaRevision
+	err = r.db.GetClient().ScanPages(input, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			var tarea models.TareaRevision
+			if err := dynamodbattribute.UnmarshalMap(item, &tarea); err != nil {
+				r.log.Errorf("Error unmarshaling review task: %v", err)
+				continue
+			}
+			tareas = append(tareas, &tarea)
+		}
+		return true
+	})
+	if err != nil {
+		r.log.Errorf("Error scanning review tasks: %v", err)
+		return nil, err
+	}
+
+	return tareas, nil
+}
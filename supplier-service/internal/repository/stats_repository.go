@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"time"
+
+	"mediplus/supplier-service/internal/database"
+	"mediplus/supplier-service/internal/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/sirupsen/logrus"
+)
+
+// StatsRepository define la interfaz para la proyección de estadísticas de proveedores
+type StatsRepository interface {
+	GetByProveedor(proveedorID string) (*models.EstadisticasProveedor, error)
+	ListAll() ([]*models.EstadisticasProveedor, error)
+	IncrementarOrdenesGeneradas(proveedorID string, fecha time.Time) error
+	RegistrarConfirmacion(proveedorID string, horasConfirmacion *float64, fecha time.Time) error
+	RegistrarRecepcion(proveedorID string, horasEntrega *float64, fecha time.Time) error
+	IncrementarSuspensiones(proveedorID string, fecha time.Time) error
+	SaveSeguimiento(seguimiento *models.SeguimientoOrden) error
+	GetSeguimiento(ordenID string) (*models.SeguimientoOrden, error)
+}
+
+// statsRepository implementa StatsRepository
+type statsRepository struct {
+	db  *database.DynamoDBClient
+	log *logrus.Logger
+}
+
+// NewStatsRepository crea una nueva instancia de StatsRepository
+func NewStatsRepository(db *database.DynamoDBClient, log *logrus.Logger) StatsRepository {
+	return &statsRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// GetByProveedor obtiene la proyección de estadísticas de un proveedor
+func (r *statsRepository) GetByProveedor(proveedorID string) (*models.EstadisticasProveedor, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String("supplier_stats"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"proveedor_id": {
+				S: aws.String(proveedorID),
+			},
+		},
+	}
+
+	result, err := r.db.GetClient().GetItem(input)
+	if err != nil {
+		r.log.Errorf("Error getting supplier stats: %v", err)
+		return nil, err
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var estadisticas models.EstadisticasProveedor
+	err = dynamodbattribute.UnmarshalMap(result.Item, &estadisticas)
+	if err != nil {
+		r.log.Errorf("Error unmarshaling supplier stats: %v", err)
+		return nil, err
+	}
+
+	return &estadisticas, nil
+}
+
+// ListAll lista las proyecciones de estadísticas de todos los proveedores
+func (r *statsRepository) ListAll() ([]*models.EstadisticasProveedor, error) {
+	input := &dynamodb.ScanInput{
+		TableName: aws.String("supplier_stats"),
+	}
+
+	var lista []*models.EstadisticasProveedor
+	err := r.db.GetClient().ScanPages(input, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			var estadisticas models.EstadisticasProveedor
+			if err := dynamodbattribute.UnmarshalMap(item, &estadisticas); err != nil {
+				r.log.Errorf("Error unmarshaling supplier stats: %v", err)
+				continue
+			}
+			lista = append(lista, &estadisticas)
+		}
+		return true
+	})
+	if err != nil {
+		r.log.Errorf("Error scanning supplier stats: %v", err)
+		return nil, err
+	}
+
+	return lista, nil
+}
+
+// IncrementarOrdenesGeneradas suma una orden generada a la proyección del proveedor
+func (r *statsRepository) IncrementarOrdenesGeneradas(proveedorID string, fecha time.Time) error {
+	update := expression.Add(expression.Name("ordenes_generadas"), expression.Value(1)).
+		Set(expression.Name("ultima_actividad"), expression.Value(fecha))
+
+	return r.actualizar(proveedorID, update)
+}
+
+// RegistrarConfirmacion suma una confirmación y, si se conoce, su tiempo de confirmación
+func (r *statsRepository) RegistrarConfirmacion(proveedorID string, horasConfirmacion *float64, fecha time.Time) error {
+	update := expression.Add(expression.Name("ordenes_confirmadas"), expression.Value(1)).
+		Set(expression.Name("ultima_actividad"), expression.Value(fecha))
+
+	if horasConfirmacion != nil {
+		update = update.
+			Add(expression.Name("horas_confirmacion_acumuladas"), expression.Value(*horasConfirmacion)).
+			Add(expression.Name("confirmaciones_medidas"), expression.Value(1))
+	}
+
+	return r.actualizar(proveedorID, update)
+}
+
+// RegistrarRecepcion suma una recepción al total y a su periodo y, si se conoce, su tiempo de entrega
+func (r *statsRepository) RegistrarRecepcion(proveedorID string, horasEntrega *float64, fecha time.Time) error {
+	// Asegurar que el mapa de periodos exista antes de incrementar una de sus claves
+	emptyMap := (&dynamodb.AttributeValue{}).SetM(map[string]*dynamodb.AttributeValue{})
+	init := expression.Set(
+		expression.Name("ordenes_recibidas_por_periodo"),
+		expression.Name("ordenes_recibidas_por_periodo").IfNotExists(expression.Value(emptyMap)),
+	)
+	if err := r.actualizar(proveedorID, init); err != nil {
+		return err
+	}
+
+	periodo := expression.Name("ordenes_recibidas_por_periodo." + fecha.Format(models.FormatoPeriodo))
+	update := expression.Add(expression.Name("ordenes_recibidas"), expression.Value(1)).
+		Set(periodo, expression.Plus(periodo.IfNotExists(expression.Value(0)), expression.Value(1))).
+		Set(expression.Name("ultima_actividad"), expression.Value(fecha))
+
+	if horasEntrega != nil {
+		update = update.
+			Add(expression.Name("horas_entrega_acumuladas"), expression.Value(*horasEntrega)).
+			Add(expression.Name("entregas_medidas"), expression.Value(1))
+	}
+
+	return r.actualizar(proveedorID, update)
+}
+
+// IncrementarSuspensiones suma una suspensión a la proyección del proveedor
+func (r *statsRepository) IncrementarSuspensiones(proveedorID string, fecha time.Time) error {
+	update := expression.Add(expression.Name("suspensiones"), expression.Value(1)).
+		Set(expression.Name("ultima_actividad"), expression.Value(fecha))
+
+	return r.actualizar(proveedorID, update)
+}
+
+// SaveSeguimiento guarda las fechas de seguimiento de una orden
+func (r *statsRepository) SaveSeguimiento(seguimiento *models.SeguimientoOrden) error {
+	item, err := dynamodbattribute.MarshalMap(seguimiento)
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String("supplier_order_tracking"),
+		Item:      item,
+	}
+
+	_, err = r.db.GetClient().PutItem(input)
+	if err != nil {
+		r.log.Errorf("Error saving order tracking: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetSeguimiento obtiene las fechas de seguimiento de una orden
+func (r *statsRepository) GetSeguimiento(ordenID string) (*models.SeguimientoOrden, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String("supplier_order_tracking"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"orden_id": {
+				S: aws.String(ordenID),
+			},
+		},
+	}
+
+	result, err := r.db.GetClient().GetItem(input)
+	if err != nil {
+		r.log.Errorf("Error getting order tracking: %v", err)
+		return nil, err
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var seguimiento models.SeguimientoOrden
+	err = dynamodbattribute.UnmarshalMap(result.Item, &seguimiento)
+	if err != nil {
+		r.log.Errorf("Error unmarshaling order tracking: %v", err)
+		return nil, err
+	}
+
+	return &seguimiento, nil
+}
+
+// actualizar aplica una expresión de actualización atómica sobre la proyección del proveedor
+func (r *statsRepository) actualizar(proveedorID string, update expression.UpdateBuilder) error {
+	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String("supplier_stats"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"proveedor_id": {
+				S: aws.String(proveedorID),
+			},
+		},
+		UpdateExpression:          expr.Update(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}
+
+	_, err = r.db.GetClient().UpdateItem(input)
+	if err != nil {
+		r.log.Errorf("Error updating supplier stats: %v", err)
+		return err
+	}
+
+	return nil
+}
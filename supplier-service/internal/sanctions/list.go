@@ -0,0 +1,330 @@
+package sanctions
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"mediplus/supplier-service/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// List mantiene en memoria la lista de sanciones/inhabilidades cargada desde un archivo local
+type List struct {
+	path            string
+	umbralSimilitud float64
+	entradas        []entradaNormalizada
+	cargadaEn       time.Time
+	modificadoEn    time.Time
+	mu              sync.RWMutex
+	log             *logrus.Logger
+}
+
+// entradaNormalizada guarda la entrada junto con sus valores normalizados para comparar
+type entradaNormalizada struct {
+	entrada        models.EntradaSancion
+	nombre         string
+	identificacion string
+}
+
+// NewList crea una lista de sanciones para el archivo indicado (CSV o JSON).
+// umbralSimilitud es la similitud mínima (0-1) para considerar una coincidencia aproximada.
+func NewList(path string, umbralSimilitud float64, log *logrus.Logger) *List {
+	return &List{
+		path:            path,
+		umbralSimilitud: umbralSimilitud,
+		log:             log,
+	}
+}
+
+// Reload vuelve a cargar la lista desde el archivo y retorna la cantidad de entradas
+func (l *List) Reload() (int, error) {
+	if l.path == "" {
+		return 0, fmt.Errorf("sanctions list path is not configured")
+	}
+
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat sanctions list: %w", err)
+	}
+
+	file, err := os.Open(l.path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open sanctions list: %w", err)
+	}
+	defer file.Close()
+
+	var entradas []models.EntradaSancion
+	switch strings.ToLower(filepath.Ext(l.path)) {
+	case ".json":
+		entradas, err = parseJSON(file)
+	case ".csv":
+		entradas, err = parseCSV(file)
+	default:
+		err = fmt.Errorf("unsupported sanctions list format: %s", filepath.Ext(l.path))
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	normalizadas := make([]entradaNormalizada, 0, len(entradas))
+	for _, entrada := range entradas {
+		normalizadas = append(normalizadas, entradaNormalizada{
+			entrada:        entrada,
+			nombre:         NormalizarNombre(entrada.Nombre),
+			identificacion: NormalizarIdentificacion(entrada.IdentificacionFiscal),
+		})
+	}
+
+	l.mu.Lock()
+	l.entradas = normalizadas
+	l.cargadaEn = time.Now()
+	l.modificadoEn = info.ModTime()
+	l.mu.Unlock()
+
+	l.log.WithFields(logrus.Fields{
+		"path":     l.path,
+		"entradas": len(normalizadas),
+	}).Info("Sanctions list loaded")
+
+	return len(normalizadas), nil
+}
+
+// ReloadIfChanged recarga la lista solo si el archivo cambió desde la última carga
+func (l *List) ReloadIfChanged() error {
+	if l.path == "" {
+		return nil
+	}
+
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat sanctions list: %w", err)
+	}
+
+	l.mu.RLock()
+	sinCambios := !l.cargadaEn.IsZero() && info.ModTime().Equal(l.modificadoEn)
+	l.mu.RUnlock()
+
+	if sinCambios {
+		return nil
+	}
+
+	_, err = l.Reload()
+	return err
+}
+
+// Size retorna la cantidad de entradas cargadas
+func (l *List) Size() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.entradas)
+}
+
+// Match compara los datos de un proveedor contra la lista y retorna las coincidencias encontradas
+func (l *List) Match(nombreLegal, razonSocial, identificacionFiscal string) []models.CoincidenciaSancion {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	coincidencias := []models.CoincidenciaSancion{}
+
+	identificacion := NormalizarIdentificacion(identificacionFiscal)
+	nombres := map[string]string{
+		"nombre_legal": nombreLegal,
+		"razon_social": razonSocial,
+	}
+
+	for _, e := range l.entradas {
+		// La identificación fiscal solo admite coincidencia exacta
+		if identificacion != "" && e.identificacion != "" && identificacion == e.identificacion {
+			coincidencias = append(coincidencias, models.CoincidenciaSancion{
+				Campo:     "identificacion_fiscal",
+				Valor:     identificacionFiscal,
+				Tipo:      models.CoincidenciaIdentificacion,
+				Similitud: 1,
+				Entrada:   e.entrada,
+			})
+			continue
+		}
+
+		if e.nombre == "" {
+			continue
+		}
+
+		for _, campo := range []string{"nombre_legal", "razon_social"} {
+			valor := nombres[campo]
+			nombre := NormalizarNombre(valor)
+			if nombre == "" {
+				continue
+			}
+
+			if nombre == e.nombre {
+				coincidencias = append(coincidencias, models.CoincidenciaSancion{
+					Campo:     campo,
+					Valor:     valor,
+					Tipo:      models.CoincidenciaExacta,
+					Similitud: 1,
+					Entrada:   e.entrada,
+				})
+				break
+			}
+
+			if similitud := Similitud(nombre, e.nombre); similitud >= l.umbralSimilitud {
+				coincidencias = append(coincidencias, models.CoincidenciaSancion{
+					Campo:     campo,
+					Valor:     valor,
+					Tipo:      models.CoincidenciaAproximada,
+					Similitud: similitud,
+					Entrada:   e.entrada,
+				})
+				break
+			}
+		}
+	}
+
+	return coincidencias
+}
+
+// parseJSON lee una lista en formato JSON (arreglo de entradas)
+func parseJSON(r io.Reader) ([]models.EntradaSancion, error) {
+	var entradas []models.EntradaSancion
+	if err := json.NewDecoder(r).Decode(&entradas); err != nil {
+		return nil, fmt.Errorf("failed to decode sanctions list: %w", err)
+	}
+	return entradas, nil
+}
+
+// parseCSV lee una lista en formato CSV con encabezado
+// (columnas: nombre, identificacion_fiscal, fuente, motivo)
+func parseCSV(r io.Reader) ([]models.EntradaSancion, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	registros, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sanctions list: %w", err)
+	}
+
+	if len(registros) == 0 {
+		return []models.EntradaSancion{}, nil
+	}
+
+	columnas := map[string]int{}
+	for i, nombre := range registros[0] {
+		columnas[strings.ToLower(strings.TrimSpace(nombre))] = i
+	}
+	if _, ok := columnas["nombre"]; !ok {
+		return nil, fmt.Errorf("sanctions list CSV must have a 'nombre' column")
+	}
+
+	campo := func(registro []string, columna string) string {
+		i, ok := columnas[columna]
+		if !ok || i >= len(registro) {
+			return ""
+		}
+		return strings.TrimSpace(registro[i])
+	}
+
+	entradas := make([]models.EntradaSancion, 0, len(registros)-1)
+	for _, registro := range registros[1:] {
+		entradas = append(entradas, models.EntradaSancion{
+			Nombre:               campo(registro, "nombre"),
+			IdentificacionFiscal: campo(registro, "identificacion_fiscal"),
+			Fuente:               campo(registro, "fuente"),
+			Motivo:               campo(registro, "motivo"),
+		})
+	}
+
+	return entradas, nil
+}
+
+// formasJuridicas son tokens de tipo societario que no distinguen a una empresa
+var formasJuridicas = map[string]bool{
+	"sa": true, "sas": true, "ltda": true, "limitada": true, "sociedad": true,
+	"anonima": true, "cia": true, "compania": true, "inc": true, "llc": true,
+	"ltd": true, "corp": true, "eu": true, "y": true, "de": true,
+}
+
+// acentos reemplaza vocales acentuadas y la eñe por su forma sin tilde
+var acentos = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n",
+	"à", "a", "è", "e", "ì", "i", "ò", "o", "ù", "u", "ç", "c",
+)
+
+// NormalizarNombre normaliza un nombre para compararlo: minúsculas, sin tildes,
+// sin puntuación y sin formas jurídicas (S.A.S., Ltda., etc.)
+func NormalizarNombre(nombre string) string {
+	nombre = acentos.Replace(strings.ToLower(nombre))
+
+	// Unir siglas con puntos ("s.a.s." -> "sas") antes de separar por puntuación
+	nombre = strings.ReplaceAll(nombre, ".", "")
+
+	tokens := strings.FieldsFunc(nombre, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	resultado := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if formasJuridicas[token] {
+			continue
+		}
+		resultado = append(resultado, token)
+	}
+
+	return strings.Join(resultado, " ")
+}
+
+// NormalizarIdentificacion deja solo letras y dígitos en mayúscula (quita guiones, puntos y espacios)
+func NormalizarIdentificacion(identificacion string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(identificacion) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Similitud calcula la similitud (0-1) entre dos cadenas a partir de la distancia de Levenshtein
+func Similitud(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	maximo := len(ra)
+	if len(rb) > maximo {
+		maximo = len(rb)
+	}
+	if maximo == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(maximo)
+}
+
+// levenshtein calcula la distancia de edición entre dos secuencias de runas
+func levenshtein(a, b []rune) int {
+	anterior := make([]int, len(b)+1)
+	actual := make([]int, len(b)+1)
+	for j := range anterior {
+		anterior[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		actual[0] = i
+		for j := 1; j <= len(b); j++ {
+			costo := 1
+			if a[i-1] == b[j-1] {
+				costo = 0
+			}
+			actual[j] = min(anterior[j]+1, actual[j-1]+1, anterior[j-1]+costo)
+		}
+		anterior, actual = actual, anterior
+	}
+
+	return anterior[len(b)]
+}
@@ -0,0 +1,399 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"mediplus/pkg/storage"
+	"mediplus/supplier-service/internal/models"
+	"mediplus/supplier-service/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrSupplierNotFound se retorna cuando el proveedor no existe
+	ErrSupplierNotFound = errors.New("supplier not found")
+	// ErrDocumentNotFound se retorna cuando el documento o la versión no existen
+	ErrDocumentNotFound = errors.New("document not found")
+	// ErrCertificationNotFound se retorna cuando el proveedor no tiene la certificación indicada
+	ErrCertificationNotFound = errors.New("certification not found")
+	// ErrDocumentRetained se retorna al intentar eliminar un documento dentro de su periodo de retención
+	ErrDocumentRetained = errors.New("document is within its retention period")
+	// ErrChecksumMismatch se retorna cuando el contenido almacenado no coincide con su checksum
+	ErrChecksumMismatch = errors.New("stored document checksum mismatch")
+)
+
+// maxIntentosSubida es cuántas veces se reintenta una subida cuando otra subida agregó una
+// versión al mismo documento
+const maxIntentosSubida = 3
+
+// UploadDocumentRequest contiene los datos de una subida de documento
+type UploadDocumentRequest struct {
+	ProveedorID       string
+	DocumentoID       string // vacío para crear un documento nuevo
+	NumeroCertificado string
+	TipoDocumento     string
+	Nombre            string
+	NombreArchivo     string
+	ContentType       string
+	SubidoPor         string
+	Contenido         []byte
+}
+
+// DocumentService define la interfaz para el servicio de documentos de proveedores
+type DocumentService interface {
+	UploadDocument(req *UploadDocumentRequest) (*models.Documento, error)
+	GetDocument(proveedorID, documentoID string) (*models.Documento, error)
+	ListDocuments(proveedorID, numeroCertificado string) ([]*models.Documento, error)
+	DownloadDocument(proveedorID, documentoID string, version int) (*models.VersionDocumento, []byte, error)
+	DeleteDocument(proveedorID, documentoID, usuarioID string) error
+	PurgeExpiredDocuments() (int, error)
+}
+
+// documentService implementa DocumentService
+type documentService struct {
+	documentRepo repository.DocumentRepository
+	supplierRepo repository.SupplierRepository
+	auditRepo    repository.AuditRepository
+	storage      storage.DocumentStorage
+	retencion    time.Duration
+	graciaPurga  time.Duration
+	log          *logrus.Logger
+}
+
+// NewDocumentService crea una nueva instancia de DocumentService
+func NewDocumentService(
+	documentRepo repository.DocumentRepository,
+	supplierRepo repository.SupplierRepository,
+	auditRepo repository.AuditRepository,
+	documentStorage storage.DocumentStorage,
+	retencion time.Duration,
+	graciaPurga time.Duration,
+	log *logrus.Logger,
+) DocumentService {
+	return &documentService{
+		documentRepo: documentRepo,
+		supplierRepo: supplierRepo,
+		auditRepo:    auditRepo,
+		storage:      documentStorage,
+		retencion:    retencion,
+		graciaPurga:  graciaPurga,
+		log:          log,
+	}
+}
+
+// UploadDocument sube un documento nuevo o una nueva versión de uno existente. Si otra subida
+// agregó una versión al mismo documento se vuelve a leer y se reintenta.
+func (s *documentService) UploadDocument(req *UploadDocumentRequest) (*models.Documento, error) {
+	proveedor, err := s.supplierRepo.GetByID(req.ProveedorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if proveedor == nil {
+		return nil, ErrSupplierNotFound
+	}
+
+	if req.DocumentoID == "" && req.NumeroCertificado != "" && proveedor.BuscarCertificacion(req.NumeroCertificado) == nil {
+		return nil, ErrCertificationNotFound
+	}
+
+	contentType := req.ContentType
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = http.DetectContentType(req.Contenido)
+	}
+
+	checksum := sha256.Sum256(req.Contenido)
+	version := models.VersionDocumento{
+		NombreArchivo: req.NombreArchivo,
+		Checksum:      hex.EncodeToString(checksum[:]),
+		ContentType:   contentType,
+		Tamano:        int64(len(req.Contenido)),
+		SubidoPor:     req.SubidoPor,
+		FechaSubida:   time.Now(),
+	}
+
+	for intento := 1; ; intento++ {
+		documento, actual, err := s.guardarVersion(req, proveedor, version)
+		if err == nil {
+			s.registrarTraza(documento.ProveedorID, "DOCUMENTO_SUBIDO",
+				fmt.Sprintf("Documento %s versión %d subido (sha256 %s)", documento.Nombre, actual.Version, actual.Checksum),
+				"", documento.DocumentoID, req.SubidoPor)
+			return documento, nil
+		}
+		if !errors.Is(err, repository.ErrDocumentoModificado) || intento == maxIntentosSubida {
+			return nil, err
+		}
+		s.log.Warnf("Document %s modified concurrently, retrying upload (attempt %d)", req.DocumentoID, intento)
+	}
+}
+
+// guardarVersion agrega la versión al documento, guarda su contenido y luego los metadatos,
+// condicionados a que nadie haya agregado otra versión desde que se leyó el documento
+func (s *documentService) guardarVersion(req *UploadDocumentRequest, proveedor *models.Proveedor, version models.VersionDocumento) (*models.Documento, *models.VersionDocumento, error) {
+	var documento *models.Documento
+	var err error
+	if req.DocumentoID != "" {
+		documento, err = s.GetDocument(req.ProveedorID, req.DocumentoID)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		nombre := req.Nombre
+		if nombre == "" {
+			nombre = req.NombreArchivo
+		}
+		documento = models.NewDocumento(req.ProveedorID, req.NumeroCertificado, req.TipoDocumento, nombre)
+	}
+
+	versionAnterior := documento.VersionActual
+	documento.AgregarVersion(version)
+
+	// Las versiones nunca se sobrescriben: cada subida tiene su propia clave, aun si otra subida
+	// concurrente calculó el mismo número de versión
+	actual := documento.ObtenerVersion(0)
+	actual.StorageKey = fmt.Sprintf("proveedores/%s/documentos/%s/v%d-%s", documento.ProveedorID, documento.DocumentoID, actual.Version, uuid.New().String()[:8])
+
+	err = s.storage.Put(actual.StorageKey, req.Contenido, actual.ContentType)
+	if err != nil {
+		s.log.Errorf("Error storing document content: %v", err)
+		return nil, nil, err
+	}
+
+	s.actualizarRetencion(documento, proveedor)
+
+	err = s.documentRepo.Save(documento, versionAnterior)
+	if err != nil {
+		// No dejar contenido huérfano si no se pudieron guardar los metadatos
+		if delErr := s.storage.Delete(actual.StorageKey); delErr != nil {
+			s.log.Errorf("Error removing orphan document content: %v", delErr)
+		}
+		return nil, nil, err
+	}
+
+	return documento, actual, nil
+}
+
+// GetDocument obtiene los metadatos de un documento del proveedor
+func (s *documentService) GetDocument(proveedorID, documentoID string) (*models.Documento, error) {
+	documento, err := s.documentRepo.GetByID(documentoID)
+	if err != nil {
+		return nil, err
+	}
+
+	if documento == nil || documento.ProveedorID != proveedorID {
+		return nil, ErrDocumentNotFound
+	}
+
+	return documento, nil
+}
+
+// ListDocuments lista los documentos del proveedor, opcionalmente filtrados por certificación
+func (s *documentService) ListDocuments(proveedorID, numeroCertificado string) ([]*models.Documento, error) {
+	documentos, err := s.documentRepo.ListByProveedor(proveedorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if numeroCertificado == "" {
+		return documentos, nil
+	}
+
+	filtrados := []*models.Documento{}
+	for _, documento := range documentos {
+		if documento.NumeroCertificado == numeroCertificado {
+			filtrados = append(filtrados, documento)
+		}
+	}
+	return filtrados, nil
+}
+
+// DownloadDocument descarga una versión del documento (la actual si version es 0) verificando su checksum
+func (s *documentService) DownloadDocument(proveedorID, documentoID string, version int) (*models.VersionDocumento, []byte, error) {
+	documento, err := s.GetDocument(proveedorID, documentoID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v := documento.ObtenerVersion(version)
+	if v == nil {
+		return nil, nil, ErrDocumentNotFound
+	}
+
+	reader, err := s.storage.Get(v.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		s.log.Errorf("Document content missing from storage: %s", v.StorageKey)
+		return nil, nil, ErrDocumentNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	defer reader.Close()
+
+	contenido, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	checksum := sha256.Sum256(contenido)
+	if hex.EncodeToString(checksum[:]) != v.Checksum {
+		s.log.Errorf("Checksum mismatch for document %s version %d", documentoID, v.Version)
+		return nil, nil, ErrChecksumMismatch
+	}
+
+	return v, contenido, nil
+}
+
+// DeleteDocument elimina un documento y todas sus versiones si ya cumplió su periodo de retención
+func (s *documentService) DeleteDocument(proveedorID, documentoID, usuarioID string) error {
+	documento, err := s.GetDocument(proveedorID, documentoID)
+	if err != nil {
+		return err
+	}
+
+	proveedor, err := s.supplierRepo.GetByID(proveedorID)
+	if err != nil {
+		return err
+	}
+	s.actualizarRetencion(documento, proveedor)
+
+	if documento.Retenido(time.Now()) {
+		return ErrDocumentRetained
+	}
+
+	return s.eliminar(documento, usuarioID)
+}
+
+// PurgeExpiredDocuments depura los documentos cuyo periodo de retención terminó: primero los
+// archiva y solo los elimina cuando llevan archivados el periodo de gracia. Retorna cuántos eliminó.
+func (s *documentService) PurgeExpiredDocuments() (int, error) {
+	documentos, err := s.documentRepo.ListAll()
+	if err != nil {
+		return 0, err
+	}
+
+	ahora := time.Now()
+	archivados, eliminados := 0, 0
+	for _, documento := range documentos {
+		if documento.Retenido(ahora) {
+			continue
+		}
+
+		// La certificación pudo haberse renovado desde la última subida
+		proveedor, err := s.supplierRepo.GetByID(documento.ProveedorID)
+		if err != nil {
+			s.log.Errorf("Error getting supplier for document %s: %v", documento.DocumentoID, err)
+			continue
+		}
+		if s.actualizarRetencion(documento, proveedor); documento.Retenido(ahora) {
+			documento.ArchivadoEn = nil
+			if err := s.documentRepo.Save(documento, documento.VersionActual); err != nil {
+				s.log.Errorf("Error updating document retention: %v", err)
+			}
+			continue
+		}
+
+		campos := logrus.Fields{
+			"documento_id":       documento.DocumentoID,
+			"proveedor_id":       documento.ProveedorID,
+			"nombre":             documento.Nombre,
+			"numero_certificado": documento.NumeroCertificado,
+			"versiones":          len(documento.Versiones),
+			"retener_hasta":      documento.RetenerHasta,
+		}
+
+		if documento.ArchivadoEn == nil {
+			documento.Archivar(ahora)
+			if err := s.documentRepo.Save(documento, documento.VersionActual); err != nil {
+				s.log.Errorf("Error archiving document %s: %v", documento.DocumentoID, err)
+				continue
+			}
+			s.registrarTraza(documento.ProveedorID, "DOCUMENTO_ARCHIVADO",
+				fmt.Sprintf("Documento %s archivado al cumplir su retención (%d versiones)", documento.Nombre, len(documento.Versiones)),
+				"", documento.DocumentoID, "system")
+			s.log.WithFields(campos).Info("Document archived by retention purge")
+			archivados++
+			continue
+		}
+
+		if !documento.PuedeEliminarse(ahora, s.graciaPurga) {
+			continue
+		}
+
+		if err := s.eliminar(documento, "system"); err != nil {
+			s.log.Errorf("Error purging document %s: %v", documento.DocumentoID, err)
+			continue
+		}
+		campos["archivado_en"] = *documento.ArchivadoEn
+		s.log.WithFields(campos).Warn("Document permanently deleted by retention purge")
+		eliminados++
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"documentos": len(documentos),
+		"archivados": archivados,
+		"eliminados": eliminados,
+	}).Info("Document retention purge completed")
+
+	return eliminados, nil
+}
+
+// actualizarRetencion recalcula la retención con el vencimiento vigente de la certificación asociada
+func (s *documentService) actualizarRetencion(documento *models.Documento, proveedor *models.Proveedor) {
+	var vencimiento time.Time
+	if proveedor != nil && documento.NumeroCertificado != "" {
+		if cert := proveedor.BuscarCertificacion(documento.NumeroCertificado); cert != nil {
+			vencimiento = cert.FechaVencimiento
+		}
+	}
+	documento.CalcularRetencion(vencimiento, s.retencion)
+}
+
+// eliminar borra el contenido de todas las versiones y luego los metadatos
+func (s *documentService) eliminar(documento *models.Documento, usuarioID string) error {
+	for _, v := range documento.Versiones {
+		if err := s.storage.Delete(v.StorageKey); err != nil {
+			return err
+		}
+	}
+
+	if err := s.documentRepo.Delete(documento.DocumentoID); err != nil {
+		return err
+	}
+
+	s.registrarTraza(documento.ProveedorID, "DOCUMENTO_ELIMINADO",
+		fmt.Sprintf("Documento %s eliminado (%d versiones)", documento.Nombre, len(documento.Versiones)),
+		documento.DocumentoID, "", usuarioID)
+
+	return nil
+}
+
+// registrarTraza crea una traza de auditoría para una operación sobre documentos
+func (s *documentService) registrarTraza(proveedorID, tipoCambio, descripcion, valorAnterior, valorNuevo, usuarioID string) {
+	if usuarioID == "" {
+		usuarioID = "system"
+	}
+
+	traza := &models.AuditoriaTraza{
+		TrazaID:       uuid.New().String(),
+		ProveedorID:   proveedorID,
+		TipoCambio:    tipoCambio,
+		Descripcion:   descripcion,
+		ValorAnterior: valorAnterior,
+		ValorNuevo:    valorNuevo,
+		UsuarioID:     usuarioID,
+		FechaCambio:   time.Now(),
+		IPAddress:     "127.0.0.1",
+	}
+
+	if err := s.auditRepo.CreateTraza(traza); err != nil {
+		s.log.Errorf("Error creating audit trace: %v", err)
+	}
+}
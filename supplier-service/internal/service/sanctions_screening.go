@@ -0,0 +1,246 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"mediplus/supplier-service/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrSanctionsListNotConfigured se retorna cuando no hay lista de sanciones configurada
+var ErrSanctionsListNotConfigured = errors.New("sanctions list is not configured")
+
+// ErrReviewTaskResolved se retorna al intentar resolver una tarea que ya fue resuelta
+var ErrReviewTaskResolved = errors.New("review task is already resolved")
+
+// ScreenSupplier verifica un proveedor contra la lista de sanciones. Ante una coincidencia
+// suspende al proveedor, deja traza de auditoría y abre una tarea de revisión.
+// Retorna nil si el proveedor no existe.
+func (s *supplierService) ScreenSupplier(proveedorID string) (*models.ResultadoScreening, error) {
+	proveedor, err := s.supplierRepo.GetByID(proveedorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if proveedor == nil {
+		return nil, nil // Proveedor no encontrado
+	}
+
+	resultado := &models.ResultadoScreening{
+		ProveedorID:    proveedorID,
+		FechaScreening: time.Now(),
+		Coincidencias:  []models.CoincidenciaSancion{},
+		Suspendido:     proveedor.EstadoProveedor == models.EstadoSuspendido,
+	}
+
+	if s.sanciones == nil || s.sanciones.Size() == 0 {
+		return resultado, nil
+	}
+
+	tareas, err := s.reviewRepo.ListByProveedor(proveedorID, models.TipoTareaSanciones)
+	if err != nil {
+		s.log.Errorf("Error listing review tasks: %v", err)
+	}
+
+	// Descartar coincidencias ya revisadas como falsos positivos
+	for _, c := range s.sanciones.Match(proveedor.NombreLegal, proveedor.RazonSocial, proveedor.IdentificacionFiscal) {
+		descartada := false
+		for _, tarea := range tareas {
+			if tarea.Descarta(c) {
+				descartada = true
+				break
+			}
+		}
+		if !descartada {
+			resultado.Coincidencias = append(resultado.Coincidencias, c)
+		}
+	}
+
+	var pendiente *models.TareaRevision
+	for _, tarea := range tareas {
+		if tarea.Estado == models.EstadoTareaPendiente {
+			pendiente = tarea
+			break
+		}
+	}
+
+	if len(resultado.Coincidencias) == 0 {
+		return resultado, nil
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"proveedor_id":  proveedorID,
+		"coincidencias": len(resultado.Coincidencias),
+	}).Warn("Supplier matched sanctions list")
+
+	// Si ya está suspendido con una revisión abierta no hay nada nuevo que registrar
+	if pendiente != nil && proveedor.EstadoProveedor == models.EstadoSuspendido {
+		resultado.TareaID = pendiente.TareaID
+		return resultado, nil
+	}
+
+	descripcion := describirCoincidencias(resultado.Coincidencias)
+
+	// Crear traza de auditoría
+	traza := &models.AuditoriaTraza{
+		TrazaID:       uuid.New().String(),
+		ProveedorID:   proveedorID,
+		TipoCambio:    "COINCIDENCIA_SANCIONES",
+		Descripcion:   "Coincidencia con lista de sanciones: " + descripcion,
+		ValorAnterior: string(proveedor.EstadoProveedor),
+		ValorNuevo:    string(models.EstadoSuspendido),
+		UsuarioID:     "system",
+		FechaCambio:   time.Now(),
+		IPAddress:     "127.0.0.1",
+	}
+
+	err = s.auditRepo.CreateTraza(traza)
+	if err != nil {
+		s.log.Errorf("Error creating audit trace: %v", err)
+	}
+
+	// Suspender al proveedor si aún no lo está
+	if proveedor.EstadoProveedor != models.EstadoSuspendido {
+		err = s.SuspendSupplier(proveedorID, "Coincidencia con lista de sanciones: "+descripcion)
+		if err != nil {
+			return nil, err
+		}
+	}
+	resultado.Suspendido = true
+
+	// Abrir una tarea de revisión, salvo que ya exista una pendiente para el proveedor
+	if pendiente != nil {
+		resultado.TareaID = pendiente.TareaID
+		return resultado, nil
+	}
+
+	tarea := models.NewTareaRevision(proveedorID, models.TipoTareaSanciones,
+		"Revisar coincidencia con lista de sanciones: "+descripcion, resultado.Coincidencias)
+
+	err = s.reviewRepo.Create(tarea)
+	if err != nil {
+		s.log.Errorf("Error creating review task: %v", err)
+		return resultado, nil
+	}
+	resultado.TareaID = tarea.TareaID
+
+	return resultado, nil
+}
+
+// ScreenAllSuppliers verifica todos los proveedores contra la lista de sanciones,
+// recargándola antes si el archivo cambió
+func (s *supplierService) ScreenAllSuppliers() error {
+	if s.sanciones == nil {
+		return ErrSanctionsListNotConfigured
+	}
+
+	if err := s.sanciones.ReloadIfChanged(); err != nil {
+		s.log.Errorf("Error reloading sanctions list: %v", err)
+	}
+
+	proveedores, err := s.supplierRepo.ListAll()
+	if err != nil {
+		return err
+	}
+
+	coincidencias := 0
+	for _, proveedor := range proveedores {
+		resultado, err := s.ScreenSupplier(proveedor.ProveedorID)
+		if err != nil {
+			s.log.Errorf("Error screening supplier %s: %v", proveedor.ProveedorID, err)
+			continue
+		}
+		if resultado != nil && len(resultado.Coincidencias) > 0 {
+			coincidencias++
+		}
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"proveedores":   len(proveedores),
+		"coincidencias": coincidencias,
+	}).Info("Sanctions screening completed")
+
+	return nil
+}
+
+// ReloadSanctionsList recarga la lista de sanciones desde su archivo
+func (s *supplierService) ReloadSanctionsList() (int, error) {
+	if s.sanciones == nil {
+		return 0, ErrSanctionsListNotConfigured
+	}
+	return s.sanciones.Reload()
+}
+
+// ListReviewTasks lista las tareas de revisión por estado (pendientes por defecto)
+func (s *supplierService) ListReviewTasks(estado models.EstadoTareaRevision) ([]*models.TareaRevision, error) {
+	if estado == "" {
+		estado = models.EstadoTareaPendiente
+	}
+	return s.reviewRepo.ListByEstado(estado)
+}
+
+// ResolveReviewTask marca una tarea de revisión como resuelta. La reactivación del
+// proveedor, si procede, se hace explícitamente con ActivateSupplier.
+// Retorna nil si la tarea no existe.
+func (s *supplierService) ResolveReviewTask(tareaID, resolucion, usuarioID string, falsoPositivo bool) (*models.TareaRevision, error) {
+	tarea, err := s.reviewRepo.GetByID(tareaID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tarea == nil {
+		return nil, nil // Tarea no encontrada
+	}
+
+	if tarea.Estado == models.EstadoTareaResuelta {
+		return nil, ErrReviewTaskResolved
+	}
+
+	if usuarioID == "" {
+		usuarioID = "system"
+	}
+	tarea.Resolver(resolucion, usuarioID, falsoPositivo)
+
+	err = s.reviewRepo.Update(tarea)
+	if err != nil {
+		return nil, err
+	}
+
+	// Crear traza de auditoría
+	traza := &models.AuditoriaTraza{
+		TrazaID:       uuid.New().String(),
+		ProveedorID:   tarea.ProveedorID,
+		TipoCambio:    "REVISION_RESUELTA",
+		Descripcion:   "Tarea de revisión resuelta: " + resolucion,
+		ValorAnterior: string(models.EstadoTareaPendiente),
+		ValorNuevo:    string(models.EstadoTareaResuelta),
+		UsuarioID:     usuarioID,
+		FechaCambio:   time.Now(),
+		IPAddress:     "127.0.0.1",
+	}
+
+	err = s.auditRepo.CreateTraza(traza)
+	if err != nil {
+		s.log.Errorf("Error creating audit trace: %v", err)
+	}
+
+	return tarea, nil
+}
+
+// describirCoincidencias arma un resumen legible de las coincidencias encontradas
+func describirCoincidencias(coincidencias []models.CoincidenciaSancion) string {
+	partes := make([]string, 0, len(coincidencias))
+	for _, c := range coincidencias {
+		parte := fmt.Sprintf("%s '%s' ~ '%s' (%s, %.2f)", c.Campo, c.Valor, c.Entrada.Nombre, c.Tipo, c.Similitud)
+		if c.Entrada.Fuente != "" {
+			parte += " [" + c.Entrada.Fuente + "]"
+		}
+		partes = append(partes, parte)
+	}
+	return strings.Join(partes, "; ")
+}
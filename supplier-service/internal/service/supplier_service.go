@@ -4,6 +4,8 @@ import (
 	"mediplus/supplier-service/internal/events"
 	"mediplus/supplier-service/internal/models"
 	"mediplus/supplier-service/internal/repository"
+	"mediplus/supplier-service/internal/sanctions"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,17 +24,30 @@ type SupplierService interface {
 	ActivateSupplier(proveedorID string) error
 	GetSuppliersByCertification(tipoCertificacion string) ([]*models.Proveedor, error)
 	GetSuppliersWithColdChain() ([]*models.Proveedor, error)
+	GetSuppliersForTemperatureRange(tempMin, tempMax float64, estado models.EstadoProveedor, tipoCertificacion string) ([]*models.Proveedor, error)
+	CheckColdChainCompatibility(proveedorID string, tempMin, tempMax float64) (*models.CompatibilidadCadenaFrio, error)
 	ListSuppliersByEstado(estado models.EstadoProveedor) ([]*models.Proveedor, error)
 	CheckExpiringCertifications() error
 	ProcessOrderGeneratedEvent(orderEvent *events.OrdenCompraGeneradaEvent) error
 	ProcessOrderConfirmedEvent(orderEvent *events.OrdenCompraConfirmadaEvent) error
 	ProcessOrderReceivedEvent(orderEvent *events.OrdenCompraRecibidaEvent) error
+	GetSupplierStats(proveedorID, desde, hasta string) (*models.EstadisticasProveedor, error)
+	GetFleetStats(desde, hasta string) (*models.EstadisticasProveedor, []*models.EstadisticasProveedor, error)
+	FindSuppliersByLocation(ubicacion models.UbicacionEntrega) ([]*models.ProveedorCobertura, error)
+	ScreenSupplier(proveedorID string) (*models.ResultadoScreening, error)
+	ScreenAllSuppliers() error
+	ReloadSanctionsList() (int, error)
+	ListReviewTasks(estado models.EstadoTareaRevision) ([]*models.TareaRevision, error)
+	ResolveReviewTask(tareaID, resolucion, usuarioID string, falsoPositivo bool) (*models.TareaRevision, error)
 }
 
 // supplierService implementa SupplierService
 type supplierService struct {
 	supplierRepo repository.SupplierRepository
 	auditRepo    repository.AuditRepository
+	statsRepo    repository.StatsRepository
+	reviewRepo   repository.ReviewTaskRepository
+	sanciones    *sanctions.List
 	eventBus     events.EventBus
 	log          *logrus.Logger
 }
@@ -41,12 +56,18 @@ type supplierService struct {
 func NewSupplierService(
 	supplierRepo repository.SupplierRepository,
 	auditRepo repository.AuditRepository,
+	statsRepo repository.StatsRepository,
+	reviewRepo repository.ReviewTaskRepository,
+	sanciones *sanctions.List,
 	eventBus events.EventBus,
 	log *logrus.Logger,
 ) SupplierService {
 	return &supplierService{
 		supplierRepo: supplierRepo,
 		auditRepo:    auditRepo,
+		statsRepo:    statsRepo,
+		reviewRepo:   reviewRepo,
+		sanciones:    sanciones,
 		eventBus:     eventBus,
 		log:          log,
 	}
@@ -81,33 +102,21 @@ func (s *supplierService) CreateSupplier(proveedor *models.Proveedor) error {
 	}
 
 	// Emitir evento
-	event := &events.ProveedorCalificadoEvent{
-		EventID:     uuid.New().String(),
-		EventType:   events.EventTypeProveedorCalificado,
-		ProveedorID: proveedor.ProveedorID,
-		Timestamp:   time.Now(),
-	}
-
-	event.Data.NombreLegal = proveedor.NombreLegal
-	event.Data.RazonSocial = proveedor.RazonSocial
-	if proveedor.EvaluacionRendimiento != nil {
-		event.Data.ScoreGeneral = proveedor.EvaluacionRendimiento.ScoreGeneral
-	}
-	if proveedor.CapacidadLogistica != nil {
-		event.Data.CapacidadCadenaFrio = proveedor.CapacidadLogistica.CapacidadCadenaFrio
-	}
-
-	// Obtener certificaciones
-	for _, cert := range proveedor.Certificaciones {
-		event.Data.Certificaciones = append(event.Data.Certificaciones, cert.TipoCertificacion)
-	}
-
+	event := nuevoEventoProveedor(events.EventTypeProveedorCalificado, proveedor)
 	err = s.eventBus.Publish(events.TopicProveedorEvents, event)
 	if err != nil {
 		s.log.Errorf("Error publishing event: %v", err)
 		// No retornamos error aquí para no afectar la creación del proveedor
 	}
 
+	// Verificar el proveedor contra la lista de sanciones
+	resultado, err := s.ScreenSupplier(proveedor.ProveedorID)
+	if err != nil {
+		s.log.Errorf("Error screening supplier: %v", err)
+	} else if resultado.Suspendido {
+		proveedor.EstadoProveedor = models.EstadoSuspendido
+	}
+
 	return nil
 }
 
@@ -153,6 +162,21 @@ func (s *supplierService) UpdateSupplier(proveedor *models.Proveedor) error {
 		s.log.Errorf("Error creating audit trace: %v", err)
 	}
 
+	// Los consumidores que proyectan el proveedor necesitan su catálogo y su logística actuales
+	event := nuevoEventoProveedor(events.EventTypeProveedorActualizado, proveedor)
+	err = s.eventBus.Publish(events.TopicProveedorEvents, event)
+	if err != nil {
+		s.log.Errorf("Error publishing supplier updated event: %v", err)
+	}
+
+	// Volver a verificar contra la lista de sanciones por si cambiaron nombre o identificación
+	resultado, err := s.ScreenSupplier(proveedor.ProveedorID)
+	if err != nil {
+		s.log.Errorf("Error screening supplier: %v", err)
+	} else if resultado.Suspendido {
+		proveedor.EstadoProveedor = models.EstadoSuspendido
+	}
+
 	return nil
 }
 
@@ -310,6 +334,12 @@ func (s *supplierService) SuspendSupplier(proveedorID string, motivo string) err
 		s.log.Errorf("Error creating audit trace: %v", err)
 	}
 
+	// Actualizar la proyección de estadísticas
+	err = s.statsRepo.IncrementarSuspensiones(proveedorID, time.Now())
+	if err != nil {
+		s.log.Errorf("Error updating supplier stats: %v", err)
+	}
+
 	// Emitir evento de suspensión
 	event := &events.ProveedorSuspendidoEvent{
 		EventID:     uuid.New().String(),
@@ -370,22 +400,7 @@ func (s *supplierService) ActivateSupplier(proveedorID string) error {
 	}
 
 	// Emitir evento de activación
-	event := &events.ProveedorCalificadoEvent{
-		EventID:     uuid.New().String(),
-		EventType:   events.EventTypeProveedorActivado,
-		ProveedorID: proveedorID,
-		Timestamp:   time.Now(),
-	}
-
-	event.Data.NombreLegal = proveedor.NombreLegal
-	event.Data.RazonSocial = proveedor.RazonSocial
-	if proveedor.EvaluacionRendimiento != nil {
-		event.Data.ScoreGeneral = proveedor.EvaluacionRendimiento.ScoreGeneral
-	}
-	if proveedor.CapacidadLogistica != nil {
-		event.Data.CapacidadCadenaFrio = proveedor.CapacidadLogistica.CapacidadCadenaFrio
-	}
-
+	event := nuevoEventoProveedor(events.EventTypeProveedorActivado, proveedor)
 	err = s.eventBus.Publish(events.TopicProveedorEvents, event)
 	if err != nil {
 		s.log.Errorf("Error publishing activation event: %v", err)
@@ -404,6 +419,45 @@ func (s *supplierService) GetSuppliersWithColdChain() ([]*models.Proveedor, erro
 	return s.supplierRepo.GetByCapacidadCadenaFrio()
 }
 
+// GetSuppliersForTemperatureRange obtiene proveedores cuyo rango de temperatura validado cubre el rango indicado.
+// El estado y el tipo de certificación, si no están vacíos, también filtran el resultado.
+func (s *supplierService) GetSuppliersForTemperatureRange(tempMin, tempMax float64, estado models.EstadoProveedor, tipoCertificacion string) ([]*models.Proveedor, error) {
+	proveedores, err := s.supplierRepo.GetByCapacidadCadenaFrio()
+	if err != nil {
+		return nil, err
+	}
+
+	compatibles := []*models.Proveedor{}
+	for _, proveedor := range proveedores {
+		if estado != "" && proveedor.EstadoProveedor != estado {
+			continue
+		}
+		if tipoCertificacion != "" && !proveedor.TieneCertificacion(tipoCertificacion) {
+			continue
+		}
+		if cubre, _ := proveedor.CapacidadLogistica.CubreRangoTemperatura(tempMin, tempMax); cubre {
+			compatibles = append(compatibles, proveedor)
+		}
+	}
+
+	return compatibles, nil
+}
+
+// CheckColdChainCompatibility verifica si un proveedor puede mantener el rango de temperatura de un producto.
+// Retorna nil si el proveedor no existe.
+func (s *supplierService) CheckColdChainCompatibility(proveedorID string, tempMin, tempMax float64) (*models.CompatibilidadCadenaFrio, error) {
+	proveedor, err := s.supplierRepo.GetByID(proveedorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if proveedor == nil {
+		return nil, nil // Proveedor no encontrado
+	}
+
+	return proveedor.EvaluarCadenaFrio(tempMin, tempMax), nil
+}
+
 // CheckExpiringCertifications verifica certificaciones por vencer
 func (s *supplierService) CheckExpiringCertifications() error {
 	// Obtener todos los proveedores
@@ -443,6 +497,63 @@ func (s *supplierService) CheckExpiringCertifications() error {
 	return nil
 }
 
+// FindSuppliersByLocation retorna los proveedores activos que cubren la ubicación de entrega,
+// ordenados por tiempo de entrega estimado
+func (s *supplierService) FindSuppliersByLocation(ubicacion models.UbicacionEntrega) ([]*models.ProveedorCobertura, error) {
+	proveedores, err := s.supplierRepo.ListByEstado(models.EstadoActivo)
+	if err != nil {
+		return nil, err
+	}
+
+	resultados := []*models.ProveedorCobertura{}
+	for _, proveedor := range proveedores {
+		if proveedor.CapacidadLogistica == nil {
+			continue
+		}
+
+		// Si varias zonas cubren la ubicación, se usa la de menor tiempo de entrega
+		var mejor *models.ProveedorCobertura
+		for _, zona := range proveedor.CapacidadLogistica.ZonasQueCubren(ubicacion) {
+			candidato := &models.ProveedorCobertura{
+				ProveedorID:           proveedor.ProveedorID,
+				NombreLegal:           proveedor.NombreLegal,
+				Zona:                  zona,
+				TiempoEntregaEstimado: proveedor.CapacidadLogistica.TiempoEntregaZona(zona),
+				CapacidadCadenaFrio:   proveedor.CapacidadLogistica.CapacidadCadenaFrio,
+			}
+			if proveedor.EvaluacionRendimiento != nil {
+				candidato.ScoreGeneral = proveedor.EvaluacionRendimiento.ScoreGeneral
+			}
+			if ubicacion.Coordenada != nil && zona.Centroide != nil {
+				distancia := models.DistanciaKm(*ubicacion.Coordenada, *zona.Centroide)
+				candidato.DistanciaCentroideKm = &distancia
+			}
+
+			if mejor == nil || candidato.TiempoEntregaEstimado < mejor.TiempoEntregaEstimado {
+				mejor = candidato
+			}
+		}
+
+		if mejor != nil {
+			resultados = append(resultados, mejor)
+		}
+	}
+
+	// Ordenar por tiempo de entrega; a igual tiempo, por cercanía y luego por score
+	sort.SliceStable(resultados, func(i, j int) bool {
+		a, b := resultados[i], resultados[j]
+		if a.TiempoEntregaEstimado != b.TiempoEntregaEstimado {
+			return a.TiempoEntregaEstimado < b.TiempoEntregaEstimado
+		}
+		if a.DistanciaCentroideKm != nil && b.DistanciaCentroideKm != nil && *a.DistanciaCentroideKm != *b.DistanciaCentroideKm {
+			return *a.DistanciaCentroideKm < *b.DistanciaCentroideKm
+		}
+		return a.ScoreGeneral > b.ScoreGeneral
+	})
+
+	return resultados, nil
+}
+
 // ListSuppliersByEstado lista proveedores por estado
 func (s *supplierService) ListSuppliersByEstado(estado models.EstadoProveedor) ([]*models.Proveedor, error) {
 	return s.supplierRepo.ListByEstado(estado)
@@ -452,6 +563,9 @@ func (s *supplierService) ListSuppliersByEstado(estado models.EstadoProveedor) (
 func (s *supplierService) ProcessOrderGeneratedEvent(orderEvent *events.OrdenCompraGeneradaEvent) error {
 	s.log.Infof("Processing order generated event for order: %s", orderEvent.OrdenID)
 
+	// Registrar la orden en la proyección de estadísticas
+	s.registrarOrdenGenerada(orderEvent)
+
 	// Obtener proveedores activos que puedan cumplir con la orden
 	proveedores, err := s.supplierRepo.ListByEstado(models.EstadoActivo)
 	if err != nil {
@@ -502,6 +616,9 @@ func (s *supplierService) ProcessOrderConfirmedEvent(orderEvent *events.OrdenCom
 		s.log.Errorf("Error creating audit trace: %v", err)
 	}
 
+	// Actualizar la proyección de estadísticas
+	s.registrarOrdenConfirmada(orderEvent)
+
 	s.log.WithFields(logrus.Fields{
 		"orden_id":     orderEvent.OrdenID,
 		"proveedor_id": orderEvent.Data.ProveedorID,
@@ -545,6 +662,9 @@ func (s *supplierService) ProcessOrderReceivedEvent(orderEvent *events.OrdenComp
 		s.log.Errorf("Error creating audit trace: %v", err)
 	}
 
+	// Actualizar la proyección de estadísticas
+	s.registrarOrdenRecibida(orderEvent)
+
 	s.log.WithFields(logrus.Fields{
 		"orden_id":     orderEvent.OrdenID,
 		"proveedor_id": orderEvent.Data.ProveedorID,
@@ -554,6 +674,128 @@ func (s *supplierService) ProcessOrderReceivedEvent(orderEvent *events.OrdenComp
 	return nil
 }
 
+// GetSupplierStats obtiene las estadísticas de actividad de un proveedor. El rango de periodos
+// solo limita el desglose de órdenes recibidas por mes; los demás indicadores son históricos.
+func (s *supplierService) GetSupplierStats(proveedorID, desde, hasta string) (*models.EstadisticasProveedor, error) {
+	estadisticas, err := s.statsRepo.GetByProveedor(proveedorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if estadisticas == nil {
+		// Proveedor sin actividad registrada
+		estadisticas = models.NewEstadisticasProveedor(proveedorID)
+	}
+
+	estadisticas.FiltrarPeriodos(desde, hasta)
+	estadisticas.CalcularIndicadores()
+
+	return estadisticas, nil
+}
+
+// GetFleetStats obtiene las estadísticas agregadas de todos los proveedores junto con el detalle por proveedor
+func (s *supplierService) GetFleetStats(desde, hasta string) (*models.EstadisticasProveedor, []*models.EstadisticasProveedor, error) {
+	lista, err := s.statsRepo.ListAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	total := models.NewEstadisticasProveedor("")
+	for _, estadisticas := range lista {
+		estadisticas.FiltrarPeriodos(desde, hasta)
+		estadisticas.CalcularIndicadores()
+		total.Acumular(estadisticas)
+	}
+	total.CalcularIndicadores()
+
+	return total, lista, nil
+}
+
+// registrarOrdenGenerada guarda la fecha de generación de la orden y la cuenta para su proveedor
+func (s *supplierService) registrarOrdenGenerada(orderEvent *events.OrdenCompraGeneradaEvent) {
+	seguimiento := &models.SeguimientoOrden{
+		OrdenID:         orderEvent.OrdenID,
+		ProveedorID:     orderEvent.Data.ProveedorID,
+		FechaGeneracion: orderEvent.Timestamp,
+	}
+
+	if err := s.statsRepo.SaveSeguimiento(seguimiento); err != nil {
+		s.log.Errorf("Error saving order tracking: %v", err)
+	}
+
+	// Las órdenes automáticas pueden generarse sin proveedor asignado
+	if orderEvent.Data.ProveedorID == "" {
+		return
+	}
+
+	if err := s.statsRepo.IncrementarOrdenesGeneradas(orderEvent.Data.ProveedorID, orderEvent.Timestamp); err != nil {
+		s.log.Errorf("Error updating supplier stats: %v", err)
+	}
+}
+
+// registrarOrdenConfirmada cuenta la confirmación y mide el tiempo desde la generación
+func (s *supplierService) registrarOrdenConfirmada(orderEvent *events.OrdenCompraConfirmadaEvent) {
+	fechaConfirmacion := orderEvent.Data.FechaConfirmacion
+	if fechaConfirmacion.IsZero() {
+		fechaConfirmacion = orderEvent.Timestamp
+	}
+
+	seguimiento, err := s.statsRepo.GetSeguimiento(orderEvent.OrdenID)
+	if err != nil {
+		s.log.Errorf("Error getting order tracking: %v", err)
+	}
+
+	var horasConfirmacion *float64
+	if seguimiento != nil && !seguimiento.FechaGeneracion.IsZero() {
+		horas := fechaConfirmacion.Sub(seguimiento.FechaGeneracion).Hours()
+		horasConfirmacion = &horas
+	}
+	if seguimiento == nil {
+		seguimiento = &models.SeguimientoOrden{OrdenID: orderEvent.OrdenID}
+	}
+
+	seguimiento.ProveedorID = orderEvent.Data.ProveedorID
+	seguimiento.FechaConfirmacion = fechaConfirmacion
+	if err := s.statsRepo.SaveSeguimiento(seguimiento); err != nil {
+		s.log.Errorf("Error saving order tracking: %v", err)
+	}
+
+	if orderEvent.Data.ProveedorID == "" {
+		return
+	}
+
+	if err := s.statsRepo.RegistrarConfirmacion(orderEvent.Data.ProveedorID, horasConfirmacion, fechaConfirmacion); err != nil {
+		s.log.Errorf("Error updating supplier stats: %v", err)
+	}
+}
+
+// registrarOrdenRecibida cuenta la recepción en su periodo y mide el tiempo de entrega desde la confirmación
+func (s *supplierService) registrarOrdenRecibida(orderEvent *events.OrdenCompraRecibidaEvent) {
+	if orderEvent.Data.ProveedorID == "" {
+		return
+	}
+
+	fechaRecepcion := orderEvent.Data.FechaRecepcion
+	if fechaRecepcion.IsZero() {
+		fechaRecepcion = orderEvent.Timestamp
+	}
+
+	seguimiento, err := s.statsRepo.GetSeguimiento(orderEvent.OrdenID)
+	if err != nil {
+		s.log.Errorf("Error getting order tracking: %v", err)
+	}
+
+	var horasEntrega *float64
+	if seguimiento != nil && !seguimiento.FechaConfirmacion.IsZero() {
+		horas := fechaRecepcion.Sub(seguimiento.FechaConfirmacion).Hours()
+		horasEntrega = &horas
+	}
+
+	if err := s.statsRepo.RegistrarRecepcion(orderEvent.Data.ProveedorID, horasEntrega, fechaRecepcion); err != nil {
+		s.log.Errorf("Error updating supplier stats: %v", err)
+	}
+}
+
 // generateSupplierRequest genera una solicitud de proveedor basada en la orden
 func (s *supplierService) generateSupplierRequest(orderEvent *events.OrdenCompraGeneradaEvent, proveedores []*models.Proveedor) error {
 	s.log.Infof("Generating supplier request for order: %s", orderEvent.OrdenID)
@@ -631,3 +873,40 @@ func (s *supplierService) buildProductRequirements(orderEvent *events.OrdenCompr
 
 	return []events.ProductoRequerido{productoRequerido}
 }
+
+// nuevoEventoProveedor arma el evento con el estado del proveedor que proyectan otros servicios:
+// datos generales, certificaciones, rango de cadena de frío y catálogo con precios
+func nuevoEventoProveedor(tipo string, proveedor *models.Proveedor) *events.ProveedorCalificadoEvent {
+	event := &events.ProveedorCalificadoEvent{
+		EventID:     uuid.New().String(),
+		EventType:   tipo,
+		ProveedorID: proveedor.ProveedorID,
+		Timestamp:   time.Now(),
+	}
+
+	event.Data.NombreLegal = proveedor.NombreLegal
+	event.Data.RazonSocial = proveedor.RazonSocial
+	if proveedor.EvaluacionRendimiento != nil {
+		event.Data.ScoreGeneral = proveedor.EvaluacionRendimiento.ScoreGeneral
+	}
+	if proveedor.CapacidadLogistica != nil {
+		event.Data.CapacidadCadenaFrio = proveedor.CapacidadLogistica.CapacidadCadenaFrio
+		event.Data.TemperaturaMinima = proveedor.CapacidadLogistica.TemperaturaMinima
+		event.Data.TemperaturaMaxima = proveedor.CapacidadLogistica.TemperaturaMaxima
+	}
+	for _, cert := range proveedor.Certificaciones {
+		event.Data.Certificaciones = append(event.Data.Certificaciones, cert.TipoCertificacion)
+	}
+	for _, oferta := range proveedor.ProductosOfrecidos {
+		event.Data.ProductosOfrecidos = append(event.Data.ProductosOfrecidos, events.OfertaProveedor{
+			ProductoID:           oferta.ProductoID,
+			PrecioBase:           oferta.PrecioBase,
+			Moneda:               oferta.Moneda,
+			EstadoDisponibilidad: string(oferta.EstadoDisponibilidad),
+			PrecioContratado:     oferta.PrecioContratado,
+			ContratoVigenteHasta: oferta.ContratoVigenteHasta,
+		})
+	}
+
+	return event
+}
@@ -8,11 +8,14 @@ import (
 	"syscall"
 	"time"
 
+	"mediplus/pkg/idempotency"
+	"mediplus/pkg/storage"
 	"mediplus/supplier-service/internal/config"
 	"mediplus/supplier-service/internal/database"
 	"mediplus/supplier-service/internal/events"
 	"mediplus/supplier-service/internal/handlers"
 	"mediplus/supplier-service/internal/repository"
+	"mediplus/supplier-service/internal/sanctions"
 	"mediplus/supplier-service/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -43,13 +46,42 @@ func main() {
 	// Inicializar repositorios
 	supplierRepo := repository.NewSupplierRepository(db, logger)
 	auditRepo := repository.NewAuditRepository(db, logger)
+	statsRepo := repository.NewStatsRepository(db, logger)
+	reviewRepo := repository.NewReviewTaskRepository(db, logger)
+	documentRepo := repository.NewDocumentRepository(db, logger)
+	processedEventRepo := idempotency.NewProcessedEventRepository(db.GetClient(), logger)
+
+	// Inicializar almacenamiento de documentos
+	var documentStorage storage.DocumentStorage
+	switch cfg.DocumentStorage {
+	case "s3":
+		documentStorage, err = storage.NewS3Storage(cfg.AWSRegion, cfg.DocumentS3Endpoint, cfg.DocumentS3Bucket)
+	default:
+		documentStorage, err = storage.NewLocalStorage(cfg.DocumentStoragePath)
+	}
+	if err != nil {
+		logger.Fatalf("Error initializing document storage: %v", err)
+	}
+
+	// Cargar la lista de sanciones (si no se puede, el screening queda inactivo hasta recargarla)
+	var sanctionsList *sanctions.List
+	if cfg.SanctionsListPath != "" {
+		sanctionsList = sanctions.NewList(cfg.SanctionsListPath, cfg.SanctionsFuzzyThreshold, logger)
+		if _, err := sanctionsList.Reload(); err != nil {
+			logger.Errorf("Error loading sanctions list: %v", err)
+		}
+	} else {
+		logger.Warn("SANCTIONS_LIST_PATH not set, sanctions screening disabled")
+	}
 
 	// Inicializar servicios
-	supplierService := service.NewSupplierService(supplierRepo, auditRepo, eventBus, logger)
+	supplierService := service.NewSupplierService(supplierRepo, auditRepo, statsRepo, reviewRepo, sanctionsList, eventBus, logger)
+	documentService := service.NewDocumentService(documentRepo, supplierRepo, auditRepo, documentStorage, cfg.DocumentRetention, cfg.DocumentPurgeGrace, logger)
 
 	// Inicializar handlers
 	supplierHandler := handlers.NewSupplierHandler(supplierService, logger)
 	eventHandler := handlers.NewEventHandler(supplierService, logger)
+	documentHandler := handlers.NewDocumentHandler(documentService, int64(cfg.DocumentMaxUploadSizeMB)<<20, logger)
 
 	// Configurar rutas
 	router := gin.Default()
@@ -66,9 +98,28 @@ func main() {
 			suppliers.PUT("/:id", supplierHandler.UpdateSupplier)
 			suppliers.DELETE("/:id", supplierHandler.DeleteSupplier)
 			suppliers.GET("", supplierHandler.ListSuppliers)
+			suppliers.GET("/stats", supplierHandler.GetFleetStats)
+			suppliers.GET("/coverage", supplierHandler.FindSuppliersByLocation)
+			suppliers.GET("/:id/stats", supplierHandler.GetSupplierStats)
 			suppliers.POST("/:id/evaluate", supplierHandler.EvaluateSupplier)
 			suppliers.POST("/:id/suspend", supplierHandler.SuspendSupplier)
 			suppliers.POST("/:id/activate", supplierHandler.ActivateSupplier)
+			suppliers.POST("/:id/screen", supplierHandler.ScreenSupplier)
+			suppliers.GET("/:id/cold-chain-compatibility", supplierHandler.CheckColdChainCompatibility)
+			suppliers.POST("/:id/documents", documentHandler.UploadDocument)
+			suppliers.GET("/:id/documents", documentHandler.ListDocuments)
+			suppliers.GET("/:id/documents/:documentoId", documentHandler.GetDocument)
+			suppliers.POST("/:id/documents/:documentoId/versions", documentHandler.UploadDocumentVersion)
+			suppliers.GET("/:id/documents/:documentoId/download", documentHandler.DownloadDocument)
+			suppliers.DELETE("/:id/documents/:documentoId", documentHandler.DeleteDocument)
+		}
+
+		v1.POST("/sanctions/reload", supplierHandler.ReloadSanctionsList)
+
+		reviewTasks := v1.Group("/review-tasks")
+		{
+			reviewTasks.GET("", supplierHandler.ListReviewTasks)
+			reviewTasks.POST("/:id/resolve", supplierHandler.ResolveReviewTask)
 		}
 	}
 
@@ -83,11 +134,14 @@ func main() {
 		Handler: router,
 	}
 
+	// Las entregas repetidas de un evento se descartan por event_id en cada cola
+	idempotentConsumer := idempotency.NewIdempotentConsumer(processedEventRepo, cfg.ProcessedEventLease, cfg.ProcessedEventRetention, logger)
+
 	// Suscribirse a eventos de órdenes
 	logger.Info("Subscribing to order events...")
 
 	// Suscribirse a eventos de orden generada
-	err = eventBus.Subscribe(events.TopicOrderEvents, "supplier-order-generated", eventHandler.HandleOrdenCompraGeneradaEvent)
+	err = eventBus.Subscribe(events.TopicOrderEvents, "supplier-order-generated", idempotentConsumer.Wrap("supplier-order-generated", eventHandler.HandleOrdenCompraGeneradaEvent))
 	if err != nil {
 		logger.Errorf("Error subscribing to order generated events: %v", err)
 	} else {
@@ -95,7 +149,7 @@ func main() {
 	}
 
 	// Suscribirse a eventos de orden confirmada
-	err = eventBus.Subscribe(events.TopicOrderEvents, "supplier-order-confirmed", eventHandler.HandleOrdenCompraConfirmadaEvent)
+	err = eventBus.Subscribe(events.TopicOrderEvents, "supplier-order-confirmed", idempotentConsumer.Wrap("supplier-order-confirmed", eventHandler.HandleOrdenCompraConfirmadaEvent))
 	if err != nil {
 		logger.Errorf("Error subscribing to order confirmed events: %v", err)
 	} else {
@@ -103,13 +157,41 @@ func main() {
 	}
 
 	// Suscribirse a eventos de orden recibida
-	err = eventBus.Subscribe(events.TopicOrderEvents, "supplier-order-received", eventHandler.HandleOrdenCompraRecibidaEvent)
+	err = eventBus.Subscribe(events.TopicOrderEvents, "supplier-order-received", idempotentConsumer.Wrap("supplier-order-received", eventHandler.HandleOrdenCompraRecibidaEvent))
 	if err != nil {
 		logger.Errorf("Error subscribing to order received events: %v", err)
 	} else {
 		logger.Info("Successfully subscribed to order received events")
 	}
 
+	// Screening periódico contra la lista de sanciones
+	if sanctionsList != nil && cfg.SanctionsScreeningInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(cfg.SanctionsScreeningInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				if err := supplierService.ScreenAllSuppliers(); err != nil {
+					logger.Errorf("Error running sanctions screening: %v", err)
+				}
+			}
+		}()
+	}
+
+	// Depuración diaria de documentos cuyo periodo de retención terminó, solo si se habilitó
+	if cfg.DocumentPurgeEnabled {
+		go func() {
+			ticker := time.NewTicker(24 * time.Hour)
+			defer ticker.Stop()
+			for range ticker.C {
+				if _, err := documentService.PurgeExpiredDocuments(); err != nil {
+					logger.Errorf("Error purging expired documents: %v", err)
+				}
+			}
+		}()
+	} else {
+		logger.Info("Document retention purge disabled")
+	}
+
 	// Iniciar servidor en goroutine
 	go func() {
 		logger.Infof("Starting supplier service on port %s", cfg.Port)